@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"goblets/config"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// aggregatedGame is the latest known state of a game on one broker.
+type aggregatedGame struct {
+	Broker  string
+	GameID  string
+	State   GameState
+	Updated time.Time
+}
+
+// aggregator merges game states and feed lines from several brokers.
+type aggregator struct {
+	mu    sync.Mutex
+	games map[string]*aggregatedGame
+	feed  []string
+}
+
+func (a *aggregator) onState(broker string) mqtt.MessageHandler {
+	return func(client mqtt.Client, msg mqtt.Message) {
+		var state GameState
+		if err := decodeState(unwrapPayload(msg.Payload()), &state); err != nil {
+			return
+		}
+		id := topicGameID(msg.Topic())
+		a.mu.Lock()
+		a.games[broker+"/"+id] = &aggregatedGame{Broker: broker, GameID: id, State: state, Updated: time.Now()}
+		a.mu.Unlock()
+		a.render()
+	}
+}
+
+func (a *aggregator) onFeed(broker string) mqtt.MessageHandler {
+	return func(client mqtt.Client, msg mqtt.Message) {
+		a.mu.Lock()
+		a.feed = append(a.feed, fmt.Sprintf("%s  [%s] %s", time.Now().Format("15:04"), broker, msg.Payload()))
+		if len(a.feed) > feedLines/2 {
+			a.feed = a.feed[len(a.feed)-feedLines/2:]
+		}
+		a.mu.Unlock()
+		a.render()
+	}
+}
+
+func (a *aggregator) render() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	games := make([]*aggregatedGame, 0, len(a.games))
+	for _, g := range a.games {
+		games = append(games, g)
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].Updated.After(games[j].Updated) })
+
+	clearScreen()
+	fmt.Println("🛰 Gobblet Gobblers — All Rooms")
+	fmt.Println()
+	fmt.Printf("%-12s %-8s %-6s %-10s %s\n", "BROKER", "GAME", "MOVES", "STATUS", "TITLE")
+	for _, g := range games {
+		status := fmt.Sprintf("P%d to move", g.State.PlayerTurn)
+		if g.State.Result != nil {
+			status = string(g.State.Result.Outcome)
+		}
+		fmt.Printf("%-12s %-8s %-6d %-10s %s\n", g.Broker, g.GameID, g.State.Moves, status, g.State.Meta.label())
+	}
+	fmt.Println()
+	for _, line := range a.feed {
+		fmt.Println(line)
+	}
+}
+
+// runAggregate subscribes to every configured broker and shows their games
+// and feeds on one dashboard, tagged with the source broker.
+func runAggregate(args []string) {
+	brokers := config.Conf.Aggregator.Brokers
+	if len(brokers) == 0 {
+		fmt.Println("❌ No brokers configured under aggregator.brokers in config.yaml")
+		os.Exit(1)
+	}
+
+	a := &aggregator{games: make(map[string]*aggregatedGame)}
+	for _, b := range brokers {
+		client := newBrokerClient(b.URL, fmt.Sprintf("GobbletAggregator-%s-%d", b.Name, time.Now().UnixNano()), "", nil)
+		if token := client.Connect(); token.Wait() && token.Error() != nil {
+			fmt.Printf("⚠ Could not connect to %s: %v\n", b.Name, token.Error())
+			continue
+		}
+		client.Subscribe(gameTopic("+", channelState), 1, a.onState(b.Name)).Wait()
+		client.Subscribe(feedTopic, 0, a.onFeed(b.Name)).Wait()
+	}
+	a.render()
+	select {}
+}
@@ -0,0 +1,262 @@
+// Package ai picks moves for computer players: a minimax search with
+// alpha-beta pruning over the engine's legal moves, or Monte Carlo tree
+// search over random playouts. Like the engine it does no I/O.
+package ai
+
+import (
+	"goblets/engine"
+	"math/rand/v2"
+	"time"
+)
+
+// win scores a won position. Wins found sooner score higher, so the search
+// takes the shortest win and puts off a loss.
+const win = 1_000_000_000_000
+
+// Engines a computer player can search with.
+const (
+	Minimax = "minimax"
+	MCTS    = "mcts"
+)
+
+// Limits bound a computer player's strength.
+type Limits struct {
+	Engine   string        // Minimax or MCTS; empty for Minimax
+	Depth    int           // plies to search with minimax
+	Playouts int           // random playouts for MCTS
+	Time     time.Duration // per move, 0 for no limit
+	Blunder  float64       // chance of playing a random move instead, 0 to 1
+	Book     *Book         // opening book played from while it knows the position
+}
+
+// Levels are the named difficulty levels.
+var Levels = map[string]Limits{
+	"easy":   {Depth: 1, Playouts: 100, Blunder: 0.3},
+	"medium": {Depth: 3, Playouts: 2000, Time: time.Second, Blunder: 0.1},
+	"hard":   {Depth: 6, Playouts: 50000, Time: 3 * time.Second},
+}
+
+// Choose picks a move for the player to move in s within l. Minimax deepens
+// the search one ply at a time and keeps the deepest search that finished
+// in time. ok is false when there is no legal move.
+func Choose(s engine.State, l Limits) (m engine.Move, ok bool) {
+	moves := engine.LegalMoves(s, s.Turn)
+	if len(moves) == 0 {
+		return engine.Move{}, false
+	}
+	if m, ok := l.Book.Pick(s); ok {
+		return m, true
+	}
+	if rand.Float64() < l.Blunder {
+		return moves[rand.IntN(len(moves))], true
+	}
+	if l.Engine == MCTS {
+		return chooseMCTS(s, l), true
+	}
+	sr := &searcher{table: map[uint64]entry{}}
+	if l.Time > 0 {
+		sr.deadline = time.Now().Add(l.Time)
+	}
+	best := moves[0]
+	for depth := 1; depth <= max(l.Depth, 1); depth++ {
+		m, ok := sr.root(s, moves, depth)
+		if !ok {
+			break // out of time, keep the last finished search
+		}
+		best = m
+	}
+	return best, true
+}
+
+// BestMove searches depth plies ahead and returns the best move for the
+// player to move in s. ok is false when there is no legal move.
+func BestMove(s engine.State, depth int) (m engine.Move, ok bool) {
+	return Choose(s, Limits{Depth: depth})
+}
+
+// Analysis compares a played move with the best move found for the same
+// position. Scores are for the player who moved.
+type Analysis struct {
+	Played   int
+	Best     int
+	BestMove engine.Move
+}
+
+// Analyze scores m and the best move of s within l, searching each to the
+// same depth so the scores compare.
+func Analyze(s engine.State, m engine.Move, l Limits) Analysis {
+	moves := engine.LegalMoves(s, s.Turn)
+	a := Analysis{BestMove: m}
+	if len(moves) == 0 {
+		return a
+	}
+	depth := max(l.Depth, 1)
+	for {
+		sr := &searcher{table: map[uint64]entry{}}
+		if l.Time > 0 {
+			sr.deadline = time.Now().Add(l.Time)
+		}
+		best, _ := sr.root(s, moves, depth)
+		played := sr.score(s, m, depth, -2*win, 2*win)
+		bestScore := sr.score(s, best, depth, -2*win, 2*win)
+		if !sr.aborted || depth == 1 {
+			a.Played, a.Best, a.BestMove = played, max(bestScore, played), best
+			if played >= bestScore {
+				a.BestMove = m
+			}
+			return a
+		}
+		depth-- // out of time: settle for a shallower search
+	}
+}
+
+// Decisive reports whether score is a forced result: 1 for a win, -1 for a
+// loss, 0 otherwise.
+func Decisive(score int) int {
+	switch {
+	case score >= win:
+		return 1
+	case score <= -win:
+		return -1
+	}
+	return 0
+}
+
+// root searches every move of s to depth. ok is false if time ran out.
+func (sr *searcher) root(s engine.State, moves []engine.Move, depth int) (m engine.Move, ok bool) {
+	best, alpha := moves[0], -2*win
+	for _, m := range moves {
+		score := sr.score(s, m, depth, alpha, 2*win)
+		if sr.aborted {
+			return best, false
+		}
+		if score > alpha {
+			best, alpha = m, score
+		}
+	}
+	return best, true
+}
+
+// bound says how a stored score relates to the true value of a position.
+type bound int
+
+const (
+	exact bound = iota
+	lower       // the true value is at least the score
+	upper       // the true value is at most the score
+)
+
+type entry struct {
+	depth int
+	score int
+	bound bound
+}
+
+type searcher struct {
+	table    map[uint64]entry // transposition table keyed by Zobrist hash
+	deadline time.Time        // zero for no time limit
+	nodes    int
+	aborted  bool // the deadline passed; scores since are meaningless
+}
+
+// timedOut stops the search once it is past its deadline. The clock is
+// only read every thousand nodes.
+func (sr *searcher) timedOut() bool {
+	sr.nodes++
+	if !sr.aborted && !sr.deadline.IsZero() && sr.nodes%1024 == 0 && time.Now().After(sr.deadline) {
+		sr.aborted = true
+	}
+	return sr.aborted
+}
+
+// score returns the value of playing m in s for the player to move.
+func (sr *searcher) score(s engine.State, m engine.Move, depth, alpha, beta int) int {
+	next, err := s.Apply(m)
+	if err != nil {
+		return -2 * win
+	}
+	switch next.Winner {
+	case 0:
+		return -sr.negamax(next, depth-1, -beta, -alpha)
+	case s.Turn:
+		return win + depth
+	}
+	return -win - depth
+}
+
+// negamax returns the value of s for the player to move.
+func (sr *searcher) negamax(s engine.State, depth, alpha, beta int) int {
+	if sr.timedOut() {
+		return 0
+	}
+	if depth <= 0 {
+		return Evaluate(s.Board, s.Turn)
+	}
+	key := s.Hash()
+	if e, ok := sr.table[key]; ok && e.depth >= depth {
+		switch {
+		case e.bound == exact,
+			e.bound == lower && e.score >= beta,
+			e.bound == upper && e.score <= alpha:
+			return e.score
+		}
+	}
+
+	moves := engine.LegalMoves(s, s.Turn)
+	if len(moves) == 0 {
+		return 0 // stalemate is a draw
+	}
+	best, start := -2*win, alpha
+	for _, m := range moves {
+		best = max(best, sr.score(s, m, depth, alpha, beta))
+		alpha = max(alpha, best)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	if sr.aborted {
+		return best
+	}
+	e := entry{depth: depth, score: best}
+	switch {
+	case best <= start:
+		e.bound = upper
+	case best >= beta:
+		e.bound = lower
+	}
+	sr.table[key] = e
+	return best
+}
+
+// Evaluate scores b for player without searching: every line that only
+// one side has pieces showing in counts for that side, more the closer it
+// is to complete.
+func Evaluate(b engine.Board, player int) int {
+	score := 0
+	for _, line := range engine.Lines(b.Size()) {
+		var owned [3]int
+		for _, cell := range line {
+			if top, ok := b[cell[0]][cell[1]].Top(); ok {
+				owned[top.Owner]++
+			}
+		}
+		switch {
+		case owned[player] > 0 && owned[3-player] == 0:
+			score += lineValue(owned[player])
+		case owned[3-player] > 0 && owned[player] == 0:
+			score -= lineValue(owned[3-player])
+		}
+	}
+	return score
+}
+
+// lineValue weighs a line with n pieces of one side, growing tenfold per
+// piece.
+func lineValue(n int) int {
+	v := 1
+	for range n {
+		v *= 10
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"goblets/engine"
+	"goblets/notation"
+	"math/rand/v2"
+	"sort"
+)
+
+// Book is an opening book: moves recommended in the positions of the first
+// few moves of a game, weighted by how well they did.
+type Book struct {
+	Variant   engine.Variant        `json:",omitempty"`
+	Positions map[string][]BookMove // by BookKey
+}
+
+// BookMove is a recommended move in algebraic notation, e.g. "P b2 L".
+type BookMove struct {
+	Move   string
+	Weight float64
+}
+
+// ParseBook reads a book written with json.Marshal.
+func ParseBook(data []byte) (*Book, error) {
+	var b Book
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// BookKey identifies a position in a book.
+func BookKey(s engine.State) string {
+	return fmt.Sprintf("%016x", s.Hash())
+}
+
+// Add counts weight for playing m in s.
+func (b *Book) Add(s engine.State, m engine.Move, weight float64) {
+	if b.Positions == nil {
+		b.Positions = map[string][]BookMove{}
+	}
+	key, text := BookKey(s), notation.Format(m)
+	for i, bm := range b.Positions[key] {
+		if bm.Move == text {
+			b.Positions[key][i].Weight += weight
+			return
+		}
+	}
+	b.Positions[key] = append(b.Positions[key], BookMove{Move: text, Weight: weight})
+}
+
+// Sort orders every position's moves best first and drops those that never
+// did well.
+func (b *Book) Sort() {
+	for key, moves := range b.Positions {
+		sort.SliceStable(moves, func(i, j int) bool { return moves[i].Weight > moves[j].Weight })
+		for len(moves) > 0 && moves[len(moves)-1].Weight <= 0 {
+			moves = moves[:len(moves)-1]
+		}
+		if len(moves) == 0 {
+			delete(b.Positions, key)
+		} else {
+			b.Positions[key] = moves
+		}
+	}
+}
+
+// lookup returns the book's legal moves for s with their weights.
+func (b *Book) lookup(s engine.State) ([]engine.Move, []float64) {
+	if b == nil || s.Variant.String() != b.Variant.String() {
+		return nil, nil
+	}
+	var moves []engine.Move
+	var weights []float64
+	for _, bm := range b.Positions[BookKey(s)] {
+		m, err := notation.Parse(bm.Move)
+		if err != nil || bm.Weight <= 0 {
+			continue
+		}
+		if _, err := s.Apply(m); err == nil {
+			moves = append(moves, m)
+			weights = append(weights, bm.Weight)
+		}
+	}
+	return moves, weights
+}
+
+// Best returns the book's top move for s.
+func (b *Book) Best(s engine.State) (engine.Move, bool) {
+	moves, weights := b.lookup(s)
+	best := -1
+	for i := range moves {
+		if best < 0 || weights[i] > weights[best] {
+			best = i
+		}
+	}
+	if best < 0 {
+		return engine.Move{}, false
+	}
+	return moves[best], true
+}
+
+// Pick returns one of the book's moves for s, chosen at random in
+// proportion to the weights so the computer doesn't always open the same.
+func (b *Book) Pick(s engine.State) (engine.Move, bool) {
+	moves, weights := b.lookup(s)
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total == 0 {
+		return engine.Move{}, false
+	}
+	r := rand.Float64() * total
+	for i, w := range weights {
+		if r -= w; r < 0 {
+			return moves[i], true
+		}
+	}
+	return moves[len(moves)-1], true
+}
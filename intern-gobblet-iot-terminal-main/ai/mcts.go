@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"goblets/engine"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// playoutPlies ends a random playout as a draw: pieces can shuffle back and
+// forth forever.
+const playoutPlies = 80
+
+// exploration is the UCT constant weighing rarely tried moves against
+// moves that have scored well.
+const exploration = 1.4
+
+// node is a position in the Monte Carlo search tree.
+type node struct {
+	state    engine.State
+	move     engine.Move // the move that led here
+	mover    int         // the player who made it
+	parent   *node
+	children []*node
+	untried  []engine.Move
+	visits   int
+	wins     float64 // for mover; a draw counts half
+}
+
+func newNode(s engine.State, m engine.Move, mover int, parent *node) *node {
+	return &node{state: s, move: m, mover: mover, parent: parent, untried: engine.LegalMoves(s, s.Turn)}
+}
+
+// chooseMCTS runs up to l.Playouts random playouts from s, within l.Time,
+// and returns the move that was explored most.
+func chooseMCTS(s engine.State, l Limits) engine.Move {
+	root := newNode(s, engine.Move{}, 3-s.Turn, nil)
+	var deadline time.Time
+	if l.Time > 0 {
+		deadline = time.Now().Add(l.Time)
+	}
+	for i := 0; i < max(l.Playouts, 1); i++ {
+		if !deadline.IsZero() && i%64 == 0 && time.Now().After(deadline) {
+			break
+		}
+		n := root
+		for len(n.untried) == 0 && len(n.children) > 0 {
+			n = n.bestChild()
+		}
+		if len(n.untried) > 0 {
+			n = n.expand()
+		}
+		n.update(playout(n.state))
+	}
+
+	best := root.children[0]
+	for _, c := range root.children {
+		if c.visits > best.visits {
+			best = c
+		}
+	}
+	return best.move
+}
+
+// bestChild picks the child with the highest upper confidence bound.
+func (n *node) bestChild() *node {
+	var best *node
+	bestValue := math.Inf(-1)
+	logVisits := math.Log(float64(n.visits))
+	for _, c := range n.children {
+		v := c.wins/float64(c.visits) + exploration*math.Sqrt(logVisits/float64(c.visits))
+		if v > bestValue {
+			best, bestValue = c, v
+		}
+	}
+	return best
+}
+
+// expand adds the child for one untried move, picked at random.
+func (n *node) expand() *node {
+	i := rand.IntN(len(n.untried))
+	m := n.untried[i]
+	n.untried[i] = n.untried[len(n.untried)-1]
+	n.untried = n.untried[:len(n.untried)-1]
+
+	next, err := n.state.Apply(m)
+	if err != nil {
+		next = n.state // LegalMoves only returns moves Apply accepts
+	}
+	c := newNode(next, m, n.state.Turn, n)
+	n.children = append(n.children, c)
+	return c
+}
+
+// playout plays random moves from s and returns the winner, or 0 for a draw.
+func playout(s engine.State) int {
+	for range playoutPlies {
+		if s.Winner != 0 {
+			return s.Winner
+		}
+		moves := engine.LegalMoves(s, s.Turn)
+		if len(moves) == 0 {
+			return 0
+		}
+		next, err := s.Apply(moves[rand.IntN(len(moves))])
+		if err != nil {
+			return 0
+		}
+		s = next
+	}
+	return s.Winner
+}
+
+// update counts a playout won by winner from n up to the root.
+func (n *node) update(winner int) {
+	for ; n != nil; n = n.parent {
+		n.visits++
+		switch winner {
+		case n.mover:
+			n.wins++
+		case 0:
+			n.wins += 0.5
+		}
+	}
+}
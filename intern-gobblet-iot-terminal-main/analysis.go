@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"goblets/ai"
+	"goblets/config"
+	"goblets/engine"
+	"goblets/notation"
+	"time"
+)
+
+// analysisTime bounds the search of each position in the analysis.
+const analysisTime = 2 * time.Second
+
+// moveAnalysis is the engine's verdict on one move of the game.
+type moveAnalysis struct {
+	Entry HistoryEntry
+	ai.Analysis
+}
+
+// loss is how much worse the played move scored than the best one.
+func (m moveAnalysis) loss() int {
+	return m.Best - m.Played
+}
+
+// analyzeGame replays moves from the empty board and has the engine score
+// every move against the best one in its position.
+func analyzeGame(moves []HistoryEntry, size, depth int) []moveAnalysis {
+	b := engine.NewBoard(size)
+	limits := ai.Limits{Depth: depth, Time: analysisTime}
+	var out []moveAnalysis
+	for _, e := range moves {
+		s := position(b, e.Player)
+		out = append(out, moveAnalysis{Entry: e, Analysis: ai.Analyze(s, e.Move, limits)})
+		next, err := s.Apply(e.Move)
+		if err != nil {
+			break
+		}
+		b = next.Board
+	}
+	return out
+}
+
+// losingBlunder picks the loser's move that threw the game away: the first
+// that turned a position that wasn't lost into a lost one, or failing that
+// the one that lost the most. It returns -1 when there is none.
+func losingBlunder(moves []moveAnalysis, loser int) int {
+	worst := -1
+	for i, m := range moves {
+		if m.Entry.Player != loser {
+			continue
+		}
+		if ai.Decisive(m.Best) >= 0 && ai.Decisive(m.Played) < 0 {
+			return i
+		}
+		if m.loss() > 0 && (worst < 0 || m.loss() > moves[worst].loss()) {
+			worst = i
+		}
+	}
+	return worst
+}
+
+// scoreText shows a score for the player who moved.
+func scoreText(score int) string {
+	switch ai.Decisive(score) {
+	case 1:
+		return "wins"
+	case -1:
+		return "loses"
+	}
+	return fmt.Sprintf("%+d", score)
+}
+
+// printAnalysis prints the engine's evaluation of every move of the game,
+// marking the move that lost it.
+func printAnalysis(result *Result) {
+	moves := activeMoves(history)
+	if len(moves) == 0 {
+		return
+	}
+	depth := config.Conf.Analysis.Depth
+	fmt.Printf("\n🔎 Analyzing %d moves (depth %d)...\n", len(moves), depth)
+	analysis := analyzeGame(moves, board.Size(), depth)
+	blunder := -1
+	if !result.IsDraw() {
+		blunder = losingBlunder(analysis, 3-result.Winner())
+	}
+	for i, m := range analysis {
+		line := fmt.Sprintf("%3d. Player %d  %-9s %6s", i+1, m.Entry.Player, notation.Format(m.Entry.Move), scoreText(m.Played))
+		if m.loss() > 0 {
+			line += fmt.Sprintf("   best was %s (%s)", notation.Format(m.BestMove), scoreText(m.Best))
+		}
+		if i == blunder {
+			line += "   ⚠ losing blunder"
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"goblets/config"
+	"goblets/engine"
+	"time"
+)
+
+const (
+	animationFrame = 80 * time.Millisecond
+	colorTrail     = "\033[7m"  // reverse video along the moved piece's path
+	colorGobble    = "\033[43m" // yellow background when a piece is gobbled
+)
+
+// highlighted maps view cells to the style they are drawn with in the
+// frame being rendered.
+var highlighted map[[2]int]string
+
+// animatedMoves is the history length whose last move has been animated.
+var animatedMoves int
+
+// highlight wraps the text drawn for view cell r, c in its highlight.
+func highlight(r, c int, text string) string {
+	if style, ok := highlighted[[2]int{r, c}]; ok {
+		return style + text + colorReset
+	}
+	return text
+}
+
+// animationFrames returns the highlights of each frame to draw. Without a
+// new move to animate there is a single plain frame.
+func animationFrames() []map[[2]int]string {
+	plain := []map[[2]int]string{nil}
+	if !config.Conf.Animate || len(history) <= animatedMoves {
+		animatedMoves = len(history)
+		return plain
+	}
+	animatedMoves = len(history)
+	last := history[len(history)-1]
+	if last.Undo {
+		return plain
+	}
+
+	m := last.Move
+	var frames []map[[2]int]string
+	for _, cell := range movePath(m) {
+		r, c := toView(cell[0], cell[1])
+		frames = append(frames, map[[2]int]string{{r, c}: colorTrail})
+	}
+	to := frames[len(frames)-1]
+	if len(board[m.Row][m.Col]) > 1 {
+		r, c := toView(m.Row, m.Col)
+		gobble := map[[2]int]string{{r, c}: colorGobble}
+		frames = append(frames, gobble, to, gobble)
+	}
+	return append(frames, to)
+}
+
+// movePath lists the board cells a piece passes: the cells between source
+// and target when they share a row, column or diagonal, otherwise just the
+// two ends. A placement only lands.
+func movePath(m engine.Move) [][2]int {
+	to := [2]int{m.Row, m.Col}
+	if m.IsPlacement() {
+		return [][2]int{to}
+	}
+	dr, dc := m.Row-m.FromRow, m.Col-m.FromCol
+	if dr != 0 && dc != 0 && abs(dr) != abs(dc) {
+		return [][2]int{{m.FromRow, m.FromCol}, to}
+	}
+	steps := max(abs(dr), abs(dc))
+	var path [][2]int
+	for k := 0; k <= steps; k++ {
+		path = append(path, [2]int{m.FromRow + k*dr/steps, m.FromCol + k*dc/steps})
+	}
+	return path
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// printFrames draws the board once per frame, each over the last.
+func printFrames(frames []map[[2]int]string) {
+	clearLine := ""
+	if len(frames) > 1 {
+		clearLine = "\033[2K"
+	}
+	lines := 0
+	for k, hl := range frames {
+		highlighted = hl
+		if k > 0 {
+			time.Sleep(animationFrame)
+			fmt.Printf("\033[%dA", lines) // back up over the previous frame
+		}
+		frame := boardPanes()
+		for _, line := range frame {
+			fmt.Print(clearLine, line, "\n")
+		}
+		lines = len(frame)
+	}
+	highlighted = nil
+}
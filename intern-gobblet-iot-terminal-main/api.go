@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"goblets/engine"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// PlayerIdentity is a player injected by an external platform.
+type PlayerIdentity struct {
+	Seat int    `json:"seat"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// createGameRequest is the body of POST /games.
+type createGameRequest struct {
+	Players     []PlayerIdentity `json:"players"`
+	Preset      string           `json:"preset"`
+	Title       string           `json:"title"`
+	Tags        []string         `json:"tags"`
+	Tournament  string           `json:"tournament"`
+	Rated       bool             `json:"rated"`
+	Variant     engine.Variant   `json:"variant"`
+	BoardSize   int              `json:"board_size"`
+	CallbackURL string           `json:"callback_url"`
+}
+
+// resultCallback is POSTed to the platform when a game it created ends.
+type resultCallback struct {
+	GameID  string           `json:"game_id"`
+	Players []PlayerIdentity `json:"players"`
+	Result  Result           `json:"result"`
+	Moves   int              `json:"moves"`
+}
+
+// apiServer lets an external matchmaking service create games and receive
+// their results.
+type apiServer struct {
+	mu        sync.Mutex
+	callbacks map[string]string // game ID -> callback URL
+	states    map[string]GameState
+}
+
+func (s *apiServer) authorized(r *http.Request) bool {
+	token := config.Conf.API.Token
+	return token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *apiServer) createGame(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	var req createGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if req.Preset == "" {
+		req.Preset = defaultPreset
+	}
+	if err := checkRosterPlayers(req.Players); err != nil {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	}
+	if !req.Variant.Valid() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown variant " + string(req.Variant)})
+		return
+	}
+	if req.BoardSize == 0 {
+		req.BoardSize = req.Variant.Rules().Size
+	}
+	if req.BoardSize < engine.MinSize || req.BoardSize > engine.MaxSize {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("board_size must be between %d and %d", engine.MinSize, engine.MaxSize)})
+		return
+	}
+	if req.Tournament != "" && !validGameID(req.Tournament) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid tournament ID"})
+		return
+	}
+	preset, err := resolvePreset(req.Preset, req.BoardSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	id, err := freshGameID()
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	state := GameState{
+		Board:      engine.NewBoard(req.BoardSize),
+		Variant:    req.Variant,
+		PlayerTurn: 1,
+		Meta: GameMeta{
+			Preset:     preset.Name,
+			Rules:      preset.Rules,
+			Rated:      req.Rated,
+			Title:      req.Title,
+			Tags:       req.Tags,
+			Tournament: req.Tournament,
+			Players:    req.Players,
+		},
+		Clocks: newClocks(preset.Rules),
+	}
+	if err := publishNewGame(id, state); err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	s.states[id] = state
+	if req.CallbackURL != "" {
+		s.callbacks[id] = req.CallbackURL
+	}
+	s.mu.Unlock()
+
+	fmt.Printf("🆕 Created game %s for %d players via API\n", id, len(req.Players))
+	writeJSON(w, http.StatusCreated, map[string]string{"game_id": id, "topic": gameTopic(id, channelState)})
+}
+
+// publishNewGame publishes the initial state of a game created for others
+// to join, and announces it.
+func publishNewGame(id string, state GameState) error {
+	if state.Seq == 0 {
+		state.Seq, state.Sender = 1, clientID
+	}
+	token := publishPayload(gameTopic(id, channelState), 1, true, encodeState(state))
+	if token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	e := gameCreatedEvent(id, state)
+	publishEvent(e.EventHeader, e)
+	return nil
+}
+
+func (s *apiServer) getGame(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	s.mu.Lock()
+	state, ok := s.states[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown game"})
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// onGameState tracks games created through the API and fires the result
+// callback once a game ends.
+func (s *apiServer) onGameState(client mqtt.Client, msg mqtt.Message) {
+	var state GameState
+	if err := decodeState(unwrapPayload(msg.Payload()), &state); err != nil {
+		return
+	}
+	id := topicGameID(msg.Topic())
+
+	s.mu.Lock()
+	if _, ok := s.states[id]; !ok {
+		s.mu.Unlock()
+		return
+	}
+	s.states[id] = state
+	url, hasCallback := s.callbacks[id]
+	if state.Result != nil && hasCallback {
+		delete(s.callbacks, id)
+	}
+	s.mu.Unlock()
+
+	if state.Result != nil && hasCallback {
+		go postResultCallback(url, resultCallback{GameID: id, Players: state.Meta.Players, Result: *state.Result, Moves: state.Moves})
+	}
+}
+
+// postResultCallback delivers a result, retrying with backoff.
+func postResultCallback(url string, cb resultCallback) {
+	data, _ := json.Marshal(cb)
+	backoff := time.Second
+	for attempt := 1; attempt <= 5; attempt++ {
+		resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				fmt.Printf("📨 Result for game %s delivered to %s\n", cb.GameID, url)
+				return
+			}
+			err = fmt.Errorf("callback returned %s", resp.Status)
+		}
+		fmt.Printf("⚠ Result callback for game %s failed (attempt %d): %v\n", cb.GameID, attempt, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// runServeAPI exposes game creation to external matchmaking platforms.
+func runServeAPI(args []string) {
+	if config.Conf.API.Token == "" {
+		log.Fatal("❌ Set api.token in config.yaml: the game API doesn't run without a token")
+	}
+	connectMQTT()
+
+	s := &apiServer{callbacks: map[string]string{}, states: map[string]GameState{}}
+	if token := mqttClient.Subscribe(gameTopic("+", channelState), 1, s.onGameState); token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error:", token.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /games", s.createGame)
+	mux.HandleFunc("GET /games/{id}", s.getGame)
+	mux.HandleFunc("GET /healthz", healthz)
+
+	addr := config.Conf.API.Listen
+	fmt.Println("🌐 Game API listening on", addr)
+	daemonReady()
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"goblets/config"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorized(t *testing.T) {
+	saved := config.Conf.API.Token
+	t.Cleanup(func() { config.Conf.API.Token = saved })
+	s := &apiServer{}
+	request := func(auth string) bool {
+		r := httptest.NewRequest("POST", "/games", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		return s.authorized(r)
+	}
+
+	config.Conf.API.Token = ""
+	if request("") || request("Bearer ") {
+		t.Error("authorized a request with no token configured")
+	}
+	config.Conf.API.Token = "secret"
+	if !request("Bearer secret") {
+		t.Error("refused the right token")
+	}
+	if request("") || request("Bearer wrong") || request("secret") {
+		t.Error("authorized a request without the right token")
+	}
+}
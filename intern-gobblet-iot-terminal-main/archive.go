@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"goblets/config"
+	"goblets/engine"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveRecord is the stored summary of a finished game.
+type ArchiveRecord struct {
+	GameID     string
+	Finished   time.Time
+	Meta       GameMeta
+	Variant    engine.Variant `json:",omitempty"`
+	HouseRules *engine.Rules  `json:",omitempty"`
+	Result     Result
+	Moves      int
+	Player     int    // seat of the local player, 0 when spectating
+	PlayerName string `json:",omitempty"`
+	Device     string `json:",omitempty"` // stable ID of the device that recorded the game
+	Board      Board
+	// Certificate is the signed result certificate issued to a seated player.
+	Certificate string `json:",omitempty"`
+}
+
+// archiveDir is the local storage backend for finished games.
+func archiveDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".gobblet", "archive")
+}
+
+// archiveGame stores the finished game in the local archive.
+func archiveGame(result *Result) {
+	record := ArchiveRecord{
+		GameID:     gameID,
+		Finished:   time.Now().UTC(),
+		Meta:       gameMeta,
+		Variant:    variant,
+		HouseRules: houseRules,
+		Result:     *result,
+		Moves:      moveCount,
+		Board:      board,
+		Device:     deviceID,
+	}
+	if playerID == 1 || playerID == 2 {
+		record.Player = playerID
+		record.PlayerName = config.Conf.PlayerName
+		if cert, err := issueCertificate(result); err != nil {
+			fmt.Println("⚠ Could not issue result certificate:", err)
+		} else {
+			record.Certificate = cert
+			fmt.Println("🏅 Result certificate (check with `verify-cert`):")
+			fmt.Println(cert)
+		}
+	}
+
+	if err := os.MkdirAll(archiveDir(), 0o700); err != nil {
+		fmt.Println("⚠ Could not archive game:", err)
+		return
+	}
+	saveArchiveRecord(&record)
+	reportLeague(&record)
+	if config.Conf.Backup.AfterGame {
+		if err := backup(); err != nil {
+			fmt.Println("⚠ Backup failed:", err)
+		}
+	}
+}
+
+// loadArchive returns every archived game, oldest first.
+func loadArchive() ([]ArchiveRecord, error) {
+	files, err := filepath.Glob(filepath.Join(archiveDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var records []ArchiveRecord
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var r ArchiveRecord
+		if err := json.Unmarshal(data, &r); err != nil {
+			fmt.Printf("⚠ Skipping unreadable archive entry %s: %v\n", filepath.Base(file), err)
+			continue
+		}
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Finished.Before(records[j].Finished) })
+	return records, nil
+}
+
+// localOutcome describes the result from the archived player's point of view.
+func (r ArchiveRecord) localOutcome() string {
+	switch {
+	case r.Result.IsDraw():
+		return "draw"
+	case r.Player == 0:
+		return "-"
+	case r.Result.Winner() == r.Player:
+		return "win"
+	}
+	return "loss"
+}
+
+// runGames implements the archive query commands.
+func runGames(args []string) {
+	if len(args) == 0 || args[0] != "search" {
+		fmt.Println("Usage: games search [--player NAME] [--result win|loss|draw] [--since YYYY-MM-DD] [--tag TAG] [--json]")
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("games search", flag.ExitOnError)
+	player := fs.String("player", "", "only games played by this player name")
+	result := fs.String("result", "", "only games with this result for the player: win, loss or draw")
+	since := fs.String("since", "", "only games finished on or after this date (YYYY-MM-DD)")
+	tag := fs.String("tag", "", "only games carrying this tag")
+	asJSON := fs.Bool("json", false, "print matching games as JSON lines")
+	fs.Parse(args[1:])
+
+	var sinceTime time.Time
+	if *since != "" {
+		var err error
+		if sinceTime, err = time.Parse("2006-01-02", *since); err != nil {
+			fmt.Println("❌ Invalid --since date:", err)
+			os.Exit(1)
+		}
+	}
+
+	records, err := loadArchive()
+	if err != nil {
+		fmt.Println("❌ Could not read archive:", err)
+		os.Exit(1)
+	}
+
+	var matches []ArchiveRecord
+	for _, r := range records {
+		if *player != "" && !strings.EqualFold(r.PlayerName, *player) {
+			continue
+		}
+		if *result != "" && r.localOutcome() != *result {
+			continue
+		}
+		if !sinceTime.IsZero() && r.Finished.Before(sinceTime) {
+			continue
+		}
+		if *tag != "" && !slices.Contains(r.Meta.Tags, *tag) {
+			continue
+		}
+		matches = append(matches, r)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, r := range matches {
+			r.PlayerName = publicName(r.PlayerName)
+			enc.Encode(r)
+		}
+		return
+	}
+	printArchiveTable(matches)
+}
+
+func printArchiveTable(records []ArchiveRecord) {
+	if len(records) == 0 {
+		fmt.Println("No matching games.")
+		return
+	}
+	fmt.Printf("%-8s %-16s %-18s %-5s %-12s %-6s %s\n", "GAME", "FINISHED", "PRESET", "SCORE", "TERMINATION", "MOVES", "TITLE")
+	for _, r := range records {
+		fmt.Printf("%-8s %-16s %-18s %-5s %-12s %-6d %s\n",
+			r.GameID, r.Finished.Local().Format("2006-01-02 15:04"), r.Meta.Preset,
+			r.Result.Outcome, r.Result.Termination, r.Moves, r.Meta.label())
+	}
+}
+
+// readArchiveRecord reads a single archived game by ID.
+func readArchiveRecord(id string) (*ArchiveRecord, error) {
+	data, err := os.ReadFile(filepath.Join(archiveDir(), id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("game %s is not in the archive", id)
+	}
+	var r ArchiveRecord
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// saveArchiveRecord writes (or overwrites) an archived game.
+func saveArchiveRecord(r *ArchiveRecord) {
+	data, _ := json.MarshalIndent(r, "", "  ")
+	if err := os.WriteFile(filepath.Join(archiveDir(), r.GameID+".json"), data, 0o600); err != nil {
+		fmt.Println("⚠ Could not archive game:", err)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"goblets/engine"
+	"math"
+	"os"
+	"time"
+)
+
+// arenaScore tallies games from the first player's side.
+type arenaScore struct {
+	Wins, Draws, Losses int
+}
+
+func (a *arenaScore) add(r *Result, seat int) {
+	switch {
+	case r.IsDraw():
+		a.Draws++
+	case r.Winner() == seat:
+		a.Wins++
+	default:
+		a.Losses++
+	}
+}
+
+func (a arenaScore) games() int { return a.Wins + a.Draws + a.Losses }
+
+// mean and variance of the per-game score (1, ½ or 0).
+func (a arenaScore) stats() (mean, variance float64) {
+	n := float64(a.games())
+	if n == 0 {
+		return 0.5, 0
+	}
+	mean = (float64(a.Wins) + float64(a.Draws)/2) / n
+	variance = (float64(a.Wins)*math.Pow(1-mean, 2) + float64(a.Draws)*math.Pow(0.5-mean, 2) + float64(a.Losses)*math.Pow(mean, 2)) / n
+	return mean, variance
+}
+
+// eloDiff converts an expected score into an Elo difference.
+func eloDiff(score float64) float64 {
+	score = min(max(score, 0.001), 0.999)
+	return -400 * math.Log10(1/score-1)
+}
+
+func expectedScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// llr is the log-likelihood ratio of the hypothesis that the first player
+// is elo1 stronger against it being elo0 stronger, in the normal
+// approximation engine testers use for the sequential probability ratio test.
+func (a arenaScore) llr(elo0, elo1 float64) float64 {
+	if a.games() == 0 {
+		return 0
+	}
+	// A win and a loss of prior keep the variance from being zero while
+	// every game has gone the same way.
+	mean, variance := arenaScore{a.Wins + 1, a.Draws, a.Losses + 1}.stats()
+	s0, s1 := expectedScore(elo0), expectedScore(elo1)
+	return float64(a.games()) * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+// runArena plays two engines, built-in or external, against each other
+// with alternating colors and reports the results, optionally stopping as
+// soon as a sequential probability ratio test decides.
+func runArena(args []string) {
+	fs := flag.NewFlagSet("arena", flag.ExitOnError)
+	games := fs.Int("games", 100, "most games to play; the players swap seats every game")
+	p1 := fs.String("p1", "hard", "engine under test, level[:engine] or ext:<command>")
+	p2 := fs.String("p2", "medium", "baseline engine, level[:engine] or ext:<command>")
+	movetime := fs.Duration("movetime", time.Second, "time per move for external engines")
+	variantName := fs.String("variant", "junior", "junior or classic")
+	maxPlies := fs.Int("max-plies", 200, "adjudicate a game as a draw after this many moves")
+	sprt := fs.Bool("sprt", false, "stop when the SPRT accepts elo0 or elo1")
+	elo0 := fs.Float64("elo0", 0, "SPRT null hypothesis: p1 is this many Elo stronger")
+	elo1 := fs.Float64("elo1", 20, "SPRT alternative hypothesis")
+	alpha := fs.Float64("alpha", 0.05, "SPRT false positive rate")
+	beta := fs.Float64("beta", 0.05, "SPRT false negative rate")
+	fs.Parse(args)
+
+	v := engine.Variant(*variantName)
+	a, errA := parseSelfPlayer(*p1)
+	b, errB := parseSelfPlayer(*p2)
+	if !v.Valid() || errA != nil || errB != nil || *elo1 <= *elo0 {
+		fmt.Println("❌ Usage: arena [--games N] [--p1 spec] [--p2 spec] [--movetime 1s] [--sprt --elo0 0 --elo1 20]")
+		os.Exit(1)
+	}
+	a.Movetime, b.Movetime = *movetime, *movetime
+	for _, p := range []*selfPlayer{&a, &b} {
+		if err := p.start(); err != nil {
+			fmt.Println("❌ Could not start engine", err)
+			os.Exit(1)
+		}
+		defer p.stop()
+	}
+
+	lower, upper := math.Log(*beta/(1-*alpha)), math.Log((1-*beta)/(*alpha))
+	var total arenaScore
+	var bySeat [2]arenaScore // p1's results as Player 1 and as Player 2
+	verdict := ""
+	for g := 0; g < *games && verdict == ""; g++ {
+		players, seat := [2]selfPlayer{a, b}, 1
+		if g%2 == 1 {
+			players, seat = [2]selfPlayer{b, a}, 2
+		}
+		result, moves := selfPlayGame(players, v, *maxPlies, nil)
+		total.add(result, seat)
+		bySeat[seat-1].add(result, seat)
+
+		line := fmt.Sprintf("🎲 Game %d: %s vs %s, %s after %d moves  (+%d =%d -%d)", g+1, players[0].Name, players[1].Name, result, len(moves), total.Wins, total.Draws, total.Losses)
+		if *sprt {
+			llr := total.llr(*elo0, *elo1)
+			line += fmt.Sprintf("  LLR %.2f [%.2f, %.2f]", llr, lower, upper)
+			switch {
+			case llr >= upper:
+				verdict = fmt.Sprintf("H1 accepted: %s is at least %+.0f Elo", a.Name, *elo1)
+			case llr <= lower:
+				verdict = fmt.Sprintf("H0 accepted: %s is not %+.0f Elo stronger", a.Name, *elo1)
+			}
+		}
+		fmt.Println(line)
+	}
+
+	n := total.games()
+	mean, variance := total.stats()
+	margin := 1.96 * math.Sqrt(variance/float64(max(n, 1)))
+	fmt.Printf("📊 Arena results after %d games\n", n)
+	fmt.Printf("  %-20s %5s %6s %7s %7s\n", "Engine", "Wins", "Draws", "Losses", "Score")
+	fmt.Printf("  %-20s %5d %6d %7d %6.1f%%\n", a.Name, total.Wins, total.Draws, total.Losses, 100*mean)
+	fmt.Printf("  %-20s %5d %6d %7d %6.1f%%\n", b.Name, total.Losses, total.Draws, total.Wins, 100*(1-mean))
+	fmt.Printf("  %s as Player 1: +%d =%d -%d, as Player 2: +%d =%d -%d\n", a.Name,
+		bySeat[0].Wins, bySeat[0].Draws, bySeat[0].Losses, bySeat[1].Wins, bySeat[1].Draws, bySeat[1].Losses)
+	fmt.Printf("  Elo difference: %+.0f (95%%: %+.0f to %+.0f)\n", eloDiff(mean), eloDiff(mean-margin), eloDiff(mean+margin))
+	if *sprt {
+		if verdict == "" {
+			verdict = "inconclusive, play more games"
+		}
+		fmt.Printf("  SPRT [%+.0f, %+.0f]: %s\n", *elo0, *elo1, verdict)
+	}
+}
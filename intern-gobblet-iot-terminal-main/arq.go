@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// The ordered delivery layer wraps every game state in an envelope carrying
+// a per-sender sequence number. Receivers buffer out-of-order envelopes,
+// drop duplicates and ask for missing ones with a NACK, so the game logic
+// sees each sender's states exactly once and in order, whatever the broker
+// does with QoS 1 redeliveries.
+
+const (
+	arqOutboxSize = 64               // envelopes kept for retransmission
+	arqGapTimeout = 10 * time.Second // after which a gap no NACK filled is skipped
+)
+
+// Envelope is the ordered-delivery wrapper around a game state payload. A
+// JSON state is carried as is in Body, a protobuf or CBOR one in Data.
+type Envelope struct {
+	Sender string
+	Seq    uint64
+	Body   json.RawMessage `json:",omitempty"`
+	Data   []byte          `json:",omitempty"`
+}
+
+// newEnvelope wraps a state payload in whichever field can carry it.
+func newEnvelope(seq uint64, data []byte) Envelope {
+	if jsonPayload(data) {
+		return Envelope{Sender: clientID, Seq: seq, Body: data}
+	}
+	return Envelope{Sender: clientID, Seq: seq, Data: data}
+}
+
+// payload is the wrapped state.
+func (env Envelope) payload() []byte {
+	if len(env.Body) > 0 {
+		return env.Body
+	}
+	return env.Data
+}
+
+// Nack asks a sender to retransmit envelopes From..To inclusive.
+type Nack struct {
+	Sender string
+	From   uint64
+	To     uint64
+}
+
+// arqStream tracks what has been delivered from one sender.
+type arqStream struct {
+	next    uint64
+	pending map[uint64]Envelope
+	gapSeen time.Time      // when the current gap opened
+	nacked  time.Time      // when its envelopes were last asked for
+	deliver func(Envelope) // hands an envelope to the game-state handler
+}
+
+// flush delivers the envelopes that follow on from next. Callers hold
+// arqRecvMu.
+func (s *arqStream) flush() {
+	for {
+		env, ok := s.pending[s.next]
+		if !ok {
+			break
+		}
+		delete(s.pending, s.next)
+		s.next++
+		s.deliver(env)
+	}
+	if len(s.pending) == 0 {
+		s.gapSeen = time.Time{}
+	}
+}
+
+// lowestPending is the first envelope waiting behind a gap.
+func (s *arqStream) lowestPending() uint64 {
+	lowest := uint64(0)
+	for seq := range s.pending {
+		if lowest == 0 || seq < lowest {
+			lowest = seq
+		}
+	}
+	return lowest
+}
+
+// restarted reports whether seq, behind what has been delivered, is a
+// sender starting over rather than a duplicate: it went back to 1, or
+// further back than any retransmission could be.
+func (s *arqStream) restarted(seq uint64) bool {
+	return seq < s.next && ((seq == 1 && s.next > 2) || s.next-seq > arqOutboxSize)
+}
+
+var (
+	arqSendMu sync.Mutex
+	arqSeq    uint64
+	arqOutbox = map[uint64]Envelope{}
+
+	arqRecvMu   sync.Mutex
+	arqStreams  = map[string]*arqStream{}
+	arqNackOnce sync.Once
+)
+
+func nackTopic() string {
+	return "gobblet/game/" + gameID + "/nack"
+}
+
+// publishGameState publishes a game state on the retained game topic,
+// wrapped in an ordered-delivery envelope when the layer is enabled.
+func publishGameState(data []byte) mqtt.Token {
+	topic := stateTopic()
+	if !config.Conf.OrderedDelivery {
+		token := publishPayload(topic, stateQoS(), true, data)
+		go watchAck(token)
+		return token
+	}
+
+	arqSendMu.Lock()
+	arqSeq++
+	env := newEnvelope(arqSeq, data)
+	arqOutbox[env.Seq] = env
+	delete(arqOutbox, env.Seq-arqOutboxSize)
+	arqSendMu.Unlock()
+
+	payload, err := json.Marshal(env)
+	if err != nil {
+		// Never publish an empty retained payload: it would delete the game
+		fmt.Println("❌ Could not wrap the game state:", err)
+		return failedToken{err: err}
+	}
+	token := publishPayload(topic, stateQoS(), true, payload)
+	go watchAck(token)
+	return token
+}
+
+// unwrapPayload returns the game state inside an envelope, or the payload
+// unchanged when it was sent without one.
+func unwrapPayload(payload []byte) []byte {
+	payload = lastInBatch(payload)
+	var env Envelope
+	if json.Unmarshal(payload, &env) == nil && env.Sender != "" && len(env.payload()) > 0 {
+		return env.payload()
+	}
+	return payload
+}
+
+// ordered wraps a game-state handler so it receives each sender's states
+// exactly once and in sequence order.
+func ordered(handler mqtt.MessageHandler) mqtt.MessageHandler {
+	if !config.Conf.OrderedDelivery {
+		return handler
+	}
+	arqNackOnce.Do(startNackHandling)
+
+	return func(client mqtt.Client, msg mqtt.Message) {
+		var env Envelope
+		if json.Unmarshal(msg.Payload(), &env) != nil || env.Sender == "" {
+			handler(client, msg) // legacy sender without envelopes
+			return
+		}
+
+		arqRecvMu.Lock()
+		defer arqRecvMu.Unlock()
+
+		stream, ok := arqStreams[env.Sender]
+		if !ok || stream.restarted(env.Seq) {
+			// First envelope from this sender (often the retained one), or the
+			// sender started numbering again: start here
+			stream = &arqStream{next: env.Seq, pending: map[uint64]Envelope{}}
+			arqStreams[env.Sender] = stream
+		}
+		if env.Seq < stream.next {
+			return // duplicate
+		}
+		stream.deliver = func(e Envelope) { handler(client, envelopeMessage{msg, e.payload()}) }
+		stream.pending[env.Seq] = env
+		stream.flush()
+
+		if len(stream.pending) > 0 && stream.gapSeen.IsZero() {
+			stream.gapSeen = time.Now()
+			requestMissing(env.Sender, stream)
+		}
+	}
+}
+
+// requestMissing publishes a NACK for the gap before the lowest buffered
+// envelope. Callers hold arqRecvMu.
+func requestMissing(sender string, stream *arqStream) {
+	stream.nacked = time.Now()
+	data, _ := json.Marshal(Nack{Sender: sender, From: stream.next, To: stream.lowestPending() - 1})
+	mqttClient.Publish(nackTopic(), 1, false, data)
+	noteLinkLoss("missing states")
+}
+
+// skipGap gives up on the envelopes missing before the lowest buffered one,
+// e.g. when the sender no longer has them, and delivers what follows.
+// Callers hold arqRecvMu.
+func skipGap(sender string, stream *arqStream) {
+	lowest := stream.lowestPending()
+	fmt.Printf("\n⚠ States %d-%d from %s never arrived; carrying on without them.\n", stream.next, lowest-1, sender)
+	stream.next = lowest
+	stream.flush()
+	if len(stream.pending) > 0 {
+		stream.gapSeen = time.Now()
+		requestMissing(sender, stream)
+	}
+}
+
+// startNackHandling retransmits our envelopes when asked, re-requests gaps
+// that stay open and skips those that never fill.
+func startNackHandling() {
+	token := mqttClient.Subscribe(nackTopic(), 1, func(client mqtt.Client, msg mqtt.Message) {
+		var nack Nack
+		if json.Unmarshal(msg.Payload(), &nack) != nil || nack.Sender != clientID {
+			return
+		}
+		noteLinkLoss("resync request")
+		arqSendMu.Lock()
+		defer arqSendMu.Unlock()
+		for seq := nack.From; seq <= nack.To; seq++ {
+			env, ok := arqOutbox[seq]
+			if !ok {
+				continue
+			}
+			payload, _ := json.Marshal(env)
+			// Not retained: a retransmission must never replace the latest state
+			mqttClient.Publish(stateTopic(), stateQoS(), false, payload)
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		fmt.Println("⚠ Could not subscribe to retransmit requests:", token.Error())
+	}
+
+	go func() {
+		for range time.Tick(2 * time.Second) {
+			arqRecvMu.Lock()
+			for sender, stream := range arqStreams {
+				switch {
+				case stream.gapSeen.IsZero():
+				case time.Since(stream.gapSeen) > arqGapTimeout:
+					skipGap(sender, stream)
+				case time.Since(stream.nacked) > 2*time.Second:
+					requestMissing(sender, stream)
+				}
+			}
+			arqRecvMu.Unlock()
+		}
+	}()
+}
+
+// envelopeMessage presents an unwrapped envelope body as an mqtt.Message.
+type envelopeMessage struct {
+	mqtt.Message
+	body []byte
+}
+
+func (m envelopeMessage) Payload() []byte { return m.body }
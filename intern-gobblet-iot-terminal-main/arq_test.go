@@ -0,0 +1,148 @@
+package main
+
+import (
+	"goblets/config"
+	"goblets/engine"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// recordingClient keeps what is published instead of sending it.
+type recordingClient struct {
+	mqtt.Client
+	published []mqtt.Message
+}
+
+func (c *recordingClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	c.published = append(c.published, traceMessage{TraceEntry{Topic: topic, Binary: payloadBytes(payload)}})
+	return doneToken{}
+}
+
+// useRecordingClient swaps the broker connection for a recordingClient for
+// the rest of the test.
+func useRecordingClient(t *testing.T) *recordingClient {
+	t.Helper()
+	c := &recordingClient{}
+	old, oldID, oldGame := mqttClient, clientID, gameID
+	mqttClient, clientID, gameID = c, "test-client", "testgame"
+	t.Cleanup(func() { mqttClient, clientID, gameID = old, oldID, oldGame })
+	return c
+}
+
+func sampleState() GameState {
+	b := engine.NewBoard(3)
+	b[1][1] = engine.Stack{{Size: 1, Owner: 2}, {Size: 3, Owner: 1}}
+	return GameState{
+		Board: b, PlayerTurn: 2, Moves: 1, Variant: engine.Junior, Seq: 4, Sender: "test-client",
+		Meta:    GameMeta{Preset: "casual"},
+		History: []HistoryEntry{{Player: 1, Move: engine.Place(1, 1, 3), Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}},
+	}
+}
+
+func TestWireStateRoundTrip(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := sampleState()
+	want.Meta = GameMeta{
+		Preset: "kids-mode", Rules: Rules{BoardSize: 3, TimeControl: 180, Assists: Assists{Hints: true, ThreatWarnings: true}},
+		Rated: true, Title: "Class 3B", Tags: []string{"classroom"}, Players: []PlayerIdentity{{Seat: 2, ID: "s2", Name: "Ben"}},
+		FirstPlayer: 2, FirstChoice: "coin flip", Themes: map[int]string{1: "animals"}, Refereed: true, Tournament: "spring",
+	}
+	want.Rules = &engine.Rules{Size: 3, Sizes: 3, Memory: true, GobbleOnThreat: true}
+	want.Ruling = &AuditEntry{Time: at, Role: "referee", Action: "pause", GameID: "g1", Moves: 1, PublicKey: []byte{1}, Signature: []byte{2}}
+	want.Clocks = &Clocks{Remaining: [2]time.Duration{time.Minute, 2 * time.Minute}, TurnStart: at}
+	want.Result = newWinResult(1, TerminationResignation)
+	want.Winner, want.ChatOff, want.Seat = 1, true, 2
+	want.Stats.SizeUsage = [2][]int{{}, {}}
+
+	var got GameState
+	if err := fromWireState(toWireState(want), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestPublishGameStateRoundTrip(t *testing.T) {
+	for _, codec := range []string{wireJSON, wireProtobuf, wireCBOR} {
+		for _, orderedDelivery := range []bool{true, false} {
+			c := useRecordingClient(t)
+			useSeats(t, Seats{1: {Session: clientID}, 2: {Session: clientID}})
+			config.Conf.WireFormat, config.Conf.OrderedDelivery = codec, orderedDelivery
+			want := sampleState()
+
+			publishGameState(encodeState(want))
+			if len(c.published) != 1 {
+				t.Fatalf("%s: published %d messages, want 1", codec, len(c.published))
+			}
+			payload := c.published[0].Payload()
+			if len(payload) == 0 {
+				t.Fatalf("%s, ordered %v: published an empty retained state", codec, orderedDelivery)
+			}
+			var got GameState
+			if err := decodeState(unwrapPayload(payload), &got); err != nil {
+				t.Fatalf("%s, ordered %v: %v", codec, orderedDelivery, err)
+			}
+			if got.Board.Hash() != want.Board.Hash() || got.Seq != want.Seq || got.Meta.Preset != want.Meta.Preset ||
+				len(got.History) != 1 || !got.History[0].Time.Equal(want.History[0].Time) {
+				t.Errorf("%s, ordered %v: got %+v, want %+v", codec, orderedDelivery, got, want)
+			}
+		}
+	}
+	config.Conf.WireFormat, config.Conf.OrderedDelivery = wireJSON, true
+}
+
+// testStream is an ordered-delivery stream expecting next, recording what
+// it delivers.
+func testStream(next uint64, delivered *[]uint64) *arqStream {
+	return &arqStream{next: next, pending: map[uint64]Envelope{}, deliver: func(e Envelope) { *delivered = append(*delivered, e.Seq) }}
+}
+
+func TestSkipGap(t *testing.T) {
+	c := useRecordingClient(t)
+	var delivered []uint64
+	s := testStream(3, &delivered)
+	for _, seq := range []uint64{5, 6, 9} {
+		s.pending[seq] = Envelope{Seq: seq}
+	}
+	s.flush()
+	if len(delivered) > 0 {
+		t.Fatalf("delivered %v across a gap", delivered)
+	}
+
+	s.gapSeen = time.Now().Add(-arqGapTimeout)
+	skipGap("peer", s)
+	if !slices.Equal(delivered, []uint64{5, 6}) || s.next != 7 {
+		t.Errorf("after skipping: delivered %v, next %d", delivered, s.next)
+	}
+	if s.gapSeen.IsZero() || time.Since(s.gapSeen) > time.Second || len(c.published) != 1 {
+		t.Error("the gap before 9 wasn't opened and asked for")
+	}
+
+	skipGap("peer", s)
+	if !slices.Equal(delivered, []uint64{5, 6, 9}) || !s.gapSeen.IsZero() {
+		t.Errorf("after skipping again: delivered %v, gap %v", delivered, s.gapSeen)
+	}
+}
+
+func TestArqStreamRestarted(t *testing.T) {
+	var delivered []uint64
+	s := testStream(10, &delivered)
+	for seq, want := range map[uint64]bool{1: true, 9: false, 5: false, 10: false, 12: false} {
+		if got := s.restarted(seq); got != want {
+			t.Errorf("restarted(%d) with next 10 = %v", seq, got)
+		}
+	}
+	s.next = 2
+	if s.restarted(1) {
+		t.Error("a duplicate of the first envelope counts as a restart")
+	}
+	s.next = arqOutboxSize + 20
+	if !s.restarted(10) {
+		t.Error("going back further than the outbox isn't a restart")
+	}
+}
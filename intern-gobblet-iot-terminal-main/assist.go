@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"goblets/engine"
+	"io"
+	"strings"
+)
+
+const (
+	colorReset   = "\033[0m"
+	colorPlayer1 = "\033[31m" // red
+	colorPlayer2 = "\033[34m" // blue
+)
+
+// largeGlyphs holds the three-line drawing of each piece size.
+var largeGlyphs = map[int][3]string{
+	1: {"       ", "   ▪   ", "       "},
+	2: {"       ", "  ███  ", "  ███  "},
+	3: {" █████ ", " █████ ", " █████ "},
+	4: {"███████", "███████", "███████"},
+}
+
+// printLargeBoard renders the board with big colored pieces for young players.
+func printLargeBoard(w io.Writer) {
+	fmt.Fprintln(w, "\nCurrent Board:")
+	border := "+" + strings.Repeat("-------+", len(board))
+	fmt.Fprintln(w, border)
+	for i := range board {
+		for line := 0; line < 3; line++ {
+			fmt.Fprint(w, "|")
+			for j := range board[i] {
+				stack := viewStack(i, j)
+				if len(stack) == 0 {
+					fmt.Fprint(w, highlight(i, j, "       ")+"|")
+					continue
+				}
+				top := stack[len(stack)-1]
+				color := playerColor(top.Owner)
+				fmt.Fprint(w, highlight(i, j, color+largeGlyphs[top.Size][line])+colorReset+"|")
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, border)
+	}
+	fmt.Fprintf(w, "%sPlayer 1%s vs %sPlayer 2%s\n\n", playerColor(1), colorReset, playerColor(2), colorReset)
+}
+
+// warnThreats prints every line where a player holds all cells but one
+// and could complete it on their next move.
+func warnThreats() {
+	for _, line := range engine.Lines(board.Size()) {
+		owners := make([]int, len(line))
+		for k, cell := range line {
+			stack := board[cell[0]][cell[1]]
+			if len(stack) > 0 {
+				owners[k] = stack[len(stack)-1].Owner
+			}
+		}
+		for player := 1; player <= 2; player++ {
+			count, open := 0, -1
+			for k, owner := range owners {
+				if owner == player {
+					count++
+				} else {
+					open = k
+				}
+			}
+			if count == len(line)-1 {
+				r, c := toView(line[open][0], line[open][1])
+				fmt.Printf("⚠ Watch out! Player %d can complete a line at %d %d\n", player, r, c)
+			}
+		}
+	}
+}
+
+// announceSimpleResult prints a short, friendly result message.
+func announceSimpleResult(r *Result) {
+	if r.IsDraw() {
+		fmt.Println("🤝 It's a tie! Well played, both of you!")
+		return
+	}
+	fmt.Printf("🎉 Player %d wins! Great game!\n", r.Winner())
+}
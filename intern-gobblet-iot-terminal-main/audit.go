@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// AuditEntry is a signed record of a referee action, published to both
+// players so every ruling is visible and attributable.
+type AuditEntry struct {
+	Time      time.Time
+	Role      string
+	Action    string
+	Text      string
+	GameID    string `json:",omitempty"`
+	Moves     int    `json:",omitempty"` // move count the action was taken at
+	PublicKey []byte
+	Signature []byte `json:",omitempty"`
+}
+
+// ruling is the referee's entry for the latest pause, resumption or
+// adjudication, published with the game state.
+var ruling *AuditEntry
+
+func auditTopic() string {
+	return "gobblet/game/" + gameID + "/audit"
+}
+
+// signedBytes is the canonical encoding covered by the signature.
+func (e AuditEntry) signedBytes() []byte {
+	e.Signature = nil
+	data, _ := json.Marshal(e)
+	return data
+}
+
+// Verify checks the entry signature against the embedded public key.
+func (e AuditEntry) Verify() bool {
+	if len(e.PublicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(e.PublicKey, e.signedBytes(), e.Signature)
+}
+
+// signAudit signs an audit entry for a referee action taken at move moves.
+func signAudit(action, text string, moves int) (AuditEntry, error) {
+	key, err := loadSigningKey()
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	entry := AuditEntry{
+		Time:      time.Now().UTC(),
+		Role:      "referee",
+		Action:    action,
+		Text:      text,
+		GameID:    gameID,
+		Moves:     moves,
+		PublicKey: key.Public().(ed25519.PublicKey),
+	}
+	entry.Signature = ed25519.Sign(key, entry.signedBytes())
+	return entry, nil
+}
+
+// publishAudit signs and publishes an audit entry for a referee action.
+func publishAudit(action, text string) error {
+	mu.Lock()
+	moves := moveCount
+	mu.Unlock()
+	entry, err := signAudit(action, text, moves)
+	if err != nil {
+		return err
+	}
+	return publishAuditEntry(entry)
+}
+
+func publishAuditEntry(entry AuditEntry) error {
+	data, _ := json.Marshal(entry)
+	token := mqttClient.Publish(auditTopic(), 1, false, data)
+	token.Wait()
+	return token.Error()
+}
+
+func onAuditReceived(client mqtt.Client, msg mqtt.Message) {
+	var entry AuditEntry
+	if err := json.Unmarshal(msg.Payload(), &entry); err != nil {
+		fmt.Println("❌ Error decoding audit entry:", err)
+		return
+	}
+	if !entry.Verify() {
+		fmt.Println("❌ Ignoring audit entry with an invalid signature")
+		return
+	}
+	signer, trusted := trustedKeyName(entry.PublicKey)
+	if !trusted {
+		fmt.Printf("\n⚠ Ignoring a referee entry signed by %s…, which isn't in your trusted keys\n", signer)
+		return
+	}
+	fmt.Printf("\n⚖ [%s %s] %s", entry.Role, signer, entry.Action)
+	if entry.Text != "" {
+		fmt.Printf(": %s", entry.Text)
+	}
+	fmt.Println()
+}
+
+// checkRuling refuses a state that pauses, resumes or adjudicates the game
+// unless it carries the matching entry of a trusted referee: only a
+// referee decides those.
+func checkRuling(state GameState) error {
+	var action string
+	switch {
+	case board == nil:
+		return nil // loading the game: nothing changes yet
+	case state.Result != nil && state.Result.Termination == TerminationAdjudication && gameResult == nil:
+		action = "adjudication"
+	case state.Paused && !paused:
+		action = "pause"
+	case !state.Paused && paused:
+		action = "resume"
+	default:
+		return nil
+	}
+	r := state.Ruling
+	switch {
+	case r == nil:
+		return fmt.Errorf("the %s isn't signed by a referee", action)
+	case r.Action != action || r.GameID != gameID || r.Moves != state.Moves:
+		return fmt.Errorf("the referee's signed %q doesn't match this %s", r.Action, action)
+	case !r.Verify():
+		return errors.New("the referee's signature is invalid")
+	}
+	if signer, trusted := trustedKeyName(r.PublicKey); !trusted {
+		return fmt.Errorf("the %s is signed by %s…, which isn't in your trusted keys", action, signer)
+	}
+	return nil
+}
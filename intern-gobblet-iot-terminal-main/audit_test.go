@@ -0,0 +1,61 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"goblets/config"
+	"goblets/engine"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckRuling(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	trusted := filepath.Join(t.TempDir(), "trusted")
+	saved := config.Conf.StrictIdentity.KnownKeysFile
+	savedBoard, savedPaused, savedID, savedResult := board, paused, gameID, gameResult
+	t.Cleanup(func() {
+		config.Conf.StrictIdentity.KnownKeysFile = saved
+		board, paused, gameID, gameResult = savedBoard, savedPaused, savedID, savedResult
+	})
+	config.Conf.StrictIdentity.KnownKeysFile = trusted
+
+	refereePub, referee, _ := ed25519.GenerateKey(nil)
+	_, player, _ := ed25519.GenerateKey(nil)
+	os.WriteFile(trusted, []byte(base64.StdEncoding.EncodeToString(refereePub)+" referee\n"), 0o600)
+	board, paused, gameID, gameResult = engine.NewBoard(3), false, "g", nil
+
+	rule := func(key ed25519.PrivateKey, action string, moves int) *AuditEntry {
+		e := AuditEntry{Time: time.Now().UTC(), Role: "referee", Action: action, GameID: "g", Moves: moves, PublicKey: key.Public().(ed25519.PublicKey)}
+		e.Signature = ed25519.Sign(key, e.signedBytes())
+		return &e
+	}
+	adjudicated := newWinResult(2, TerminationAdjudication)
+
+	for _, tc := range []struct {
+		name  string
+		state GameState
+		ok    bool
+	}{
+		{"move", GameState{Moves: 4}, true},
+		{"unsigned pause", GameState{Moves: 4, Paused: true}, false},
+		{"pause", GameState{Moves: 4, Paused: true, Ruling: rule(referee, "pause", 4)}, true},
+		{"pause signed by a player", GameState{Moves: 4, Paused: true, Ruling: rule(player, "pause", 4)}, false},
+		{"pause replayed from another move", GameState{Moves: 4, Paused: true, Ruling: rule(referee, "pause", 2)}, false},
+		{"pause signed as a resumption", GameState{Moves: 4, Paused: true, Ruling: rule(referee, "resume", 4)}, false},
+		{"unsigned adjudication", GameState{Moves: 4, Result: adjudicated}, false},
+		{"adjudication", GameState{Moves: 4, Result: adjudicated, Ruling: rule(referee, "adjudication", 4)}, true},
+		{"resignation", GameState{Moves: 4, Result: newWinResult(2, TerminationResignation)}, true},
+	} {
+		if err := checkRuling(tc.state); (err == nil) != tc.ok {
+			t.Errorf("%s: checkRuling = %v", tc.name, err)
+		}
+	}
+
+	paused = true
+	if err := checkRuling(GameState{Moves: 4}); err == nil {
+		t.Error("accepted an unsigned resumption")
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"goblets/config"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// backupMagic starts every backup, followed by the key salt, the nonce and
+// the sealed tar.gz of ~/.gobblet.
+const backupMagic = "GOBBLET-BACKUP-1\n"
+
+const backupKeyIterations = 600_000
+
+func gobbletDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".gobblet")
+}
+
+func backupKey(salt []byte) ([]byte, error) {
+	if config.Conf.Backup.Passphrase == "" {
+		return nil, errors.New("set backup.passphrase in config.yaml first")
+	}
+	return pbkdf2.Key(sha256.New, config.Conf.Backup.Passphrase, salt, backupKeyIterations, 32)
+}
+
+// packBackup archives dir as tar.gz.
+func packBackup(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		hdr, _ := tar.FileInfoHeader(info, "")
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unpackBackup writes the files of a tar.gz into dir, refusing entries
+// that would land outside it.
+func unpackBackup(data []byte, dir string) (int, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	tr := tar.NewReader(zr)
+	files := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return files, err
+		}
+		if hdr.Typeflag != tar.TypeReg || !filepath.IsLocal(hdr.Name) {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return files, err
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return files, err
+		}
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			return files, err
+		}
+		files++
+	}
+}
+
+func sealBackup(plain []byte) ([]byte, error) {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	key, err := backupKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+	out := append([]byte(backupMagic), salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plain, []byte(backupMagic)), nil
+}
+
+func openBackup(sealed []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(sealed, []byte(backupMagic))
+	if !ok || len(rest) < 16+12 {
+		return nil, errors.New("not a gobblet backup")
+	}
+	key, err := backupKey(rest[:16])
+	if err != nil {
+		return nil, err
+	}
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	nonce := rest[16 : 16+gcm.NonceSize()]
+	plain, err := gcm.Open(nil, nonce, rest[16+gcm.NonceSize():], []byte(backupMagic))
+	if err != nil {
+		return nil, errors.New("wrong passphrase or damaged backup")
+	}
+	return plain, nil
+}
+
+// backupRequest calls the backup URL with the configured auth header.
+func backupRequest(method string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, config.Conf.Backup.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if name, value, ok := strings.Cut(config.Conf.Backup.AuthHeader, ":"); ok {
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("backup storage returned %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// backup encrypts ~/.gobblet and uploads it, replacing the previous backup.
+func backup() error {
+	if config.Conf.Backup.URL == "" {
+		return errors.New("set backup.url in config.yaml first")
+	}
+	plain, err := packBackup(gobbletDir())
+	if err != nil {
+		return err
+	}
+	sealed, err := sealBackup(plain)
+	if err != nil {
+		return err
+	}
+	if _, err := backupRequest(http.MethodPut, sealed); err != nil {
+		return err
+	}
+	fmt.Printf("☁ Backed up %s (%d KB)\n", gobbletDir(), len(sealed)/1024)
+	return nil
+}
+
+// runBackup uploads an encrypted copy of the local profile, keys, device
+// identity and game archive.
+func runBackup(args []string) {
+	if err := backup(); err != nil {
+		fmt.Println("❌ Backup failed:", err)
+		os.Exit(1)
+	}
+}
+
+// runRestore downloads the backup and unpacks it into ~/.gobblet, e.g. on a
+// reflashed device, which then keeps its identity and history.
+func runRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	force := fs.Bool("force", false, "overwrite an existing device identity")
+	fs.Parse(args)
+	if config.Conf.Backup.URL == "" {
+		fmt.Println("❌ Set backup.url in config.yaml first")
+		os.Exit(1)
+	}
+	if _, err := os.Stat(deviceIDFile()); err == nil && !*force {
+		fmt.Println("❌ This device already has an identity; use --force to replace it with the backup's")
+		os.Exit(1)
+	}
+
+	files, err := restore()
+	if err != nil {
+		fmt.Println("❌ Restore failed:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("☁ Restored %d file(s) into %s\n", files, gobbletDir())
+}
+
+// restore downloads, decrypts and unpacks the backup.
+func restore() (int, error) {
+	sealed, err := backupRequest(http.MethodGet, nil)
+	if err != nil {
+		return 0, err
+	}
+	plain, err := openBackup(sealed)
+	if err != nil {
+		return 0, err
+	}
+	return unpackBackup(plain, gobbletDir())
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"goblets/config"
+	"sync"
+	"sync/atomic"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// mqttOverhead approximates the fixed header and packet ID of a PUBLISH.
+const mqttOverhead = 4
+
+var (
+	bytesUsed      atomic.Int64
+	budgetWarnOnce sync.Once
+)
+
+// budgetMode reports whether a bandwidth budget is set for metered links.
+// Optional traffic (feed, update checks, ...) is skipped in budget mode.
+func budgetMode() bool {
+	return config.Conf.Bandwidth.BudgetKB > 0
+}
+
+// meteredClient counts the bytes a client sends and receives so a game can
+// be checked against the bandwidth budget.
+type meteredClient struct {
+	mqtt.Client
+}
+
+func (c meteredClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	size := len(topic) + mqttOverhead
+	switch p := payload.(type) {
+	case []byte:
+		size += len(p)
+	case string:
+		size += len(p)
+	}
+	countBytes(size)
+	return c.Client.Publish(topic, qos, retained, payload)
+}
+
+func (c meteredClient) Subscribe(topic string, qos byte, callback mqtt.MessageHandler) mqtt.Token {
+	countBytes(len(topic) + mqttOverhead)
+	return c.Client.Subscribe(topic, qos, func(client mqtt.Client, msg mqtt.Message) {
+		countBytes(len(msg.Topic()) + len(msg.Payload()) + mqttOverhead)
+		callback(client, msg)
+	})
+}
+
+func countBytes(n int) {
+	used := bytesUsed.Add(int64(n))
+	if used > int64(config.Conf.Bandwidth.BudgetKB)*1024 {
+		budgetWarnOnce.Do(func() {
+			fmt.Printf("\n⚠ Bandwidth budget of %d KB exceeded for this game\n", config.Conf.Bandwidth.BudgetKB)
+		})
+	}
+}
+
+// reportBandwidth prints the bytes used this game against the budget.
+func reportBandwidth() {
+	if !budgetMode() {
+		return
+	}
+	used := float64(bytesUsed.Load()) / 1024
+	budget := config.Conf.Bandwidth.BudgetKB
+	fmt.Printf("📶 Data used this game: %.1f KB of %d KB budget (%.0f%%)\n", used, budget, 100*used/float64(budget))
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Batch carries several payloads for one topic in a single publish. The
+// receiver handles them in order; readers that only want the latest state
+// take the last one.
+type Batch struct {
+	Batch []json.RawMessage
+}
+
+// batchWindow is how long bots and simulations collect messages per topic
+// before publishing them as one batch. 0 publishes every message on its own.
+var batchWindow time.Duration
+
+// batcher is the batching client in use, so it can be flushed on exit.
+var batcher *batchingClient
+
+// batchingClient collects publishes per topic for one window and sends
+// them as a single message, cutting per-message overhead and IoT Core costs
+// in automated play. Publishes return at once; delivery errors are printed
+// when the batch is sent.
+type batchingClient struct {
+	mqtt.Client
+	window  time.Duration
+	mu      sync.Mutex
+	pending map[string]*pendingBatch
+}
+
+type pendingBatch struct {
+	qos      byte
+	retained bool
+	items    []json.RawMessage
+}
+
+func newBatchingClient(client mqtt.Client, window time.Duration) *batchingClient {
+	batcher = &batchingClient{Client: client, window: window, pending: map[string]*pendingBatch{}}
+	return batcher
+}
+
+func (c *batchingClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	var data []byte
+	switch p := payload.(type) {
+	case []byte:
+		data = p
+	case string:
+		data = []byte(p)
+	}
+	if !json.Valid(data) {
+		return c.Client.Publish(topic, qos, retained, payload)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.pending[topic]
+	if b == nil {
+		b = &pendingBatch{}
+		c.pending[topic] = b
+		time.AfterFunc(c.window, func() { c.flush(topic) })
+	}
+	b.items = append(b.items, json.RawMessage(data))
+	b.qos = max(b.qos, qos)
+	b.retained = b.retained || retained
+	return doneToken{}
+}
+
+// flush publishes what has been collected for topic.
+func (c *batchingClient) flush(topic string) {
+	c.mu.Lock()
+	b := c.pending[topic]
+	delete(c.pending, topic)
+	c.mu.Unlock()
+	if b == nil {
+		return
+	}
+
+	data := []byte(b.items[0])
+	if len(b.items) > 1 {
+		data, _ = json.Marshal(Batch{Batch: b.items})
+	}
+	if token := c.Client.Publish(topic, b.qos, b.retained, data); token.Wait() && token.Error() != nil {
+		fmt.Printf("❌ Could not publish a batch of %d message(s) to %s: %v\n", len(b.items), topic, token.Error())
+	}
+}
+
+// flushAll publishes every pending batch now.
+func (c *batchingClient) flushAll() {
+	c.mu.Lock()
+	var topics []string
+	for topic := range c.pending {
+		topics = append(topics, topic)
+	}
+	c.mu.Unlock()
+	for _, topic := range topics {
+		c.flush(topic)
+	}
+}
+
+func (c *batchingClient) Disconnect(quiesce uint) {
+	c.flushAll()
+	c.Client.Disconnect(quiesce)
+}
+
+// flushBatches sends anything still waiting to be batched, before exiting.
+func flushBatches() {
+	if batcher != nil {
+		batcher.flushAll()
+	}
+}
+
+// doneToken is returned for batched publishes, which complete in the
+// background.
+type doneToken struct{}
+
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+func (doneToken) Wait() bool                       { return true }
+func (doneToken) WaitTimeout(_ time.Duration) bool { return true }
+func (doneToken) Done() <-chan struct{}            { return closedChan }
+func (doneToken) Error() error                     { return nil }
+
+// unbatched feeds each message of a batch to handler in order. Other
+// messages pass straight through.
+func unbatched(handler mqtt.MessageHandler) mqtt.MessageHandler {
+	return func(client mqtt.Client, msg mqtt.Message) {
+		var b Batch
+		if json.Unmarshal(msg.Payload(), &b) != nil || len(b.Batch) == 0 {
+			handler(client, msg)
+			return
+		}
+		for _, item := range b.Batch {
+			handler(client, envelopeMessage{Message: msg, body: item})
+		}
+	}
+}
+
+// lastInBatch returns the newest payload of a batch, or payload itself.
+func lastInBatch(payload []byte) []byte {
+	var b Batch
+	if json.Unmarshal(payload, &b) == nil && len(b.Batch) > 0 {
+		return b.Batch[len(b.Batch)-1]
+	}
+	return payload
+}
+
+// failedToken is returned for a publish that could not be sent at all.
+type failedToken struct {
+	doneToken
+	err error
+}
+
+func (t failedToken) Error() error { return t.err }
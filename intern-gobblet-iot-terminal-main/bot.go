@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"goblets/engine"
+	"goblets/notation"
+	"math/rand"
+	"os"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// scriptBot is a bot strategy written in Starlark. The script must define
+// choose_move(board, player, moves) and return one of the move strings.
+type scriptBot struct {
+	thread *starlark.Thread
+	choose starlark.Value
+	board  Board
+	player int
+}
+
+func loadScriptBot(path string) (*scriptBot, error) {
+	bot := &scriptBot{thread: &starlark.Thread{
+		Name:  "bot",
+		Print: func(_ *starlark.Thread, msg string) { fmt.Println("🤖", msg) },
+	}}
+	predeclared := starlark.StringDict{
+		"evaluate": starlark.NewBuiltin("evaluate", bot.evaluate),
+		"randint":  starlark.NewBuiltin("randint", randint),
+	}
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, bot.thread, path, nil, predeclared)
+	if err != nil {
+		return nil, err
+	}
+	choose, ok := globals["choose_move"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s does not define choose_move(board, player, moves)", path)
+	}
+	bot.choose = choose
+	return bot, nil
+}
+
+// boardValue exposes b to scripts as rows of cells, each a bottom-to-top
+// list of (size, owner) tuples.
+func boardValue(b Board) starlark.Value {
+	rows := make([]starlark.Value, 0, 3)
+	for i := range b {
+		cells := make([]starlark.Value, 0, 3)
+		for j := range b[i] {
+			stack := make([]starlark.Value, 0, len(b[i][j]))
+			for _, g := range b[i][j] {
+				stack = append(stack, starlark.Tuple{starlark.MakeInt(g.Size), starlark.MakeInt(g.Owner)})
+			}
+			cells = append(cells, starlark.NewList(stack))
+		}
+		rows = append(rows, starlark.NewList(cells))
+	}
+	return starlark.NewList(rows)
+}
+
+// chooseMove asks the script for a move on b.
+func (bot *scriptBot) chooseMove(b Board, player int) (Action, error) {
+	moves := legalMoves(&b, player)
+	if len(moves) == 0 {
+		return Action{}, fmt.Errorf("no legal moves")
+	}
+	values := make([]starlark.Value, len(moves))
+	for i, m := range moves {
+		values[i] = starlark.String(m.String())
+	}
+
+	bot.board, bot.player = b, player
+	result, err := starlark.Call(bot.thread, bot.choose, starlark.Tuple{boardValue(b), starlark.MakeInt(player), starlark.NewList(values)}, nil)
+	if err != nil {
+		return Action{}, err
+	}
+	s, ok := starlark.AsString(result)
+	if !ok {
+		return Action{}, fmt.Errorf("choose_move returned %s, want a move string", result.Type())
+	}
+	for _, m := range moves {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return Action{}, fmt.Errorf("choose_move returned illegal move %q", s)
+}
+
+// evaluate scores a move string for the bot's side: 100 for a win, -100 if
+// it completes the opponent's line or hands them a winning reply, otherwise
+// the difference in visible pieces.
+func (bot *scriptBot) evaluate(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var move string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &move); err != nil {
+		return nil, err
+	}
+	a, err := parseAction(move)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	return starlark.MakeInt(scoreMove(bot.board, a, bot.player)), nil
+}
+
+func scoreMove(b Board, a Action, player int) int {
+	opponent := 3 - player
+	after, err := position(b, player).Apply(a.move())
+	if err != nil {
+		return -100
+	}
+	switch after.Winner {
+	case player:
+		return 100
+	case opponent:
+		return -100
+	}
+	for _, reply := range engine.LegalMoves(after, opponent) {
+		if next, err := after.Apply(reply); err == nil && next.Winner == opponent {
+			return -100
+		}
+	}
+	score := 0
+	for i := range after.Board {
+		for j := range after.Board[i] {
+			if top, ok := after.Board[i][j].Top(); ok {
+				if top.Owner == player {
+					score++
+				} else {
+					score--
+				}
+			}
+		}
+	}
+	return score
+}
+
+func randint(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var n int
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &n); err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("%s: n must be positive", fn.Name())
+	}
+	return starlark.MakeInt(rand.Intn(n)), nil
+}
+
+// runBot joins a game and lets a Starlark script play one seat.
+func runBot(args []string) {
+	fs := flag.NewFlagSet("bot", flag.ExitOnError)
+	script := fs.String("script", "", "Starlark file defining choose_move(board, player, moves)")
+	game := fs.String("game", "", "ID of the game to join")
+	seat := fs.Int("player", 2, "seat the bot plays (1 or 2)")
+	pace := fs.Duration("pace", time.Second, "think time before each move")
+	fs.DurationVar(&batchWindow, "batch", 0, "collect publishes for this long and send them as one message (e.g. 50ms)")
+	health := fs.String("health", "", "serve /healthz on this address, e.g. :8090")
+	fs.Parse(args)
+
+	if *script == "" || !validGameID(*game) || (*seat != 1 && *seat != 2) {
+		fmt.Println("❌ Usage: bot --script <file.star> --game <ID> [--player 1|2]")
+		os.Exit(1)
+	}
+	bot, err := loadScriptBot(*script)
+	if err != nil {
+		fmt.Println("❌ Could not load bot script:", err)
+		os.Exit(1)
+	}
+
+	gameID = *game
+	playerID = *seat
+	connectMQTT()
+	if !loadGameState() {
+		fmt.Println("❌ No game found with ID", gameID)
+		os.Exit(1)
+	}
+	subscribeGame()
+	if holder, err := reserveSeats(playerID); errors.Is(err, errSeatTaken) {
+		fmt.Printf("❌ Seat %d is taken by %s\n", playerID, holderName(holder))
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Printf("❌ Could not reserve seat %d: %v\n", playerID, err)
+		os.Exit(1)
+	}
+	if *health != "" {
+		serveHealth(*health)
+	}
+	if gameMeta.FirstChoice == firstByCoinFlip && playerTurn == 0 {
+		runCoinFlip()
+	}
+	healthBeat()
+	daemonReady()
+	fmt.Printf("🤖 %s is playing game %s as Player %d\n", *script, gameID, playerID)
+
+	for {
+		healthBeat()
+		mu.Lock()
+		ready := playerTurn == playerID && !paused
+		b := board.Clone()
+		mu.Unlock()
+		if !ready {
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+
+		time.Sleep(*pace)
+		a, err := bot.chooseMove(b, playerID)
+		if err != nil {
+			fmt.Println("❌ Bot error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("🤖 Playing", notation.Format(a.move()))
+		if err := applyAction(a); err != nil {
+			fmt.Printf("❌ Bot move %s was rejected: %s\n", a, moveErrorText(err))
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Tournament is the retained definition of a single-elimination event on
+// gobblet/tournament/<id>. Only the first round lists players; later rounds
+// are filled in from the results of their games.
+type Tournament struct {
+	Name   string
+	Rounds [][]BracketMatch
+}
+
+// BracketMatch is one game in the bracket. Winner can be set by the
+// organizer; otherwise it is taken from the game's result.
+type BracketMatch struct {
+	GameID  string
+	Players [2]string `json:",omitempty"`
+	Winner  int       `json:",omitempty"`
+}
+
+const bracketNameWidth = 14
+
+func tournamentTopic(id string) string {
+	return "gobblet/tournament/" + id
+}
+
+// finalMatch is the match of the last round, which decides the
+// tournament. A bracket of a single round has no final.
+func (t Tournament) finalMatch() (BracketMatch, bool) {
+	if len(t.Rounds) < 2 || len(t.Rounds[len(t.Rounds)-1]) != 1 {
+		return BracketMatch{}, false
+	}
+	return t.Rounds[len(t.Rounds)-1][0], true
+}
+
+// fetchTournament reads the retained definition of tournament id.
+func fetchTournament(id string) (Tournament, error) {
+	found := make(chan Tournament, 1)
+	topic := tournamentTopic(id)
+	token := mqttClient.Subscribe(topic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		var t Tournament
+		if json.Unmarshal(msg.Payload(), &t) == nil {
+			select {
+			case found <- t:
+			default:
+			}
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		return Tournament{}, token.Error()
+	}
+	defer mqttClient.Unsubscribe(topic)
+	select {
+	case t := <-found:
+		return t, nil
+	case <-time.After(2 * time.Second):
+		return Tournament{}, fmt.Errorf("no tournament %s on the broker", id)
+	}
+}
+
+// bracketView keeps the latest tournament definition and game results.
+type bracketView struct {
+	mu         sync.Mutex
+	tournament Tournament
+	results    map[string]*Result // game ID -> result
+}
+
+// matchPlayers returns the names playing a match, resolving later rounds
+// from the winners of the round before.
+func (v *bracketView) matchPlayers(round, match int) [2]string {
+	if round == 0 {
+		return v.tournament.Rounds[0][match].Players
+	}
+	var players [2]string
+	for k := 0; k < 2; k++ {
+		players[k] = v.matchWinner(round-1, 2*match+k)
+	}
+	return players
+}
+
+// matchWinner returns the winner's name, or "?" while the match is open.
+func (v *bracketView) matchWinner(round, match int) string {
+	rounds := v.tournament.Rounds
+	if round >= len(rounds) || match >= len(rounds[round]) {
+		return "?"
+	}
+	m := rounds[round][match]
+	winner := m.Winner
+	if r := v.results[m.GameID]; winner == 0 && r != nil {
+		winner = r.Winner()
+	}
+	if winner == 0 {
+		return "?"
+	}
+	return v.matchPlayers(round, match)[winner-1]
+}
+
+func padName(name string) string {
+	if utf8.RuneCountInString(name) > bracketNameWidth {
+		name = string([]rune(name)[:bracketNameWidth-1]) + "…"
+	}
+	return name + strings.Repeat(" ", bracketNameWidth-utf8.RuneCountInString(name))
+}
+
+// render draws the bracket as ASCII art, one column per round.
+func (v *bracketView) render() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	clearScreen()
+	fmt.Printf("🏆 %s\n\n", v.tournament.Name)
+	rounds := v.tournament.Rounds
+	if len(rounds) == 0 {
+		fmt.Println("Waiting for the bracket to be published...")
+		return
+	}
+
+	for r := 1; r < len(rounds); r++ {
+		if len(rounds[r]) != len(rounds[r-1])/2 {
+			fmt.Printf("❌ Round %d should have %d matches\n", r+1, len(rounds[r-1])/2)
+			return
+		}
+	}
+
+	colWidth := bracketNameWidth + 4
+	height := 4 * len(rounds[0])
+	grid := make([][]rune, height)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", colWidth*(len(rounds)+1)))
+	}
+	put := func(row, col int, s string) {
+		for k, r := range []rune(s) {
+			grid[row][col+k] = r
+		}
+	}
+
+	// centers[m] is the row where the winner of match m is written
+	var centers []int
+	for r := range rounds {
+		x := r * colWidth
+		var next []int
+		for m := range rounds[r] {
+			top, bottom := 4*m, 4*m+2
+			if r > 0 {
+				top, bottom = centers[2*m], centers[2*m+1]
+			}
+			players := v.matchPlayers(r, m)
+			put(top, x, padName(players[0])+"─┐")
+			put(bottom, x, padName(players[1])+"─┘")
+			center := (top + bottom) / 2
+			for row := top + 1; row < bottom; row++ {
+				put(row, x+bracketNameWidth+1, "│")
+			}
+			put(center, x+bracketNameWidth+1, "├─ ")
+			next = append(next, center)
+		}
+		centers = next
+	}
+	put(centers[0], len(rounds)*colWidth, "🏆 "+v.matchWinner(len(rounds)-1, 0))
+
+	for _, line := range grid {
+		fmt.Println(strings.TrimRight(string(line), " "))
+	}
+}
+
+func (v *bracketView) onResult(client mqtt.Client, msg mqtt.Message) {
+	var state GameState
+	if decodeState(unwrapPayload(msg.Payload()), &state) != nil || state.Result == nil {
+		return
+	}
+	id := topicGameID(msg.Topic())
+	v.mu.Lock()
+	v.results[id] = state.Result
+	v.mu.Unlock()
+	v.render()
+}
+
+func (v *bracketView) onTournament(client mqtt.Client, msg mqtt.Message) {
+	var t Tournament
+	if err := json.Unmarshal(msg.Payload(), &t); err != nil {
+		fmt.Println("❌ Error decoding tournament:", err)
+		return
+	}
+	v.mu.Lock()
+	v.tournament = t
+	v.mu.Unlock()
+
+	for _, round := range t.Rounds {
+		for _, m := range round {
+			if m.GameID != "" {
+				client.Subscribe(gameTopic(m.GameID, channelState), 1, v.onResult)
+			}
+		}
+	}
+	v.render()
+}
+
+// runBracket shows a tournament bracket that updates as results arrive,
+// e.g. on a projector at an event. With --publish it first publishes a
+// bracket definition from a JSON file.
+func runBracket(args []string) {
+	fs := flag.NewFlagSet("bracket", flag.ExitOnError)
+	publish := fs.String("publish", "", "JSON file with the tournament definition to publish first")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: bracket [--publish tournament.json] <tournamentID>")
+		os.Exit(1)
+	}
+	id := fs.Arg(0)
+	connectMQTT()
+
+	if *publish != "" {
+		data, err := os.ReadFile(*publish)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		var t Tournament
+		if err := json.Unmarshal(data, &t); err != nil {
+			log.Fatal("❌ Invalid tournament file: ", err)
+		}
+		if len(t.Rounds) == 0 || len(t.Rounds[0]) != 1<<(len(t.Rounds)-1) {
+			log.Fatal("❌ The first round must have 2^(rounds-1) matches")
+		}
+		mqttClient.Publish(tournamentTopic(id), 1, true, data).Wait()
+		fmt.Println("📤 Published tournament", id)
+	}
+
+	v := &bracketView{tournament: Tournament{Name: "Tournament " + id}, results: map[string]*Result{}}
+	if token := mqttClient.Subscribe(tournamentTopic(id), 1, v.onTournament); token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error:", token.Error())
+	}
+	v.render()
+	select {}
+}
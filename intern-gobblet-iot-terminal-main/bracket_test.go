@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWonTournamentFinal(t *testing.T) {
+	b := startTestBroker(t)
+	c := connectV5(t, b)
+	saved, savedID, savedMeta, savedPlayer := mqttClient, gameID, gameMeta, playerID
+	t.Cleanup(func() { mqttClient, gameID, gameMeta, playerID = saved, savedID, savedMeta, savedPlayer })
+	mqttClient, playerID = c, 1
+
+	cup := Tournament{Name: "Cup", Rounds: [][]BracketMatch{
+		{{GameID: "semi-1", Players: [2]string{"alice", "bob"}}, {GameID: "semi-2", Players: [2]string{"carol", "dan"}}},
+		{{GameID: "final"}},
+	}}
+	data, _ := json.Marshal(cup)
+	c.Publish(tournamentTopic("cup"), 1, true, data).Wait()
+	awarded, _ := json.Marshal(Tournament{Rounds: [][]BracketMatch{{{GameID: "a"}, {GameID: "b"}}, {{GameID: "final", Winner: 2}}}})
+	c.Publish(tournamentTopic("awarded"), 1, true, awarded).Wait()
+	single, _ := json.Marshal(Tournament{Rounds: [][]BracketMatch{{{GameID: "final"}}}})
+	c.Publish(tournamentTopic("single"), 1, true, single).Wait()
+
+	for _, tc := range []struct {
+		tournament, game string
+		want             bool
+	}{
+		{"cup", "final", true},
+		{"cup", "semi-1", false},
+		{"", "final", false},
+		{"awarded", "final", false},
+		{"single", "final", false},
+	} {
+		gameID, gameMeta = tc.game, GameMeta{Tournament: tc.tournament, Tags: []string{"final"}}
+		if got := wonTournamentFinal(); got != tc.want {
+			t.Errorf("winning %s in tournament %q: %v, want %v", tc.game, tc.tournament, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Package bridge lets programs outside this codebase play as the computer
+// opponent. It speaks a line-based text protocol modelled on chess's UCI
+// over the program's stdin and stdout:
+//
+//	→ uci                      start; the engine introduces itself
+//	← id name <name>           (optional)
+//	← uciok
+//	→ isready                  wait until the engine has settled
+//	← readyok
+//	→ ucinewgame               a new game begins
+//	→ position <position>      the position to search, see engine.EncodePosition
+//	→ go movetime <ms>         search for at most ms milliseconds, or
+//	→ go depth <plies>         search this many plies ahead
+//	← bestmove <move>          the chosen move in algebraic notation, e.g. "P b2 L"
+//	→ stop                     the search ran out of time: answer with bestmove now
+//	→ quit                     the engine should exit
+//
+// Lines the engine writes that the bridge doesn't expect, such as "info"
+// lines, are ignored. House rules are not sent: engines play by the
+// variant's standard rules, and a move they break is reported as an error.
+package bridge
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"goblets/ai"
+	"goblets/engine"
+	"goblets/notation"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// grace is how long past its move time an engine may take to answer.
+var grace = 5 * time.Second
+
+var (
+	// ErrTimeout is returned when the engine doesn't answer in time.
+	ErrTimeout = errors.New("engine did not answer in time")
+	// ErrNoMove is returned when the engine answers "bestmove none".
+	ErrNoMove = errors.New("engine has no move")
+)
+
+// Engine is a running external engine.
+type Engine struct {
+	Name  string
+	cmd   *exec.Cmd
+	in    io.WriteCloser
+	lines chan string
+	late  int // searches stopped after timing out, whose bestmove is still to come
+}
+
+// Start runs command (a program followed by its arguments) and completes
+// the handshake.
+func Start(command string) (*Engine, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("no engine command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	e := &Engine{Name: args[0], cmd: cmd, in: in, lines: make(chan string, 64)}
+	go func() {
+		sc := bufio.NewScanner(out)
+		for sc.Scan() {
+			e.lines <- strings.TrimSpace(sc.Text())
+		}
+		close(e.lines)
+	}()
+
+	if err := e.send("uci"); err != nil {
+		e.Close()
+		return nil, err
+	}
+	for {
+		line, err := e.expect(grace)
+		if err != nil {
+			e.Close()
+			return nil, fmt.Errorf("handshake: %w", err)
+		}
+		if name, ok := strings.CutPrefix(line, "id name "); ok {
+			e.Name = name
+		}
+		if line == "uciok" {
+			return e, nil
+		}
+	}
+}
+
+func (e *Engine) send(format string, args ...any) error {
+	_, err := fmt.Fprintf(e.in, format+"\n", args...)
+	return err
+}
+
+// expect returns the engine's next line, waiting at most timeout.
+func (e *Engine) expect(timeout time.Duration) (string, error) {
+	select {
+	case line, ok := <-e.lines:
+		if !ok {
+			return "", errors.New("engine exited")
+		}
+		return line, nil
+	case <-time.After(timeout):
+		return "", ErrTimeout
+	}
+}
+
+// settle reads past the bestmove of every search stopped after timing out,
+// so a late answer isn't taken for the answer to the next search.
+func (e *Engine) settle() error {
+	for e.late > 0 {
+		line, err := e.expect(grace)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "bestmove ") {
+			e.late--
+		}
+	}
+	return nil
+}
+
+// NewGame tells the engine a new game begins and waits until it is ready.
+func (e *Engine) NewGame() error {
+	if err := e.settle(); err != nil {
+		return err
+	}
+	if err := e.send("ucinewgame"); err != nil {
+		return err
+	}
+	if err := e.send("isready"); err != nil {
+		return err
+	}
+	for {
+		line, err := e.expect(grace)
+		if err != nil || line == "readyok" {
+			return err
+		}
+	}
+}
+
+// BestMove asks the engine for its move in s, searching for at most
+// movetime. The move is checked against the rules of s.
+func (e *Engine) BestMove(s engine.State, movetime time.Duration) (engine.Move, error) {
+	if err := e.settle(); err != nil {
+		return engine.Move{}, err
+	}
+	if err := e.send("position %s", engine.EncodePosition(s)); err != nil {
+		return engine.Move{}, err
+	}
+	if err := e.send("go movetime %d", movetime.Milliseconds()); err != nil {
+		return engine.Move{}, err
+	}
+	deadline := time.Now().Add(movetime + grace)
+	for {
+		line, err := e.expect(time.Until(deadline))
+		if err == ErrTimeout {
+			e.send("stop")
+			e.late++
+		}
+		if err != nil {
+			return engine.Move{}, err
+		}
+		text, ok := strings.CutPrefix(line, "bestmove ")
+		if !ok {
+			continue
+		}
+		if text == "none" {
+			return engine.Move{}, ErrNoMove
+		}
+		m, err := notation.Parse(text)
+		if err != nil {
+			return engine.Move{}, fmt.Errorf("bestmove %q: %w", text, err)
+		}
+		if _, err := s.Apply(m); err != nil {
+			return engine.Move{}, fmt.Errorf("bestmove %q: %w", text, err)
+		}
+		return m, nil
+	}
+}
+
+// Close asks the engine to quit and kills it if it doesn't.
+func (e *Engine) Close() error {
+	e.send("quit")
+	e.in.Close()
+	done := make(chan error, 1)
+	go func() { done <- e.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(grace):
+		e.cmd.Process.Kill()
+		return <-done
+	}
+}
+
+// Serve speaks the protocol on r and w with the built-in AI, so it can be
+// run as an external engine itself and engine authors have a reference.
+func Serve(r io.Reader, w io.Writer, name string, l ai.Limits) error {
+	sc := bufio.NewScanner(r)
+	var s engine.State
+	for sc.Scan() {
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(sc.Text()), " ")
+		switch cmd {
+		case "uci":
+			fmt.Fprintf(w, "id name %s\nuciok\n", name)
+		case "isready":
+			fmt.Fprintln(w, "readyok")
+		case "position":
+			pos, err := engine.DecodePosition(arg)
+			if err != nil {
+				fmt.Fprintln(w, "info string bad position:", err)
+				continue
+			}
+			s = pos
+		case "go":
+			limits := l
+			what, value, _ := strings.Cut(arg, " ")
+			n, _ := strconv.Atoi(value)
+			switch {
+			case what == "movetime" && n > 0:
+				limits.Time = time.Duration(n) * time.Millisecond
+			case what == "depth" && n > 0:
+				limits.Depth, limits.Time = n, 0
+			}
+			m, ok := ai.Choose(s, limits)
+			if !ok {
+				fmt.Fprintln(w, "bestmove none")
+				continue
+			}
+			fmt.Fprintln(w, "bestmove", notation.Format(m))
+		case "quit":
+			return nil
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,77 @@
+package bridge
+
+import (
+	"bufio"
+	"fmt"
+	"goblets/ai"
+	"goblets/engine"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain runs the test binary as a slow engine when asked to: it only
+// answers its first search once told to stop, and the later ones at once.
+func TestMain(m *testing.M) {
+	if os.Getenv("BRIDGE_TEST_ENGINE") == "slow" {
+		searches := 0
+		sc := bufio.NewScanner(os.Stdin)
+		for sc.Scan() {
+			switch cmd, _, _ := strings.Cut(sc.Text(), " "); cmd {
+			case "uci":
+				fmt.Println("id name slow\nuciok")
+			case "isready":
+				fmt.Println("readyok")
+			case "go":
+				searches++
+				if searches > 1 {
+					fmt.Println("bestmove P b2 L")
+				}
+			case "stop":
+				fmt.Println("bestmove P a1 S")
+			case "quit":
+				os.Exit(0)
+			}
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestLateBestMoveIsDiscarded(t *testing.T) {
+	old := grace
+	t.Cleanup(func() { grace = old })
+	grace = 100 * time.Millisecond
+	t.Setenv("BRIDGE_TEST_ENGINE", "slow")
+
+	e, err := Start(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	if e.Name != "slow" {
+		t.Errorf("engine name %q", e.Name)
+	}
+
+	s := engine.State{Board: engine.NewBoard(3), Turn: 1}
+	if _, err := e.BestMove(s, 10*time.Millisecond); err != ErrTimeout {
+		t.Fatalf("first search: %v, want %v", err, ErrTimeout)
+	}
+	m, err := e.BestMove(s, 10*time.Millisecond)
+	if err != nil || m != engine.Place(1, 1, 3) {
+		t.Errorf("second search: %+v, %v; want the second answer, not the late first one", m, err)
+	}
+}
+
+func TestServe(t *testing.T) {
+	in := strings.NewReader("uci\nisready\nposition .,.,./.,.,./.,.,. 1\ngo depth 1\nquit\n")
+	var out strings.Builder
+	if err := Serve(in, &out, "ref", ai.Limits{Depth: 1}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || lines[0] != "id name ref" || lines[1] != "uciok" || lines[2] != "readyok" || !strings.HasPrefix(lines[3], "bestmove P ") {
+		t.Errorf("Serve wrote %q", lines)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	reportTail    = 1 << 20 // most bytes taken from the end of each log
+	reportLogs    = 3       // newest audit logs included
+	redactedValue = "REDACTED"
+)
+
+// secretKeys are config keys whose values never leave the machine.
+var secretKeys = []string{"token", "passphrase", "auth_header", "salt", "password", "secret"}
+
+// redactConfig returns settings with every secret value replaced.
+func redactConfig(settings map[string]any) map[string]any {
+	out := map[string]any{}
+	for k, v := range settings {
+		switch v := v.(type) {
+		case map[string]any:
+			out[k] = redactConfig(v)
+		default:
+			out[k] = v
+			if s, ok := v.(string); ok && s != "" && slices.ContainsFunc(secretKeys, func(key string) bool { return strings.Contains(k, key) }) {
+				out[k] = redactedValue
+			}
+		}
+	}
+	return out
+}
+
+// BuildInfo is the version section of a bug report.
+type BuildInfo struct {
+	Client   string
+	Protocol int
+	Go       string
+	Platform string
+	Module   string            `json:",omitempty"`
+	Settings map[string]string `json:",omitempty"` // vcs revision, build flags
+}
+
+func buildInfo() BuildInfo {
+	b := BuildInfo{Client: clientVersion, Protocol: protocolVersion, Go: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		b.Module = info.Main.Path + " " + info.Main.Version
+		b.Settings = map[string]string{}
+		for _, s := range info.Settings {
+			b.Settings[s.Key] = s.Value
+		}
+	}
+	return b
+}
+
+// readTail returns up to the last reportTail bytes of path, starting at a
+// line boundary when it had to cut.
+func readTail(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) <= reportTail {
+		return data, err
+	}
+	data = data[len(data)-reportTail:]
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return data, nil
+}
+
+// newestFiles returns the paths of the n most recently modified files in dir.
+func newestFiles(dir string, n int) []string {
+	entries, _ := os.ReadDir(dir)
+	type file struct {
+		path string
+		mod  time.Time
+	}
+	var files []file
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && info.Mode().IsRegular() {
+			files = append(files, file{filepath.Join(dir, e.Name()), info.ModTime()})
+		}
+	}
+	slices.SortFunc(files, func(a, b file) int { return b.mod.Compare(a.mod) })
+	var paths []string
+	for _, f := range files[:min(n, len(files))] {
+		paths = append(paths, f.path)
+	}
+	return paths
+}
+
+// runReportBug bundles what's needed to look into a problem into one
+// tar.gz: version info, the config without secrets, the MQTT trace if one
+// is recorded, the newest audit logs and the latest session snapshot.
+func runReportBug(args []string) {
+	fs := flag.NewFlagSet("report-bug", flag.ExitOnError)
+	out := fs.String("out", "gobblet-bug-"+time.Now().Format("20060102-150405")+".tar.gz", "file to write the bundle to")
+	fs.Parse(args)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	var contents []string
+	add := func(name string, data []byte) {
+		hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: time.Now()}
+		if tw.WriteHeader(hdr) == nil {
+			tw.Write(data)
+			contents = append(contents, name)
+		}
+	}
+	addFile := func(name, path string) {
+		data, err := readTail(path)
+		if err != nil {
+			fmt.Printf("⚠ Skipping %s: %v\n", path, err)
+			return
+		}
+		add(name, data)
+	}
+
+	data, _ := json.MarshalIndent(buildInfo(), "", "  ")
+	add("version.json", data)
+	data, _ = json.MarshalIndent(redactConfig(viper.AllSettings()), "", "  ")
+	add("config.json", data)
+	if trace := viper.GetString("trace_file"); trace != "" {
+		addFile("trace.jsonl", trace)
+	}
+	for _, path := range newestFiles(filepath.Join(gobbletDir(), "audit"), reportLogs) {
+		addFile("logs/"+filepath.Base(path), path)
+	}
+	if snaps := newestFiles(filepath.Join(gobbletDir(), "snapshots"), 1); len(snaps) > 0 {
+		addFile("snapshot/"+filepath.Base(snaps[0]), snaps[0])
+	}
+
+	if err := tw.Close(); err != nil {
+		fmt.Println("❌ Could not build the bug report:", err)
+		os.Exit(1)
+	}
+	zw.Close()
+	if err := os.WriteFile(*out, buf.Bytes(), 0o600); err != nil {
+		fmt.Println("❌ Could not write the bug report:", err)
+		os.Exit(1)
+	}
+	fmt.Println("🐞 Wrote bug report", *out)
+	for _, name := range contents {
+		fmt.Println("  ", name)
+	}
+	fmt.Println("Secrets in the config are redacted; check the logs for anything you'd rather not share before attaching it.")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goblets/config"
+	"os"
+	"strings"
+	"time"
+)
+
+// certPrefix marks a result certificate string and its format version.
+const certPrefix = "GGC1."
+
+// ResultCertificate is a signed, shareable proof of a finished game, e.g.
+// for validating tournament prizes. It travels as a single string that fits
+// in a QR code.
+type ResultCertificate struct {
+	GameID       string
+	Title        string           `json:",omitempty"`
+	Players      []PlayerIdentity `json:",omitempty"`
+	Seat         int
+	Name         string `json:",omitempty"`
+	Result       Result
+	Moves        int
+	PositionHash string
+	Issued       time.Time
+	PublicKey    []byte
+	Signature    []byte `json:",omitempty"`
+}
+
+// positionHash fingerprints a final position.
+func positionHash(b Board) string {
+	data, _ := json.Marshal(b)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// signedBytes is the canonical encoding covered by the signature.
+func (c ResultCertificate) signedBytes() []byte {
+	c.Signature = nil
+	data, _ := json.Marshal(c)
+	return data
+}
+
+// Verify checks the certificate signature against the embedded public key.
+func (c ResultCertificate) Verify() bool {
+	if len(c.PublicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(c.PublicKey, c.signedBytes(), c.Signature)
+}
+
+// issueCertificate signs the local player's view of a finished game.
+func issueCertificate(result *Result) (string, error) {
+	key, err := loadSigningKey()
+	if err != nil {
+		return "", err
+	}
+	cert := ResultCertificate{
+		GameID:       gameID,
+		Title:        gameMeta.Title,
+		Players:      gameMeta.Players,
+		Seat:         playerID,
+		Name:         publicName(config.Conf.PlayerName),
+		Result:       *result,
+		Moves:        moveCount,
+		PositionHash: positionHash(board),
+		Issued:       time.Now().UTC(),
+		PublicKey:    key.Public().(ed25519.PublicKey),
+	}
+	cert.Signature = ed25519.Sign(key, cert.signedBytes())
+	data, _ := json.Marshal(cert)
+	return certPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCertificate(s string) (ResultCertificate, error) {
+	var cert ResultCertificate
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(s), certPrefix)
+	if !ok {
+		return cert, errors.New("not a result certificate")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cert, fmt.Errorf("corrupt certificate: %w", err)
+	}
+	if err := json.Unmarshal(data, &cert); err != nil {
+		return cert, fmt.Errorf("corrupt certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// runVerifyCert checks a result certificate and shows what it attests.
+func runVerifyCert(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: verify-cert <certificate>")
+		os.Exit(1)
+	}
+	cert, err := decodeCertificate(args[0])
+	if err != nil {
+		fmt.Println("❌", err)
+		os.Exit(1)
+	}
+	if !cert.Verify() {
+		fmt.Println("❌ Invalid signature: this certificate was altered or forged")
+		os.Exit(1)
+	}
+
+	signer := base64.StdEncoding.EncodeToString(cert.PublicKey)
+	fmt.Println("✅ Valid result certificate")
+	fmt.Printf("   Game:     %s %s\n", cert.GameID, cert.Title)
+	fmt.Printf("   Result:   %s\n", cert.Result.String())
+	fmt.Printf("   Moves:    %d\n", cert.Moves)
+	fmt.Printf("   Issued:   %s by Player %d %s\n", cert.Issued.Format(time.RFC3339), cert.Seat, cert.Name)
+	for _, p := range cert.Players {
+		fmt.Printf("   Seat %d:   %s (%s)\n", p.Seat, p.Name, p.ID)
+	}
+	fmt.Printf("   Position: %s\n", cert.PositionHash)
+
+	keys, err := readTrustedKeys()
+	if err != nil {
+		fmt.Println("⚠ Could not read trusted keys:", err)
+	}
+	for _, k := range keys {
+		if k.PublicKey == signer {
+			fmt.Printf("   Signer:   %s (trusted)\n", k.Name)
+			return
+		}
+	}
+	fmt.Printf("   Signer:   %s… (not in your trusted keys)\n", signer[:8])
+}
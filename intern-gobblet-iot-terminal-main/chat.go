@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// chatCommand starts a chat line at the prompt. The input reader takes chat
+// lines out before the game loop sees them, so they never reach move parsing.
+const chatCommand = "/say"
+
+// chatInput returns the text of a chat line typed at the prompt.
+func chatInput(line string) (text string, ok bool) {
+	line = strings.TrimSpace(line)
+	rest, found := strings.CutPrefix(line, chatCommand)
+	if !found || rest != "" && rest[0] != ' ' {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// chatLabel names the sender of m with their role.
+func chatLabel(m ChatMessage) string {
+	role := fmt.Sprintf("Player %d", m.Player)
+	if m.Role == "spectator" || m.Role == "referee" {
+		role = m.Role
+	}
+	if m.Name == "" {
+		return role
+	}
+	return fmt.Sprintf("%s (%s)", m.Name, role)
+}
+
+// sendChat publishes text on the game's chat channel.
+func sendChat(text string) {
+	switch {
+	case !chatAllowed():
+		fmt.Println("❌ Chat is off in this organization.")
+		return
+	case text == "":
+		fmt.Println("❌ Usage: /say <message>")
+		return
+	}
+	m := ChatMessage{Player: playerID, Name: config.Conf.PlayerName, Role: seatRole(playerID), Session: clientID, Text: text, Time: time.Now().UTC()}
+	chatLines = append(chatLines, "🗨 "+chatLabel(m)+": "+m.Text)
+	data, _ := json.Marshal(m)
+	if token := mqttClient.Publish(chatTopic(), 1, false, data); token.Wait() && token.Error() != nil {
+		fmt.Println("❌ Could not send the message:", token.Error())
+	}
+}
+
+// onChat shows a chat line from another session above the board.
+func onChat(client mqtt.Client, msg mqtt.Message) {
+	var m ChatMessage
+	if json.Unmarshal(msg.Payload(), &m) != nil || m.Text == "" || m.Session == clientID || !chatAllowed() {
+		return
+	}
+	line := chatLabel(m) + ": " + m.Text
+	chatLines = append(chatLines, "💬 "+line)
+	fmt.Println("\n💬", line)
+	redrawScreen()
+}
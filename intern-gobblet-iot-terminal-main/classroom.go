@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"goblets/config"
+	"goblets/engine"
+	"maps"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// classroomMode reports whether an organization is configured.
+func classroomMode() bool {
+	return config.Conf.Organization.Name != ""
+}
+
+// chatAllowed reports whether chat is on; organizations can turn it off,
+// and the goblet-referee turns it off in their refereed games.
+func chatAllowed() bool {
+	return !chatOff && (!classroomMode() || config.Conf.Organization.Chat)
+}
+
+// presetAllowed reports whether the organization lets games use preset.
+func presetAllowed(name string) bool {
+	allowed := config.Conf.Organization.AllowedPresets
+	return len(allowed) == 0 || slices.Contains(allowed, name)
+}
+
+// rosterStudent finds the student with the given ID or name.
+func rosterStudent(idOrName string) (config.Student, bool) {
+	for _, s := range config.Conf.Organization.Roster {
+		if s.ID == idOrName || strings.EqualFold(s.Name, idOrName) {
+			return s, true
+		}
+	}
+	return config.Student{}, false
+}
+
+// checkRoster refuses to seat a player who isn't on the roster.
+func checkRoster() error {
+	if !classroomMode() || len(config.Conf.Organization.Roster) == 0 {
+		return nil
+	}
+	if _, ok := rosterStudent(config.Conf.PlayerName); !ok {
+		return fmt.Errorf("%q is not on the roster of %s; set player_name to your name on the roster", config.Conf.PlayerName, config.Conf.Organization.Name)
+	}
+	return nil
+}
+
+// checkRosterPlayers refuses players from outside the roster in games
+// created through the API.
+func checkRosterPlayers(players []PlayerIdentity) error {
+	if !classroomMode() || len(config.Conf.Organization.Roster) == 0 {
+		return nil
+	}
+	for _, p := range players {
+		if _, ok := rosterStudent(p.ID); !ok {
+			return fmt.Errorf("player %q is not on the roster of %s", p.ID, config.Conf.Organization.Name)
+		}
+	}
+	return nil
+}
+
+// watchSessionLimit ends the game as a draw when the organization's session
+// time runs out, with a warning five minutes before.
+func watchSessionLimit() {
+	minutes := config.Conf.Organization.SessionMinutes
+	if !classroomMode() || minutes <= 0 {
+		return
+	}
+	limit := time.Duration(minutes) * time.Minute
+	go func() {
+		if limit > 5*time.Minute {
+			time.Sleep(limit - 5*time.Minute)
+			fmt.Println("\n⏰ 5 minutes of session time left")
+			limit = 5 * time.Minute
+		}
+		time.Sleep(limit)
+		mu.Lock()
+		// The referee ends refereed games when it enforces the session
+		over := gameResult == nil && !gameMeta.Refereed
+		var state GameState
+		if over {
+			gameResult = newDrawResult(TerminationSessionTime)
+			state = nextState()
+		}
+		mu.Unlock()
+		if over {
+			sendState(state)
+			fmt.Println("\n⏰ Session time is up")
+			finishGame(state.Result)
+		}
+	}()
+}
+
+// ClassroomRound is a set of games started together for the roster, kept
+// in ~/.gobblet/classroom so the teacher can collect the results.
+type ClassroomRound struct {
+	Organization string
+	Started      time.Time
+	Preset       string
+	Games        []ClassroomGame
+	Bye          *config.Student `json:",omitempty"` // left out of an odd-sized roster
+}
+
+// ClassroomGame is one pairing of a round.
+type ClassroomGame struct {
+	GameID  string
+	Players [2]config.Student // seat 1, seat 2
+}
+
+func classroomDir() string {
+	return filepath.Join(gobbletDir(), "classroom")
+}
+
+// runClassroom is the teacher's CLI: start a round of games for the whole
+// roster, or report on a round.
+func runClassroom(args []string) {
+	if !classroomMode() {
+		fmt.Println("❌ Set organization.name and organization.roster in config.yaml first")
+		os.Exit(1)
+	}
+	if len(args) > 0 {
+		switch args[0] {
+		case "start":
+			runClassroomStart(args[1:])
+			return
+		case "report":
+			runClassroomReport(args[1:])
+			return
+		}
+	}
+	fmt.Println("Usage: classroom start [--preset NAME] [--variant junior|classic] [--shuffle]")
+	fmt.Println("       classroom report [--csv FILE] [round.json]")
+	os.Exit(1)
+}
+
+// runClassroomStart pairs up the roster and creates a game for each pair.
+func runClassroomStart(args []string) {
+	fs := flag.NewFlagSet("classroom start", flag.ExitOnError)
+	presetName := fs.String("preset", defaultPreset, "rule preset for every game")
+	variantName := fs.String("variant", "junior", "junior or classic")
+	shuffle := fs.Bool("shuffle", false, "pair students at random instead of in roster order")
+	fs.Parse(args)
+
+	org := config.Conf.Organization
+	v := engine.Variant(*variantName)
+	if !v.Valid() {
+		fmt.Println("❌ Unknown variant", *variantName)
+		os.Exit(1)
+	}
+	preset, err := resolvePreset(*presetName, v.Rules().Size)
+	if err != nil {
+		fmt.Println("❌", err)
+		os.Exit(1)
+	}
+	students := slices.Clone(org.Roster)
+	if len(students) < 2 {
+		fmt.Println("❌ The roster needs at least two students")
+		os.Exit(1)
+	}
+	if *shuffle {
+		rand.Shuffle(len(students), func(i, j int) { students[i], students[j] = students[j], students[i] })
+	}
+
+	connectMQTT()
+	round := ClassroomRound{Organization: org.Name, Started: time.Now().UTC(), Preset: preset.Name}
+	if len(students)%2 == 1 {
+		bye := students[len(students)-1]
+		round.Bye = &bye
+		students = students[:len(students)-1]
+	}
+	for i := 0; i < len(students); i += 2 {
+		id, err := freshGameID()
+		if err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
+		}
+		g := ClassroomGame{GameID: id, Players: [2]config.Student{students[i], students[i+1]}}
+		state := GameState{
+			Board:      engine.NewBoard(v.Rules().Size),
+			Variant:    v,
+			PlayerTurn: 1,
+			Meta: GameMeta{
+				Preset: preset.Name,
+				Rules:  preset.Rules,
+				Title:  org.Name,
+				Tags:   []string{"classroom"},
+				Players: []PlayerIdentity{
+					{Seat: 1, ID: g.Players[0].ID, Name: g.Players[0].Name},
+					{Seat: 2, ID: g.Players[1].ID, Name: g.Players[1].Name},
+				},
+			},
+			Clocks: newClocks(preset.Rules),
+		}
+		// With a referee the organization's rules hold for every student
+		state.Meta.Refereed = config.Conf.RefereeKey != ""
+		if err := publishNewGame(g.GameID, state); err != nil {
+			fmt.Printf("❌ Could not create the game for %s and %s: %v\n", g.Players[0].Name, g.Players[1].Name, err)
+			continue
+		}
+		round.Games = append(round.Games, g)
+		fmt.Printf("🆕 Game %s: %s (Player 1) vs %s (Player 2)\n", g.GameID, g.Players[0].Name, g.Players[1].Name)
+	}
+	if round.Bye != nil {
+		fmt.Printf("💤 %s sits this round out\n", round.Bye.Name)
+	}
+
+	path := filepath.Join(classroomDir(), "round-"+round.Started.Format("20060102-150405")+".json")
+	data, _ := json.MarshalIndent(round, "", "  ")
+	if err := os.MkdirAll(classroomDir(), 0o700); err == nil {
+		err = os.WriteFile(path, data, 0o600)
+	}
+	if err != nil {
+		fmt.Println("❌ Could not save the round:", err)
+		os.Exit(1)
+	}
+	fmt.Println("📋 Saved the round to", path)
+}
+
+// latestRound returns the most recently started round file.
+func latestRound() (string, error) {
+	files, err := filepath.Glob(filepath.Join(classroomDir(), "round-*.json"))
+	if err != nil || len(files) == 0 {
+		return "", fmt.Errorf("no rounds in %s; start one with classroom start", classroomDir())
+	}
+	slices.Sort(files)
+	return files[len(files)-1], nil
+}
+
+// fetchGameStates reads the retained state of each game, waiting up to
+// timeout for them. Games without a state are left out.
+func fetchGameStates(ids []string, timeout time.Duration) map[string]GameState {
+	var statesMu sync.Mutex
+	states := map[string]GameState{}
+	for _, id := range ids {
+		token := mqttClient.Subscribe(gameTopic(id, channelState), 1, func(client mqtt.Client, msg mqtt.Message) {
+			var state GameState
+			if decodeState(unwrapPayload(msg.Payload()), &state) != nil {
+				return
+			}
+			statesMu.Lock()
+			states[id] = state
+			statesMu.Unlock()
+		})
+		if token.Wait() && token.Error() != nil {
+			fmt.Printf("⚠ Could not read game %s: %v\n", id, token.Error())
+		}
+	}
+	time.Sleep(timeout)
+	statesMu.Lock()
+	defer statesMu.Unlock()
+	return maps.Clone(states)
+}
+
+// runClassroomReport collects the results of a round's games.
+func runClassroomReport(args []string) {
+	fs := flag.NewFlagSet("classroom report", flag.ExitOnError)
+	csvPath := fs.String("csv", "", "also write the games to this CSV file")
+	fs.Parse(args)
+
+	path := fs.Arg(0)
+	if path == "" {
+		var err error
+		if path, err = latestRound(); err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Println("❌ Could not read the round:", err)
+		os.Exit(1)
+	}
+	var round ClassroomRound
+	if err := json.Unmarshal(data, &round); err != nil {
+		fmt.Println("❌ Not a classroom round:", err)
+		os.Exit(1)
+	}
+
+	connectMQTT()
+	ids := make([]string, len(round.Games))
+	for i, g := range round.Games {
+		ids[i] = g.GameID
+	}
+	states := fetchGameStates(ids, 3*time.Second)
+
+	points := map[string]float64{}
+	rows := [][]string{{"game", "player 1", "player 2", "status", "moves"}}
+	fmt.Printf("📊 %s, round started %s (%s)\n", round.Organization, round.Started.Local().Format("2006-01-02 15:04"), round.Preset)
+	for _, g := range round.Games {
+		state, ok := states[g.GameID]
+		status := "not found"
+		switch {
+		case !ok:
+		case state.Result != nil:
+			status = state.Result.String()
+			if state.Result.IsDraw() {
+				points[g.Players[0].Name] += 0.5
+				points[g.Players[1].Name] += 0.5
+			} else {
+				points[g.Players[state.Result.Winner()-1].Name]++
+			}
+		case state.Moves == 0:
+			status = "not started"
+		default:
+			status = "in progress"
+		}
+		fmt.Printf("  %s  %-12s vs %-12s  %s after %d moves\n", g.GameID, g.Players[0].Name, g.Players[1].Name, status, state.Moves)
+		rows = append(rows, []string{g.GameID, g.Players[0].Name, g.Players[1].Name, status, fmt.Sprint(state.Moves)})
+	}
+
+	fmt.Println("🏆 Points (win 1, draw ½)")
+	for _, s := range config.Conf.Organization.Roster {
+		fmt.Printf("  %-12s %g\n", s.Name, points[s.Name])
+	}
+
+	if *csvPath != "" {
+		f, err := os.Create(*csvPath)
+		if err != nil {
+			fmt.Println("❌ Could not write the CSV:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w := csv.NewWriter(f)
+		w.WriteAll(rows)
+		fmt.Println("📄 Wrote", *csvPath)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"goblets/config"
+	"time"
+)
+
+// Clocks is the clock snapshot published with every state so both players
+// and spectators show the same times. Each terminal charges the clocks
+// itself, from when moves reach it: a peer's TurnStart is on that peer's
+// wall clock, so only whether it is set is read.
+type Clocks struct {
+	Remaining [2]time.Duration // player 1, player 2
+	TurnStart time.Time        // when the player to move started thinking, zero before the first move
+}
+
+const colorLowTime = "\033[5;1;31m" // blinking bold red
+
+// clockSlack is how far a peer's clocks may drift from ours, from network
+// delay, before we report them.
+const clockSlack = 5 * time.Second
+
+var clocks *Clocks // nil when the game has no time control
+
+// newClocks starts both clocks at the preset's time control.
+func newClocks(rules Rules) *Clocks {
+	if rules.TimeControl <= 0 {
+		return nil
+	}
+	total := time.Duration(rules.TimeControl) * time.Second
+	return &Clocks{Remaining: [2]time.Duration{total, total}}
+}
+
+// left is the live time remaining for player.
+func (c *Clocks) left(player int, toMove int, paused bool) time.Duration {
+	d := c.Remaining[player-1]
+	if player == toMove && !paused && !c.TurnStart.IsZero() {
+		d -= time.Since(c.TurnStart)
+	}
+	return max(d, 0)
+}
+
+// charge stops player's clock at the end of their move and starts the
+// opponent's. Like over the board, clocks start after the first move.
+func (c *Clocks) charge(player int) {
+	now := time.Now().UTC()
+	if !c.TurnStart.IsZero() {
+		// The opponent's move reached us one network trip late; don't bill it
+		c.Remaining[player-1] -= max(now.Sub(c.TurnStart)-latencyCredit(), 0)
+	}
+	c.TurnStart = now
+}
+
+// receivedClocks takes the clocks of a game we load or join. The remaining
+// times are taken as sent, within the time control, and a running clock
+// restarts from now.
+func receivedClocks(c *Clocks, rules Rules) *Clocks {
+	if c == nil {
+		return nil
+	}
+	local := &Clocks{Remaining: c.Remaining}
+	for i, d := range local.Remaining {
+		local.Remaining[i] = max(d, 0)
+		if rules.TimeControl > 0 {
+			local.Remaining[i] = min(local.Remaining[i], time.Duration(rules.TimeControl)*time.Second)
+		}
+	}
+	if !c.TurnStart.IsZero() {
+		local.TurnStart = time.Now().UTC()
+	}
+	return local
+}
+
+// check compares a peer's clocks with ours. Time handed back or taken away
+// beyond network delay is reported; our clocks stand either way.
+func (c *Clocks) check(peer *Clocks) error {
+	for i := range c.Remaining {
+		switch diff := peer.Remaining[i] - c.Remaining[i]; {
+		case diff > clockSlack:
+			return fmt.Errorf("Player %d's clock went back %s", i+1, formatClock(diff))
+		case diff < -clockSlack:
+			return fmt.Errorf("Player %d's clock jumped %s ahead", i+1, formatClock(-diff))
+		}
+	}
+	return nil
+}
+
+// adoptClocks updates our clocks for a received state: the player who was
+// to move is charged when the state holds a new move.
+func adoptClocks(peer *Clocks, moved bool, mover int) {
+	if clocks == nil {
+		clocks = receivedClocks(peer, gameMeta.Rules)
+		return
+	}
+	if moved && (mover == 1 || mover == 2) {
+		clocks.charge(mover)
+	}
+	if peer != nil {
+		if err := clocks.check(peer); err != nil {
+			fmt.Println("⚠ Kept our own clocks:", err)
+		}
+	}
+}
+
+// setPaused freezes the running clock for a referee pause and restarts it
+// on resume.
+func (c *Clocks) setPaused(player int, pause bool) {
+	if c.TurnStart.IsZero() {
+		return
+	}
+	if pause {
+		c.charge(player)
+	} else {
+		c.TurnStart = time.Now().UTC()
+	}
+}
+
+func lowTime(d time.Duration) bool {
+	return d < time.Duration(config.Conf.Clock.LowTimeWarning)*time.Second
+}
+
+func formatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// clockLine renders both clocks, flashing any that are low on time.
+func clockLine() string {
+	line := "⏱"
+	for player := 1; player <= 2; player++ {
+		d := clocks.left(player, playerTurn, paused)
+		text := fmt.Sprintf("P%d %s", player, formatClock(d))
+		if player == playerTurn {
+			text += " ◀"
+		}
+		if lowTime(d) {
+			text = colorLowTime + text + colorReset
+		}
+		line += "  " + text
+	}
+	return line
+}
+
+// tickClock redraws the clock line in place and beeps when the local
+// player's clock is running low.
+func tickClock() {
+	if clocks == nil {
+		return
+	}
+	fmt.Print("\r" + clockLine() + "   ")
+	if playerTurn == playerID && !paused {
+		beepIfLow()
+	}
+	checkFlag()
+}
+
+// checkFlag ends the game on time when the player to move has run out.
+// Either seat may call it, so the game ends even if the flagged player's
+// terminal has gone quiet.
+func checkFlag() {
+	mu.Lock()
+	// The referee flags refereed games from its own clocks
+	flagged := clocks != nil && gameResult == nil && !paused && !gameMeta.Refereed && (playerTurn == 1 || playerTurn == 2) &&
+		clocks.left(playerTurn, playerTurn, paused) == 0
+	var state GameState
+	if flagged {
+		fmt.Printf("\n⏰ Player %d ran out of time!\n", playerTurn)
+		clocks.Remaining[playerTurn-1] = 0
+		gameResult = newWinResult(3-playerTurn, TerminationTime)
+		state = nextState()
+	}
+	mu.Unlock()
+	if flagged {
+		sendState(state)
+		finishGame(state.Result)
+	}
+}
+
+// beepIfLow rings the terminal bell while the local player is low on time.
+func beepIfLow() {
+	if config.Conf.Clock.Beep && lowTime(clocks.left(playerID, playerTurn, paused)) {
+		fmt.Print("\a")
+	}
+}
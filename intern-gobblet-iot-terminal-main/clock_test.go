@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReceivedClocksRestartFromNow(t *testing.T) {
+	sent := &Clocks{Remaining: [2]time.Duration{time.Minute, -time.Second}, TurnStart: time.Now().Add(-time.Hour)}
+	got := receivedClocks(sent, Rules{TimeControl: 180})
+	if got.Remaining != [2]time.Duration{time.Minute, 0} {
+		t.Errorf("remaining %v, want [1m0s 0s]", got.Remaining)
+	}
+	if time.Since(got.TurnStart) > time.Second {
+		t.Errorf("the running clock kept the peer's start %v", got.TurnStart)
+	}
+
+	padded := &Clocks{Remaining: [2]time.Duration{time.Hour, time.Minute}}
+	if got := receivedClocks(padded, Rules{TimeControl: 180}); got.Remaining[0] != 3*time.Minute || !got.TurnStart.IsZero() {
+		t.Errorf("got %+v, want Player 1 capped at the time control and no clock running", got)
+	}
+}
+
+func TestClocksCheck(t *testing.T) {
+	ours := &Clocks{Remaining: [2]time.Duration{time.Minute, time.Minute}}
+	for _, tc := range []struct {
+		name string
+		peer [2]time.Duration
+		ok   bool
+	}{
+		{"same", [2]time.Duration{time.Minute, time.Minute}, true},
+		{"network delay", [2]time.Duration{time.Minute + 2*time.Second, time.Minute - 3*time.Second}, true},
+		{"time handed back", [2]time.Duration{2 * time.Minute, time.Minute}, false},
+		{"time taken away", [2]time.Duration{time.Minute, 10 * time.Second}, false},
+	} {
+		if err := ours.check(&Clocks{Remaining: tc.peer}); (err == nil) != tc.ok {
+			t.Errorf("%s: check() = %v", tc.name, err)
+		}
+	}
+}
+
+func TestAdoptClocksChargesLocally(t *testing.T) {
+	oldClocks, oldMeta := clocks, gameMeta
+	t.Cleanup(func() { clocks, gameMeta = oldClocks, oldMeta })
+
+	clocks = &Clocks{Remaining: [2]time.Duration{time.Minute, time.Minute}, TurnStart: time.Now().Add(-10 * time.Second)}
+	// The mover's machine runs an hour behind and says no time passed
+	peer := &Clocks{Remaining: [2]time.Duration{time.Minute, time.Minute}, TurnStart: time.Now().Add(-time.Hour)}
+	adoptClocks(peer, true, 2)
+	if got := clocks.Remaining[1]; got > 51*time.Second || got < 49*time.Second {
+		t.Errorf("Player 2 has %v left, want about 50s charged from our own clock", got)
+	}
+	if clocks.Remaining[0] != time.Minute || time.Since(clocks.TurnStart) > time.Second {
+		t.Errorf("Player 1's clock should start now with a minute left, got %+v", clocks)
+	}
+}
@@ -0,0 +1,420 @@
+// Command gobbot is a headless computer player. It connects to the broker
+// with its own certificates, claims a seat in a game and plays it with the
+// built-in AI, so one person can practice without a second terminal.
+//
+//	go run ./cmd/gobbot --broker tls://<endpoint>:8883 --game 12345
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"goblets/ai"
+	"goblets/engine"
+	"goblets/notation"
+	"goblets/wire"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// gameState is the part of the terminals' game state the bot reads or
+// updates. Every other field is passed through unchanged.
+type gameState struct {
+	Board       engine.Board
+	PlayerTurn  int
+	Moves       int
+	Paused      bool
+	Result      *result
+	Winner      int
+	Variant     engine.Variant
+	Rules       *engine.Rules
+	Clocks      json.RawMessage
+	Repetitions map[string]int
+	History     []historyEntry
+	Meta        struct{ FirstChoice string }
+	Seq         uint64 `json:",omitempty"`
+	Sender      string `json:",omitempty"`
+}
+
+type result struct {
+	Outcome     string
+	Termination string
+}
+
+type historyEntry struct {
+	Player int
+	Move   engine.Move `json:",omitempty"`
+	Undo   bool        `json:",omitempty"`
+	Time   time.Time
+}
+
+// seatClaim is the signed seat claim terminals publish, see SeatClaim.
+type seatClaim struct {
+	GameID    string
+	Player    int
+	Device    string
+	Session   string
+	Time      time.Time
+	PublicKey []byte
+	Signature []byte `json:",omitempty"`
+}
+
+// seatHolder is a seat in the seating document terminals keep, see
+// SeatHolder.
+type seatHolder struct {
+	Device  string
+	Session string
+	Name    string `json:",omitempty"`
+	Time    time.Time
+}
+
+// moveMessage is a move delta terminals publish on the moves topic, see
+// MoveMessage.
+type moveMessage struct {
+	Type   string
+	From   string `json:",omitempty"`
+	To     string `json:",omitempty"`
+	Size   int    `json:",omitempty"`
+	Player int    `json:",omitempty"`
+	Seq    int
+}
+
+// presence is the heartbeat terminals publish on the presence topic, see
+// Presence.
+type presence struct {
+	Session  string
+	Player   int    `json:",omitempty"`
+	Role     string `json:",omitempty"`
+	Online   bool
+	Time     time.Time `json:",omitempty"`
+	Codecs   []string  `json:",omitempty"`
+	Protocol int       `json:",omitempty"`
+}
+
+// heartbeatInterval is how often the bot announces itself, as terminals do.
+const heartbeatInterval = 5 * time.Second
+
+// message is a received state or move delta.
+type message struct {
+	move    bool
+	payload []byte
+}
+
+func main() {
+	broker := flag.String("broker", "", "broker URL, e.g. tls://<endpoint>:8883")
+	caFile := flag.String("ca", "root-CA.pem", "root CA certificate")
+	certFile := flag.String("cert", "gobbot.pem.crt", "the bot's device certificate")
+	keyFile := flag.String("key", "gobbot.private.pem.key", "the bot's private key")
+	identity := flag.String("identity", "gobbot.seat.key", "seat-claim signing key, created on first run")
+	game := flag.String("game", "", "ID of the game to join")
+	seat := flag.Int("player", 2, "seat the bot plays (1 or 2)")
+	level := flag.String("level", "medium", "easy, medium or hard")
+	engineName := flag.String("engine", ai.Minimax, "minimax or mcts")
+	pace := flag.Duration("pace", time.Second, "least think time before each move")
+	bookFile := flag.String("book", "", "opening book written by selfplay --book")
+	flag.Parse()
+
+	limits, ok := ai.Levels[*level]
+	if *broker == "" || *game == "" || strings.ContainsAny(*game, "/+# ") || (*seat != 1 && *seat != 2) || !ok {
+		fmt.Println("❌ Usage: gobbot --broker <url> --game <ID> [--player 1|2] [--level easy|medium|hard]")
+		os.Exit(1)
+	}
+	limits.Engine = *engineName
+	if *bookFile != "" {
+		data, err := os.ReadFile(*bookFile)
+		if err == nil {
+			limits.Book, err = ai.ParseBook(data)
+		}
+		if err != nil {
+			log.Fatal("❌ Could not load the opening book: ", err)
+		}
+	}
+
+	key, err := loadIdentity(*identity)
+	if err != nil {
+		log.Fatal("❌ Could not load the seat-claim key: ", err)
+	}
+	hostname, _ := os.Hostname()
+	device := "gobbot-" + hostname
+	session := fmt.Sprintf("Gobbot-%s-%d", hostname, time.Now().UnixNano())
+	topic := "gobblet/game/" + *game
+	goodbye, _ := json.Marshal(presence{Session: session})
+	client, err := connect(*broker, *caFile, *certFile, *keyFile, session, topic+"/presence", goodbye)
+	if err != nil {
+		log.Fatal("❌ MQTT Connection Error: ", err)
+	}
+	defer client.Disconnect(250)
+	defer func() { client.Publish(topic+"/presence", 0, false, goodbye).Wait() }()
+	// Terminals only switch to a binary wire format when every seat holder
+	// reads it; the bot reads JSON.
+	go heartbeat(client, topic+"/presence", presence{Session: session, Player: *seat, Role: "player", Online: true, Codecs: []string{"json"}, Protocol: 3})
+	messages := make(chan message, 8)
+	token := client.Subscribe(topic+"/state", 1, func(client mqtt.Client, msg mqtt.Message) {
+		messages <- message{payload: wire.Unwrap(msg.Payload())}
+	})
+	if token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error: ", token.Error())
+	}
+	token = client.Subscribe(topic+"/moves", 1, func(client mqtt.Client, msg mqtt.Message) {
+		messages <- message{move: true, payload: msg.Payload()}
+	})
+	if token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error: ", token.Error())
+	}
+
+	holder := seatHolder{Device: device, Session: session, Name: "gobbot", Time: time.Now().UTC()}
+	if err := reserveSeat(client, topic+"/seats", *seat, holder); err != nil {
+		log.Fatal("❌ Could not reserve the seat: ", err)
+	}
+	claim := seatClaim{GameID: *game, Player: *seat, Device: device, Session: session, Time: time.Now().UTC(), PublicKey: key.Public().(ed25519.PublicKey)}
+	data, _ := json.Marshal(claim)
+	claim.Signature = ed25519.Sign(key, data)
+	data, _ = json.Marshal(claim)
+	if token := client.Publish(fmt.Sprintf("%s/claims/%d", topic, *seat), 1, true, data); token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Could not claim the seat: ", token.Error())
+	}
+	fmt.Printf("🤖 gobbot is playing game %s as Player %d (%s, %s)\n", *game, *seat, *level, limits.Engine)
+
+	played := -1       // move count of the last state we answered
+	var payload []byte // the latest full state, with any deltas applied
+	var s gameState
+	for msg := range messages {
+		if msg.move {
+			next, err := applyDelta(s, payload, msg.payload)
+			if err != nil {
+				fmt.Println("⚠ Ignoring a move:", err)
+				continue
+			}
+			if next == nil {
+				continue
+			}
+			payload = next
+		} else {
+			var numbered struct{ Seq uint64 }
+			if json.Unmarshal(msg.payload, &numbered) == nil && numbered.Seq != 0 && numbered.Seq < s.Seq {
+				continue // older than the state we hold
+			}
+			payload = msg.payload
+		}
+		s = gameState{}
+		if err := json.Unmarshal(payload, &s); err != nil {
+			fmt.Println("⚠ Ignoring an unreadable game state:", err)
+			continue
+		}
+		if s.Result != nil || s.Winner != 0 {
+			fmt.Println("🏁 Game over after", s.Moves, "moves")
+			return
+		}
+		if len(s.Clocks) > 0 && string(s.Clocks) != "null" {
+			log.Fatal("❌ gobbot doesn't keep game clocks; create the game without a time control")
+		}
+		if s.PlayerTurn == 0 && s.Meta.FirstChoice == "coin flip" {
+			log.Fatal("❌ gobbot can't take part in a coin flip; let the creator move first")
+		}
+		if s.PlayerTurn != *seat || s.Paused || s.Moves == played {
+			continue
+		}
+
+		start := time.Now()
+		m, ok := ai.Choose(position(s), limits)
+		if !ok {
+			log.Fatal("❌ no legal move")
+		}
+		next, err := play(s, m)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		next.Seq, next.Sender = s.Seq+1, session
+		time.Sleep(*pace - time.Since(start))
+		out, err := update(payload, next)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		if token := client.Publish(topic+"/state", 1, true, out); token.Wait() && token.Error() != nil {
+			log.Fatal("❌ Could not publish the move: ", token.Error())
+		}
+		played = next.Moves
+		payload = out
+		fmt.Println("🤖 Played", notation.Format(m))
+		if next.Result != nil {
+			fmt.Printf("🏁 Game over: %s by %s\n", next.Result.Outcome, next.Result.Termination)
+			return
+		}
+	}
+}
+
+func position(s gameState) engine.State {
+	return engine.State{Board: s.Board, Turn: s.PlayerTurn, Variant: s.Variant, Rules: s.Rules}
+}
+
+// play applies m for the player to move with the engine's bookkeeping,
+// plus the history the terminals keep in playMove.
+func play(s gameState, m engine.Move) (gameState, error) {
+	seat := s.PlayerTurn
+	g, err := engine.Game{State: position(s), Moves: s.Moves, Repetitions: s.Repetitions}.Play(m)
+	if err != nil {
+		return s, err
+	}
+	s.Board, s.PlayerTurn, s.Moves, s.Winner, s.Repetitions = g.Board, g.Turn, g.Moves, g.Winner, g.Repetitions
+	s.History = append(s.History, historyEntry{Player: seat, Move: m, Time: time.Now().UTC()})
+	if g.Result != nil {
+		s.Result = &result{Outcome: g.Result.Outcome(), Termination: g.Result.Termination}
+	}
+	return s, nil
+}
+
+// applyDelta applies a move delta to the full state in payload and returns
+// the new payload, or nil when the delta is one we already have or can't
+// place yet.
+func applyDelta(s gameState, payload, delta []byte) ([]byte, error) {
+	var mm moveMessage
+	if err := json.Unmarshal(delta, &mm); err != nil {
+		return nil, err
+	}
+	if payload == nil || mm.Type == "resync" || mm.Type == "ack" || mm.Seq != s.Moves+1 {
+		return nil, nil // a full state will follow a gap
+	}
+	if mm.Player != s.PlayerTurn {
+		return nil, fmt.Errorf("Player %d moved on Player %d's turn", mm.Player, s.PlayerTurn)
+	}
+	m, err := notation.Delta(mm.Type, mm.From, mm.To, mm.Size)
+	if err != nil {
+		return nil, err
+	}
+	next, err := play(s, m)
+	if err != nil {
+		return nil, err
+	}
+	return update(payload, next)
+}
+
+// update writes the fields the bot changed into the received state, keeping
+// every other field as the terminals sent it.
+func update(payload []byte, s gameState) ([]byte, error) {
+	return wire.UpdateState(payload, map[string]any{
+		"Board": s.Board, "PlayerTurn": s.PlayerTurn, "Moves": s.Moves, "Result": s.Result,
+		"Winner": s.Winner, "Repetitions": s.Repetitions, "History": s.History, "Seq": s.Seq, "Sender": s.Sender,
+	})
+}
+
+// reserveSeat takes a seat in the game's seating document as terminals do
+// in reserveSeats: the claim is written, given time for racing claims to
+// land, and read back.
+func reserveSeat(client mqtt.Client, topic string, seat int, me seatHolder) error {
+	docs := make(chan map[int]seatHolder, 1)
+	token := client.Subscribe(topic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		var doc map[int]seatHolder
+		if json.Unmarshal(msg.Payload(), &doc) != nil {
+			return
+		}
+		select { // keep only the latest document
+		case <-docs:
+		default:
+		}
+		docs <- doc
+	})
+	if token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	defer client.Unsubscribe(topic)
+
+	// latest returns the newest document received within wait
+	latest := func(doc map[int]seatHolder, wait time.Duration) map[int]seatHolder {
+		timeout := time.After(wait)
+		for {
+			select {
+			case doc = <-docs:
+			case <-timeout:
+				return doc
+			}
+		}
+	}
+	doc := latest(nil, time.Second)
+	for range 3 {
+		h, taken := doc[seat]
+		if taken && h.Device != me.Device {
+			return fmt.Errorf("seat %d is taken by %s", seat, h.Device)
+		}
+		if taken && h.Session == me.Session {
+			return nil
+		}
+		if doc == nil {
+			doc = map[int]seatHolder{}
+		}
+		doc[seat] = me
+		data, _ := json.Marshal(doc)
+		if token := client.Publish(topic, 1, true, data); token.Wait() && token.Error() != nil {
+			return token.Error()
+		}
+		doc = latest(nil, time.Second) // what the broker kept, our claim or not
+	}
+	return errors.New("the seating kept changing")
+}
+
+// heartbeat announces the bot on the presence topic every
+// heartbeatInterval, as terminals do in announcePresence.
+func heartbeat(client mqtt.Client, topic string, p presence) {
+	for {
+		p.Time = time.Now().UTC()
+		data, _ := json.Marshal(p)
+		client.Publish(topic, 0, false, data).Wait()
+		time.Sleep(heartbeatInterval)
+	}
+}
+
+// loadIdentity reads the bot's seat-claim key, creating it on first run.
+func loadIdentity(path string) (ed25519.PrivateKey, error) {
+	seed, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		seed = make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(path, seed, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s is not a seat-claim key", path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// connect opens a TLS connection to the broker with the bot's certificates,
+// leaving will for the broker to publish on willTopic if the bot dies.
+func connect(broker, caFile, certFile, keyFile, clientID, willTopic string, will []byte) (mqtt.Client, error) {
+	pemCerts, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	certpool := x509.NewCertPool()
+	certpool.AppendCertsFromPEM(pemCerts)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(clientID).
+		SetTLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: certpool}).
+		SetKeepAlive(30*time.Second).
+		SetPingTimeout(20*time.Second).
+		SetAutoReconnect(true).
+		SetBinaryWill(willTopic, will, 0, false)
+	client := mqtt.NewClient(opts)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	return client, nil
+}
@@ -0,0 +1,874 @@
+// Command goblet-referee is the authoritative referee service for refereed
+// games. Players only send their moves; the referee checks each one against
+// the canonical state with the engine and is the only one to publish the
+// retained state, so an illegal or forged board never reaches the players.
+//
+//	go run ./cmd/goblet-referee --broker tls://<endpoint>:8883
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"goblets/engine"
+	"goblets/notation"
+	"goblets/wire"
+	"log"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/spf13/viper"
+)
+
+// sender is the Sender of every state the referee publishes; terminals in
+// refereed games only apply states from it.
+const sender = "goblet-referee"
+
+// refereeSeat is the seat number terminals give the referee in rejections.
+const refereeSeat = 4
+
+// gameState is the part of the terminals' game state the referee reads or
+// updates. Every other field is passed through unchanged.
+type gameState struct {
+	Board       engine.Board
+	PlayerTurn  int
+	Moves       int
+	Paused      bool
+	Result      *result
+	Winner      int
+	Variant     engine.Variant
+	Rules       *engine.Rules
+	Clocks      json.RawMessage
+	Repetitions map[string]int
+	History     []historyEntry
+	Meta        struct {
+		Refereed bool
+		Preset   string
+		Rules    struct{ TimeControl int }
+		Players  []struct{ ID string } `json:",omitempty"`
+	}
+	Control *control `json:",omitempty"`
+	// Ruling is an arbiter's signed pause, resumption or adjudication,
+	// which the terminals check against their trusted keys.
+	Ruling *struct {
+		Action string
+		Moves  int
+	} `json:",omitempty"`
+	Seq    uint64 `json:",omitempty"`
+	Sender string `json:",omitempty"`
+	// Seat is the player who published the state, signed with their seat
+	// claim's key.
+	Seat int `json:",omitempty"`
+}
+
+// control is a resignation or draw message sent with a state, see Control.
+type control struct {
+	Action string
+	Player int
+}
+
+type result struct {
+	Outcome     string
+	Termination string
+}
+
+// boardTerminations are the results only a move can bring about.
+var boardTerminations = map[string]bool{"line": true, "uncovered line": true, "repetition": true, "stalemate": true}
+
+// clocks mirrors the terminals' Clocks. The referee runs them from the
+// times moves reach it, and the clocks players send are ignored.
+type clocks struct {
+	Remaining [2]time.Duration
+	TurnStart time.Time
+}
+
+// now is the referee's clock, replaced in tests.
+var now = time.Now
+
+type historyEntry struct {
+	Player int
+	Move   engine.Move `json:",omitempty"`
+	Undo   bool        `json:",omitempty"`
+	Time   time.Time
+}
+
+// moveMessage is a move delta terminals publish on the moves topic, see
+// MoveMessage.
+type moveMessage struct {
+	Type   string
+	From   string `json:",omitempty"`
+	To     string `json:",omitempty"`
+	Size   int    `json:",omitempty"`
+	Player int    `json:",omitempty"`
+	Seq    int
+	Clocks json.RawMessage `json:",omitempty"`
+	// Signature is the mover's, with the key of their seat claim.
+	Signature []byte `json:",omitempty"`
+}
+
+// seatHolder is a seat in the seating document terminals keep, see
+// SeatHolder.
+type seatHolder struct {
+	Device  string
+	Session string
+	Name    string `json:",omitempty"`
+}
+
+// seatClaim is the signed seat claim terminals publish, see SeatClaim.
+type seatClaim struct {
+	GameID    string
+	Player    int
+	Device    string
+	Session   string
+	Time      time.Time
+	PublicKey []byte
+	Signature []byte `json:",omitempty"`
+}
+
+// seating tells who may move for each seat of a game: the device holding
+// it in the seating document, by the key of a seat claim it signed. A
+// seat's key is kept from the first claim that matches its holder.
+type seating struct {
+	holders map[int]seatHolder
+	claims  map[int]seatClaim
+	keys    map[int]ed25519.PublicKey
+}
+
+// rejection tells the players a move or state was refused, see Rejection.
+type rejection struct {
+	Player int
+	Moves  int
+	Reason string
+}
+
+// game is the canonical state of one refereed game.
+type game struct {
+	payload   []byte // as last published, every field the terminals sent
+	s         gameState
+	clocks    *clocks // nil without a time control
+	drawOffer int     // the seat with an open draw offer, 0 if none
+	started   time.Time
+}
+
+type referee struct {
+	client mqtt.Client
+	key    ed25519.PrivateKey // signs every published state
+	org    *organization      // nil outside classroom mode
+	games  map[string]*game
+	seats  map[string]*seating
+}
+
+// organization is the organization block of the players' config.yaml, see
+// OrganizationConfig. The referee holds every game it referees to it.
+type organization struct {
+	Name           string    `mapstructure:"name"`
+	Roster         []student `mapstructure:"roster"`
+	AllowedPresets []string  `mapstructure:"allowed_presets"`
+	Chat           bool      `mapstructure:"chat"`
+	SessionMinutes int       `mapstructure:"session_minutes"`
+}
+
+type student struct {
+	ID   string `mapstructure:"id"`
+	Name string `mapstructure:"name"`
+}
+
+// message is a received state or move.
+type message struct {
+	topic   string
+	payload []byte
+}
+
+func main() {
+	broker := flag.String("broker", "", "broker URL, e.g. tls://<endpoint>:8883")
+	caFile := flag.String("ca", "root-CA.pem", "root CA certificate")
+	certFile := flag.String("cert", "referee.pem.crt", "the referee's device certificate")
+	keyFile := flag.String("key", "referee.private.pem.key", "the referee's private key")
+	signKey := flag.String("sign-key", "referee.sign.key", "the key the referee signs its states with, created on first run")
+	only := flag.String("game", "", "referee only this game; empty for every refereed game")
+	orgFile := flag.String("org", "", "a config.yaml whose organization block the games must keep to")
+	flag.Parse()
+
+	if *broker == "" || strings.ContainsAny(*only, "/+# ") {
+		fmt.Println("❌ Usage: goblet-referee --broker <url> [--game <ID>] [--org config.yaml]")
+		os.Exit(1)
+	}
+	key, err := loadSigningKey(*signKey)
+	if err != nil {
+		log.Fatal("❌ Signing key error: ", err)
+	}
+	var org *organization
+	if *orgFile != "" {
+		if org, err = loadOrganization(*orgFile); err != nil {
+			log.Fatal("❌ Organization error: ", err)
+		}
+		fmt.Println("🏫 Enforcing the rules of", org.Name)
+	}
+	fmt.Println("🔑 Referee key (referee_key in the players' config.yaml):", base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)))
+	hostname, _ := os.Hostname()
+	client, err := connect(*broker, *caFile, *certFile, *keyFile, fmt.Sprintf("GobbletReferee-%s-%d", hostname, time.Now().UnixNano()))
+	if err != nil {
+		log.Fatal("❌ MQTT Connection Error: ", err)
+	}
+	defer client.Disconnect(250)
+
+	// One goroutine handles every message, so games need no locking
+	messages := make(chan message, 64)
+	id := *only
+	if id == "" {
+		id = "+"
+	}
+	filters := map[string]byte{
+		"gobblet/game/" + id + "/state": 1, "gobblet/game/" + id + "/moves": 1,
+		"gobblet/game/" + id + "/seats": 1, "gobblet/game/" + id + "/claims/+": 1,
+	}
+	token := client.SubscribeMultiple(filters, func(client mqtt.Client, msg mqtt.Message) {
+		messages <- message{topic: msg.Topic(), payload: msg.Payload()}
+	})
+	if token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error: ", token.Error())
+	}
+	fmt.Println("⚖ goblet-referee is watching", "gobblet/game/"+id)
+
+	r := newReferee(client, key)
+	r.org = org
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			r.checkTime()
+			continue
+		case msg := <-messages:
+			r.handle(msg)
+		}
+	}
+}
+
+// handle passes a received message to the handler for its topic.
+func (r *referee) handle(msg message) {
+	parts := strings.Split(msg.topic, "/")
+	if len(parts) < 4 || len(msg.payload) == 0 {
+		return
+	}
+	switch {
+	case parts[3] == "claims":
+		r.onClaim(parts[2], msg.payload)
+	case path.Base(msg.topic) == "state":
+		r.onState(parts[2], wire.Unwrap(msg.payload))
+	case path.Base(msg.topic) == "moves":
+		r.onMove(parts[2], msg.payload)
+	case path.Base(msg.topic) == "seats":
+		r.onSeats(parts[2], msg.payload)
+	}
+}
+
+func newReferee(client mqtt.Client, key ed25519.PrivateKey) *referee {
+	return &referee{client: client, key: key, games: map[string]*game{}, seats: map[string]*seating{}}
+}
+
+func (r *referee) seating(id string) *seating {
+	s := r.seats[id]
+	if s == nil {
+		s = &seating{holders: map[int]seatHolder{}, claims: map[int]seatClaim{}, keys: map[int]ed25519.PublicKey{}}
+		r.seats[id] = s
+	}
+	return s
+}
+
+// loadOrganization reads the organization block of a config.yaml.
+func loadOrganization(path string) (*organization, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetDefault("organization.chat", true)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	var org organization
+	if err := v.UnmarshalKey("organization", &org); err != nil {
+		return nil, err
+	}
+	if org.Name == "" {
+		return nil, fmt.Errorf("%s has no organization.name", path)
+	}
+	return &org, nil
+}
+
+// onRoster reports whether a student's ID or name is on the roster. Without
+// an organization or a roster anyone may play.
+func (o *organization) onRoster(idOrName string) bool {
+	if o == nil || len(o.Roster) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(o.Roster, func(s student) bool {
+		return s.ID == idOrName || strings.EqualFold(s.Name, idOrName)
+	})
+}
+
+// admits checks a new game's preset and named players against the
+// organization.
+func (o *organization) admits(s gameState) error {
+	if o == nil {
+		return nil
+	}
+	if len(o.AllowedPresets) > 0 && !slices.Contains(o.AllowedPresets, s.Meta.Preset) {
+		return fmt.Errorf("%s doesn't allow the %q preset", o.Name, s.Meta.Preset)
+	}
+	for _, p := range s.Meta.Players {
+		if !o.onRoster(p.ID) {
+			return fmt.Errorf("player %q is not on the roster of %s", p.ID, o.Name)
+		}
+	}
+	return nil
+}
+
+// chatOff reports whether the organization turned chat off.
+func (o *organization) chatOff() bool {
+	return o != nil && !o.Chat
+}
+
+// onSeats follows a game's seating document.
+func (r *referee) onSeats(id string, payload []byte) {
+	var holders map[int]seatHolder
+	if json.Unmarshal(payload, &holders) == nil {
+		r.seating(id).holders = holders
+	}
+}
+
+// onClaim keeps the latest correctly signed seat claim for each seat.
+func (r *referee) onClaim(id string, payload []byte) {
+	var c seatClaim
+	if json.Unmarshal(payload, &c) != nil || c.GameID != id || len(c.PublicKey) != ed25519.PublicKeySize {
+		return
+	}
+	sig := c.Signature
+	c.Signature = nil
+	doc, _ := json.Marshal(c) // claims are signed in field order, see SeatClaim.signedBytes
+	if ed25519.Verify(c.PublicKey, doc, sig) {
+		r.seating(id).claims[c.Player] = c
+	}
+}
+
+// seatKey returns the key moves for a seat must be signed with, pinning it
+// the first time a claim matches the seat's holder.
+func (s *seating) seatKey(seat int) (ed25519.PublicKey, bool) {
+	if key, ok := s.keys[seat]; ok {
+		return key, true
+	}
+	holder, seated := s.holders[seat]
+	claim, claimed := s.claims[seat]
+	if !seated || !claimed || claim.Device != holder.Device {
+		return nil, false
+	}
+	s.keys[seat] = claim.PublicKey
+	return claim.PublicKey, true
+}
+
+// fromSeatHolder reports whether a move or state was signed by the holder
+// of seat.
+func (r *referee) fromSeatHolder(id string, seat int, payload []byte) bool {
+	key, ok := r.seating(id).seatKey(seat)
+	if !ok {
+		return false
+	}
+	doc, sig, err := canonical(payload)
+	return err == nil && ed25519.Verify(key, doc, sig)
+}
+
+// onState adopts the referee's own states and new games, and checks states
+// players publish themselves, see checkState.
+func (r *referee) onState(id string, payload []byte) {
+	var s gameState
+	if err := json.Unmarshal(payload, &s); err != nil || !s.Meta.Refereed {
+		return
+	}
+	if s.Sender == sender && !r.signed(payload) {
+		fmt.Printf("⚠ %s: ignored a state sent in the referee's name without its signature\n", id)
+		return
+	}
+	g := r.games[id]
+	switch {
+	case g == nil && s.Sender != sender && !fresh(s):
+		// A game the referee didn't see begin, or a forgery of one
+		fmt.Printf("⚠ %s: ignored a refereed game that didn't start at move 0 in front of the referee\n", id)
+	case g == nil:
+		if err := r.org.admits(s); err != nil {
+			r.refuse(id, s.Moves, "game: "+err.Error())
+			return
+		}
+		g = &game{payload: payload, s: s, clocks: adoptClocks(s), started: now()}
+		r.games[id] = g
+		if s.Sender != sender {
+			fmt.Printf("📋 Refereeing game %s\n", id)
+			r.publish(id, g, payload, s, s.Seq+1) // players only trust signed states
+		}
+	case s.Sender == sender:
+		if s.Seq > g.s.Seq {
+			// A state from an earlier run
+			g.payload, g.s, g.clocks = payload, s, adoptClocks(s)
+		}
+	case s.Seq != 0 && s.Seq < g.s.Seq:
+		// older than the canonical state
+	default:
+		if err := r.checkState(id, g, s, payload); err != nil {
+			r.reject(id, g, s.Moves, "state: "+err.Error(), s.Seq)
+			return
+		}
+		if c := s.Control; c != nil {
+			switch c.Action {
+			case "draw_offer":
+				g.drawOffer = c.Player
+			case "draw_decline":
+				g.drawOffer = 0
+			}
+		}
+		if s.Paused != g.s.Paused && g.clocks != nil {
+			g.clocks.setPaused(s.PlayerTurn, s.Paused, now())
+		}
+		over := g.s.Result == nil && s.Result != nil
+		r.publish(id, g, payload, s, max(g.s.Seq, s.Seq)+1)
+		if over {
+			fmt.Printf("🏁 %s: %s by %s\n", id, s.Result.Outcome, s.Result.Termination)
+		}
+	}
+}
+
+// checkState checks a state a player or arbiter published against the
+// canonical one. It may never change the board. An arbiter's pause,
+// resumption or adjudication comes with its signed ruling. Anything else
+// must be signed by a seat holder, who may resign their own seat, offer or
+// answer a draw, and accept the opponent's open offer.
+func (r *referee) checkState(id string, g *game, s gameState, payload []byte) error {
+	if err := sameBoard(g.s, s); err != nil {
+		return err
+	}
+	if s.Ruling != nil {
+		return ruled(g.s, s)
+	}
+	switch {
+	case s.Seat != 1 && s.Seat != 2 || !r.fromSeatHolder(id, s.Seat, payload):
+		return errors.New("it isn't signed by the holder of a seat")
+	case s.Paused != g.s.Paused:
+		return errors.New("only an arbiter's ruling pauses or resumes the game")
+	case s.Control != nil && s.Control.Player != s.Seat:
+		return fmt.Errorf("Player %d sent Player %d's %s", s.Seat, s.Control.Player, s.Control.Action)
+	}
+	return decided(g, s)
+}
+
+// ruled checks that an arbiter's state changes only what its ruling
+// covers.
+func ruled(canonical, s gameState) error {
+	ruling := s.Ruling
+	var ok bool
+	switch ruling.Action {
+	case "pause":
+		ok = s.Paused && !canonical.Paused && sameJSON(canonical.Result, s.Result)
+	case "resume":
+		ok = !s.Paused && canonical.Paused && sameJSON(canonical.Result, s.Result)
+	case "adjudication":
+		ok = s.Paused == canonical.Paused && canonical.Result == nil && s.Result != nil &&
+			s.Result.Termination == "adjudication" && s.Winner == s.Result.winner()
+	}
+	if !ok || ruling.Moves != s.Moves {
+		return fmt.Errorf("the arbiter's %q ruling doesn't match the state", ruling.Action)
+	}
+	return nil
+}
+
+// decided checks the result a seat holder's state brings: a resignation of
+// their own seat, or a draw agreed to the opponent's open offer. Every
+// other result is decided by the board or by the referee's clocks.
+func decided(g *game, s gameState) error {
+	c := g.s
+	switch {
+	case sameJSON(c.Result, s.Result) && s.Winner == c.Winner:
+		return nil
+	case c.Result != nil:
+		return errors.New("the game is over")
+	case s.Result == nil || s.Winner != s.Result.winner():
+		return errors.New("the winner doesn't match the result")
+	}
+	switch s.Result.Termination {
+	case "resignation":
+		if s.Winner != 3-s.Seat {
+			return fmt.Errorf("Player %d can only resign for themselves", s.Seat)
+		}
+	case "agreement":
+		if s.Winner != 0 || g.drawOffer != 3-s.Seat {
+			return fmt.Errorf("Player %d agreed a draw nobody offered them", s.Seat)
+		}
+	default:
+		return fmt.Errorf("a %s result is for the referee to decide", s.Result.Termination)
+	}
+	return nil
+}
+
+// winner returns the seat a result goes to, 0 for a draw.
+func (r *result) winner() int {
+	switch r.Outcome {
+	case "1-0":
+		return 1
+	case "0-1":
+		return 2
+	}
+	return 0
+}
+
+// fresh reports whether a state is a game nobody has moved in yet.
+func fresh(s gameState) bool {
+	for _, row := range s.Board {
+		for _, stack := range row {
+			if len(stack) > 0 {
+				return false
+			}
+		}
+	}
+	return s.Moves == 0 && len(s.History) == 0 && s.Result == nil && s.Winner == 0
+}
+
+// sameBoard checks that a player's state leaves the board, the turn, the
+// rules and board-decided results as they are. Its clocks are replaced by
+// the referee's.
+func sameBoard(canonical, s gameState) error {
+	switch {
+	case !sameJSON(canonical.Board, s.Board):
+		return errors.New("only moves may change the board")
+	case s.Moves != canonical.Moves || len(s.History) != len(canonical.History):
+		return errors.New("only moves may change the move count")
+	case s.PlayerTurn != canonical.PlayerTurn:
+		return errors.New("only moves may change the turn")
+	case s.Variant != canonical.Variant || !sameJSON(canonical.Rules, s.Rules):
+		return errors.New("the rules can't change during a game")
+	case s.Result != nil && boardTerminations[s.Result.Termination] && canonical.Result == nil:
+		return fmt.Errorf("a %s result is decided by the board", s.Result.Termination)
+	}
+	return nil
+}
+
+// sameJSON compares two values by their JSON encoding.
+func sameJSON(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Equal(x, y)
+}
+
+// onMove plays a player's move on the canonical state if it is legal.
+func (r *referee) onMove(id string, payload []byte) {
+	g := r.games[id]
+	var mm moveMessage
+	if g == nil || json.Unmarshal(payload, &mm) != nil || mm.Type == "ack" {
+		return
+	}
+	if mm.Type == "resync" {
+		if mm.Seq < g.s.Moves {
+			r.client.Publish("gobblet/game/"+id+"/state", 1, true, g.payload)
+		}
+		return
+	}
+	if mm.Seq <= g.s.Moves {
+		if mm.Seq == g.s.Moves {
+			// A retransmission of the last move: the state confirming it was lost
+			r.client.Publish("gobblet/game/"+id+"/state", 1, true, g.payload)
+		}
+		return
+	}
+
+	s := g.s
+	var reason string
+	switch {
+	case !r.fromSeatHolder(id, mm.Player, payload):
+		reason = fmt.Sprintf("move %d isn't signed by the holder of Player %d's seat", mm.Seq, mm.Player)
+	case !r.org.onRoster(r.seating(id).holders[mm.Player].Name):
+		reason = fmt.Sprintf("Player %d, %q, is not on the roster of %s", mm.Player, r.seating(id).holders[mm.Player].Name, r.org.Name)
+	case mm.Seq != s.Moves+1:
+		reason = fmt.Sprintf("move %d arrived before move %d", mm.Seq, s.Moves+1)
+	case s.Result != nil || s.Winner != 0:
+		reason = "the game is over"
+	case s.Paused:
+		reason = "the game is paused"
+	case mm.Player != s.PlayerTurn:
+		reason = fmt.Sprintf("Player %d moved on Player %d's turn", mm.Player, s.PlayerTurn)
+	}
+	if reason != "" {
+		r.reject(id, g, mm.Seq, reason, 0)
+		return
+	}
+	if g.clocks.flagged(s.PlayerTurn, now()) {
+		r.flag(id, g)
+		return
+	}
+	m, err := notation.Delta(mm.Type, mm.From, mm.To, mm.Size)
+	var next gameState
+	if err == nil {
+		next, err = play(s, m)
+	}
+	if err != nil {
+		r.reject(id, g, mm.Seq, fmt.Sprintf("illegal move by Player %d: %v", mm.Player, err), 0)
+		return
+	}
+	if g.clocks != nil {
+		g.clocks.charge(mm.Player, now())
+	}
+	g.drawOffer = 0
+	out, err := update(g.payload, next)
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	fmt.Printf("✅ %s: move %d, Player %d plays %s\n", id, next.Moves, mm.Player, notation.Format(m))
+	r.publish(id, g, out, next, s.Seq+1)
+	if next.Result != nil {
+		fmt.Printf("🏁 %s: %s by %s\n", id, next.Result.Outcome, next.Result.Termination)
+	}
+}
+
+// checkTime ends games whose player to move ran out of time on the
+// referee's clocks, and games past the organization's session time.
+func (r *referee) checkTime() {
+	for id, g := range r.games {
+		switch {
+		case g.s.Result != nil || g.s.Paused:
+		case g.clocks.flagged(g.s.PlayerTurn, now()):
+			r.flag(id, g)
+		case r.org != nil && r.org.SessionMinutes > 0 && now().Sub(g.started) >= time.Duration(r.org.SessionMinutes)*time.Minute:
+			r.end(id, g, &result{Outcome: "½-½", Termination: "session time"})
+		}
+	}
+}
+
+// flag ends a game on time for the player to move.
+func (r *referee) flag(id string, g *game) {
+	loser := g.s.PlayerTurn
+	g.clocks.Remaining[loser-1] = 0
+	outcome := "1-0"
+	if loser == 1 {
+		outcome = "0-1"
+	}
+	r.end(id, g, &result{Outcome: outcome, Termination: "time"})
+}
+
+// end publishes the canonical state with a result the referee decided.
+func (r *referee) end(id string, g *game, res *result) {
+	next := g.s
+	next.Result, next.Winner = res, res.winner()
+	out, err := update(g.payload, next)
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	r.publish(id, g, out, next, g.s.Seq+1)
+	fmt.Printf("🏁 %s: %s by %s\n", id, res.Outcome, res.Termination)
+}
+
+// adoptClocks starts the referee's clocks for a game: from the time
+// control for a new game, or from the referee's own last state after a
+// restart.
+func adoptClocks(s gameState) *clocks {
+	if s.Meta.Rules.TimeControl <= 0 {
+		return nil
+	}
+	c := &clocks{}
+	if s.Sender == sender && json.Unmarshal(s.Clocks, c) == nil {
+		return c
+	}
+	total := time.Duration(s.Meta.Rules.TimeControl) * time.Second
+	return &clocks{Remaining: [2]time.Duration{total, total}}
+}
+
+// charge stops player's clock at t, when their move reached the referee,
+// and starts the opponent's. Clocks start after the first move.
+func (c *clocks) charge(player int, t time.Time) {
+	if !c.TurnStart.IsZero() {
+		c.Remaining[player-1] -= t.Sub(c.TurnStart)
+	}
+	c.TurnStart = t
+}
+
+// setPaused stops the running clock for a pause and restarts it on resume.
+func (c *clocks) setPaused(toMove int, pause bool, t time.Time) {
+	switch {
+	case c.TurnStart.IsZero():
+	case pause:
+		c.charge(toMove, t)
+	default:
+		c.TurnStart = t
+	}
+}
+
+// flagged reports whether the player to move is out of time at t.
+func (c *clocks) flagged(toMove int, t time.Time) bool {
+	return c != nil && !c.TurnStart.IsZero() && (toMove == 1 || toMove == 2) &&
+		c.Remaining[toMove-1]-t.Sub(c.TurnStart) <= 0
+}
+
+// reject tells the players why a move or state was refused and republishes
+// the canonical state over whatever they now show, numbered past seen.
+func (r *referee) reject(id string, g *game, moves int, reason string, seen uint64) {
+	r.refuse(id, moves, reason)
+	r.publish(id, g, g.payload, g.s, max(g.s.Seq, seen)+1)
+}
+
+// refuse tells the players why a move, state or game was refused.
+func (r *referee) refuse(id string, moves int, reason string) {
+	fmt.Printf("❌ %s: %s\n", id, reason)
+	data, _ := json.Marshal(rejection{Player: refereeSeat, Moves: moves, Reason: reason})
+	r.client.Publish("gobblet/game/"+id+"/control", 1, false, data)
+}
+
+// publish makes s, encoded in payload, the canonical state and publishes it
+// as the retained state numbered seq.
+func (r *referee) publish(id string, g *game, payload []byte, s gameState, seq uint64) {
+	s.Seq, s.Sender = seq, sender
+	fields := map[string]any{}
+	if g.clocks != nil {
+		fields["Clocks"] = g.clocks
+	}
+	if r.org.chatOff() {
+		fields["ChatOff"] = true
+	}
+	out, err := stamp(payload, seq, fields, r.key)
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	g.payload, g.s = out, s
+	if token := r.client.Publish("gobblet/game/"+id+"/state", 1, true, out); token.Wait() && token.Error() != nil {
+		fmt.Printf("❌ %s: could not publish the state: %v\n", id, token.Error())
+	}
+}
+
+// stamp numbers a state, marks it as the referee's, writes the fields the
+// referee keeps itself and signs it.
+func stamp(payload []byte, seq uint64, fields map[string]any, key ed25519.PrivateKey) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return nil, err
+	}
+	delete(doc, "Signature")
+	delete(doc, "Seat")
+	fields["Seq"], fields["Sender"] = seq, sender
+	for name, v := range fields {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		doc[name] = data
+	}
+	signed, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	doc["Signature"], _ = json.Marshal(ed25519.Sign(key, signed))
+	return json.Marshal(doc)
+}
+
+// signed reports whether a state carries the referee's own signature.
+func (r *referee) signed(payload []byte) bool {
+	doc, sig, err := canonical(payload)
+	return err == nil && ed25519.Verify(r.key.Public().(ed25519.PublicKey), doc, sig)
+}
+
+// canonical returns what a signature in a refereed game covers, the JSON
+// document without its Signature field with keys sorted as encoding/json
+// writes a map, and the signature.
+func canonical(payload []byte) (doc, sig []byte, err error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, nil, err
+	}
+	if s, ok := fields["Signature"]; ok {
+		json.Unmarshal(s, &sig)
+	}
+	delete(fields, "Signature")
+	doc, err = json.Marshal(fields)
+	return doc, sig, err
+}
+
+func position(s gameState) engine.State {
+	return engine.State{Board: s.Board, Turn: s.PlayerTurn, Variant: s.Variant, Rules: s.Rules}
+}
+
+// play applies m for the player to move with the engine's bookkeeping,
+// plus the history the terminals keep in playMove.
+func play(s gameState, m engine.Move) (gameState, error) {
+	seat := s.PlayerTurn
+	g, err := engine.Game{State: position(s), Moves: s.Moves, Repetitions: s.Repetitions}.Play(m)
+	if err != nil {
+		return s, err
+	}
+	s.Board, s.PlayerTurn, s.Moves, s.Winner, s.Repetitions = g.Board, g.Turn, g.Moves, g.Winner, g.Repetitions
+	s.History = append(s.History, historyEntry{Player: seat, Move: m, Time: time.Now().UTC()})
+	if g.Result != nil {
+		s.Result = &result{Outcome: g.Result.Outcome(), Termination: g.Result.Termination}
+	}
+	return s, nil
+}
+
+// update writes the fields a move changes into the canonical state, keeping
+// every other field as the terminals sent it.
+func update(payload []byte, s gameState) ([]byte, error) {
+	return wire.UpdateState(payload, map[string]any{
+		"Board": s.Board, "PlayerTurn": s.PlayerTurn, "Moves": s.Moves, "Result": s.Result,
+		"Winner": s.Winner, "Repetitions": s.Repetitions, "History": s.History,
+	})
+}
+
+// loadSigningKey reads the referee's signing key, creating it on first run.
+// Keep it across runs: the players' referee_key names its public half.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	seed, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		seed = make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(path, seed, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s is not a signing key", path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// connect opens a TLS connection to the broker with the referee's
+// certificates.
+func connect(broker, caFile, certFile, keyFile, clientID string) (mqtt.Client, error) {
+	pemCerts, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	certpool := x509.NewCertPool()
+	certpool.AppendCertsFromPEM(pemCerts)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(clientID).
+		SetTLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: certpool}).
+		SetKeepAlive(30 * time.Second).
+		SetPingTimeout(20 * time.Second).
+		SetAutoReconnect(true)
+	client := mqtt.NewClient(opts)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	return client, nil
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"goblets/engine"
+	"strings"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+type published struct {
+	topic   string
+	payload []byte
+}
+
+// recordingClient records what the referee publishes.
+type recordingClient struct {
+	mqtt.Client
+	published []published
+}
+
+func (c *recordingClient) Publish(topic string, qos byte, retained bool, payload any) mqtt.Token {
+	c.published = append(c.published, published{topic, payload.([]byte)})
+	return doneToken{}
+}
+
+type doneToken struct{}
+
+func (doneToken) Wait() bool                     { return true }
+func (doneToken) WaitTimeout(time.Duration) bool { return true }
+func (doneToken) Error() error                   { return nil }
+func (doneToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+func newTestReferee(t *testing.T) (*referee, *recordingClient) {
+	t.Helper()
+	_, key, _ := ed25519.GenerateKey(nil)
+	client := &recordingClient{}
+	return newReferee(client, key), client
+}
+
+// newGame is the first state a terminal publishes for a refereed game.
+func newGame(t *testing.T) []byte {
+	t.Helper()
+	s := gameState{Board: engine.NewBoard(3), PlayerTurn: 1, Variant: engine.Junior, Sender: "terminal"}
+	s.Meta.Refereed = true
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// lastState returns the state the referee published last.
+func lastState(t *testing.T, c *recordingClient) gameState {
+	t.Helper()
+	for i := len(c.published) - 1; i >= 0; i-- {
+		if strings.HasSuffix(c.published[i].topic, "/state") {
+			var s gameState
+			if err := json.Unmarshal(c.published[i].payload, &s); err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}
+	}
+	t.Fatal("no state was published")
+	return gameState{}
+}
+
+// seat puts a device with the given key in a seat, as takeSeat and
+// claimSeat do on a terminal.
+func seat(t *testing.T, r *referee, id string, player int, key ed25519.PrivateKey) {
+	t.Helper()
+	device := "device-" + string(rune('0'+player))
+	doc := map[int]seatHolder{}
+	for p, h := range r.seating(id).holders {
+		doc[p] = h
+	}
+	doc[player] = seatHolder{Device: device, Session: device + "-session", Name: "Student " + string(rune('0'+player))}
+	data, _ := json.Marshal(doc)
+	r.onSeats(id, data)
+
+	c := seatClaim{GameID: id, Player: player, Device: device, Session: device + "-session", Time: time.Now().UTC(), PublicKey: key.Public().(ed25519.PublicKey)}
+	signed, _ := json.Marshal(c)
+	c.Signature = ed25519.Sign(key, signed)
+	data, _ = json.Marshal(c)
+	r.onClaim(id, data)
+}
+
+// move builds a placement delta signed with key, as signMove does.
+func move(t *testing.T, player, seq int, to string, key ed25519.PrivateKey) []byte {
+	t.Helper()
+	return signedMove(t, moveMessage{Type: "place", To: to, Size: 1, Player: player, Seq: seq}, key)
+}
+
+func signedMove(t *testing.T, mm moveMessage, key ed25519.PrivateKey) []byte {
+	t.Helper()
+	data, _ := json.Marshal(mm)
+	doc, _, err := canonical(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	fields["Signature"] = ed25519.Sign(key, doc)
+	data, _ = json.Marshal(fields)
+	return data
+}
+
+func TestSameBoard(t *testing.T) {
+	var canonical gameState
+	json.Unmarshal(newGame(t), &canonical)
+
+	for name, change := range map[string]func(*gameState){
+		"board":   func(s *gameState) { s.Board[1][1] = engine.Stack{{Owner: 1, Size: 3}} },
+		"moves":   func(s *gameState) { s.Moves = 4 },
+		"turn":    func(s *gameState) { s.PlayerTurn = 2 },
+		"rules":   func(s *gameState) { s.Rules = &engine.Rules{Size: 3, Reserves: 1} },
+		"variant": func(s *gameState) { s.Variant = engine.Classic },
+		"result":  func(s *gameState) { s.Result = &result{Outcome: "1-0", Termination: "line"} },
+	} {
+		var s gameState
+		data, _ := json.Marshal(canonical)
+		json.Unmarshal(data, &s)
+		change(&s)
+		if sameBoard(canonical, s) == nil {
+			t.Errorf("a state changing the %s was passed on", name)
+		}
+	}
+
+	resigned := canonical
+	resigned.Result, resigned.Winner = &result{Outcome: "0-1", Termination: "resignation"}, 2
+	if err := sameBoard(canonical, resigned); err != nil {
+		t.Errorf("a resignation was refused: %v", err)
+	}
+}
+
+func TestOnStateAdoptsOnlyFreshGames(t *testing.T) {
+	r, client := newTestReferee(t)
+
+	var s gameState
+	json.Unmarshal(newGame(t), &s)
+	s.Moves, s.PlayerTurn = 3, 2
+	s.Board[0][0] = engine.Stack{{Owner: 1, Size: 3}}
+	data, _ := json.Marshal(s)
+	r.onState("midgame", data)
+	if r.games["midgame"] != nil || len(client.published) != 0 {
+		t.Error("adopted a game that started before the referee saw it")
+	}
+
+	r.onState("fresh", newGame(t))
+	if r.games["fresh"] == nil {
+		t.Fatal("didn't adopt a new game")
+	}
+	if got := lastState(t, client); got.Sender != sender || !r.signed(client.published[len(client.published)-1].payload) {
+		t.Errorf("the adopted game wasn't republished signed, got Sender %q", got.Sender)
+	}
+}
+
+func TestOnStateIgnoresForgedRefereeStates(t *testing.T) {
+	r, client := newTestReferee(t)
+	r.onState("g", newGame(t))
+	before := len(client.published)
+
+	var forged map[string]any
+	json.Unmarshal(client.published[before-1].payload, &forged)
+	forged["Seq"], forged["PlayerTurn"] = 99, 2
+	data, _ := json.Marshal(forged)
+	r.onState("g", data)
+
+	if g := r.games["g"]; g.s.Seq == 99 || g.s.PlayerTurn != 1 || len(client.published) != before {
+		t.Error("adopted a state in the referee's name that it didn't sign")
+	}
+}
+
+func TestOnMoveChecksSeatHolder(t *testing.T) {
+	_, alice, _ := ed25519.GenerateKey(nil)
+	_, bob, _ := ed25519.GenerateKey(nil)
+	_, mallory, _ := ed25519.GenerateKey(nil)
+	r, client := newTestReferee(t)
+	r.onState("g", newGame(t))
+	seat(t, r, "g", 1, alice)
+	seat(t, r, "g", 2, bob)
+
+	r.onMove("g", move(t, 1, 1, "a1", mallory))
+	if r.games["g"].s.Moves != 0 {
+		t.Fatal("played a move signed by someone outside the seat")
+	}
+	if last := client.published[len(client.published)-2]; !strings.HasSuffix(last.topic, "/control") {
+		t.Errorf("a forged move wasn't rejected on /control, got %s", last.topic)
+	}
+
+	r.onMove("g", move(t, 1, 1, "a1", alice))
+	if got := lastState(t, client); got.Moves != 1 || got.PlayerTurn != 2 {
+		t.Fatalf("the seat holder's move wasn't played: move %d, turn %d", got.Moves, got.PlayerTurn)
+	}
+
+	r.onMove("g", move(t, 2, 2, "b2", alice))
+	if r.games["g"].s.Moves != 1 {
+		t.Error("Player 1's key moved for Player 2")
+	}
+
+	// A later claim with another key doesn't take over a seat
+	seat(t, r, "g", 2, mallory)
+	r.onMove("g", move(t, 2, 2, "b2", mallory))
+	if r.games["g"].s.Moves != 1 {
+		t.Error("a second claim took over the seat")
+	}
+	r.onMove("g", move(t, 2, 2, "b2", bob))
+	if r.games["g"].s.Moves != 2 {
+		t.Error("the seat holder's second move wasn't played")
+	}
+}
+
+func TestOrganization(t *testing.T) {
+	_, alice, _ := ed25519.GenerateKey(nil)
+	_, bob, _ := ed25519.GenerateKey(nil)
+	r, client := newTestReferee(t)
+	r.org = &organization{Name: "Class 3B", Roster: []student{{ID: "s1", Name: "Student 1"}}, AllowedPresets: []string{"kids-mode"}}
+
+	var s gameState
+	json.Unmarshal(newGame(t), &s)
+	s.Meta.Preset = "blitz"
+	data, _ := json.Marshal(s)
+	r.onState("blitz", data)
+	if r.games["blitz"] != nil {
+		t.Error("refereed a game with a preset the organization doesn't allow")
+	}
+
+	s.Meta.Preset = "kids-mode"
+	s.Meta.Players = []struct{ ID string }{{ID: "s1"}, {ID: "outsider"}}
+	data, _ = json.Marshal(s)
+	r.onState("outsider", data)
+	if r.games["outsider"] != nil {
+		t.Error("refereed a game naming a player who isn't on the roster")
+	}
+
+	s.Meta.Players = nil
+	data, _ = json.Marshal(s)
+	r.onState("g", data)
+	if r.games["g"] == nil {
+		t.Fatal("refused a game keeping to the organization's rules")
+	}
+	seat(t, r, "g", 1, alice)
+	seat(t, r, "g", 2, bob) // "Student 2" isn't on the roster
+	r.onMove("g", move(t, 1, 1, "a1", alice))
+	r.onMove("g", move(t, 2, 2, "b2", bob))
+	if got := r.games["g"].s.Moves; got != 1 {
+		t.Errorf("%d moves played, want only the one by the student on the roster", got)
+	}
+
+	var fields map[string]any
+	json.Unmarshal(client.published[len(client.published)-1].payload, &fields)
+	if fields["ChatOff"] != true {
+		t.Error("chat wasn't turned off for an organization without chat")
+	}
+}
+
+// signedState publishes s as seat, signed with key, as signState does.
+func signedState(t *testing.T, s gameState, seat int, key ed25519.PrivateKey) []byte {
+	t.Helper()
+	s.Seat, s.Sender, s.Seq = seat, "terminal", 0
+	data, _ := json.Marshal(s)
+	doc, _, err := canonical(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	json.Unmarshal(data, &fields)
+	fields["Signature"], _ = json.Marshal(ed25519.Sign(key, doc))
+	data, _ = json.Marshal(fields)
+	return data
+}
+
+func TestPlayerStates(t *testing.T) {
+	_, alice, _ := ed25519.GenerateKey(nil)
+	_, bob, _ := ed25519.GenerateKey(nil)
+	resign := func(seat int) gameState {
+		var s gameState
+		json.Unmarshal(newGame(t), &s)
+		s.Result, s.Winner = &result{Outcome: "0-1", Termination: "resignation"}, 2
+		if seat == 2 {
+			s.Result.Outcome, s.Winner = "1-0", 1
+		}
+		s.Control = &control{Action: "resign", Player: seat}
+		return s
+	}
+	offer := func(seat int) gameState {
+		var s gameState
+		json.Unmarshal(newGame(t), &s)
+		s.Control = &control{Action: "draw_offer", Player: seat}
+		return s
+	}
+	agree := func(seat int) gameState {
+		var s gameState
+		json.Unmarshal(newGame(t), &s)
+		s.Result = &result{Outcome: "½-½", Termination: "agreement"}
+		s.Control = &control{Action: "draw_accept", Player: seat}
+		return s
+	}
+	flagged := func() gameState {
+		var s gameState
+		json.Unmarshal(newGame(t), &s)
+		s.Result, s.Winner = &result{Outcome: "1-0", Termination: "time"}, 1
+		return s
+	}
+
+	for _, tc := range []struct {
+		name   string
+		states func(*testing.T) [][]byte
+		over   bool
+	}{
+		{"resigning your own seat", func(t *testing.T) [][]byte {
+			return [][]byte{signedState(t, resign(1), 1, alice)}
+		}, true},
+		{"resigning for the opponent", func(t *testing.T) [][]byte {
+			return [][]byte{signedState(t, resign(1), 2, bob)}
+		}, false},
+		{"resigning with the opponent's seat number", func(t *testing.T) [][]byte {
+			return [][]byte{signedState(t, resign(1), 1, bob)}
+		}, false},
+		{"unsigned resignation", func(t *testing.T) [][]byte {
+			data, _ := json.Marshal(resign(1))
+			return [][]byte{data}
+		}, false},
+		{"claiming the opponent's flag", func(t *testing.T) [][]byte {
+			return [][]byte{signedState(t, flagged(), 1, alice)}
+		}, false},
+		{"agreeing to an offer", func(t *testing.T) [][]byte {
+			return [][]byte{signedState(t, offer(1), 1, alice), signedState(t, agree(2), 2, bob)}
+		}, true},
+		{"agreeing to your own offer", func(t *testing.T) [][]byte {
+			return [][]byte{signedState(t, offer(1), 1, alice), signedState(t, agree(1), 1, alice)}
+		}, false},
+		{"agreeing without an offer", func(t *testing.T) [][]byte {
+			return [][]byte{signedState(t, agree(2), 2, bob)}
+		}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _ := newTestReferee(t)
+			r.onState("g", newGame(t))
+			seat(t, r, "g", 1, alice)
+			seat(t, r, "g", 2, bob)
+			for _, data := range tc.states(t) {
+				r.onState("g", data)
+			}
+			if over := r.games["g"].s.Result != nil; over != tc.over {
+				t.Errorf("game over %v, want %v", over, tc.over)
+			}
+		})
+	}
+}
+
+func TestRefereeClocks(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = time.Now })
+
+	_, alice, _ := ed25519.GenerateKey(nil)
+	_, bob, _ := ed25519.GenerateKey(nil)
+	r, client := newTestReferee(t)
+	var s gameState
+	json.Unmarshal(newGame(t), &s)
+	s.Meta.Rules.TimeControl = 60
+	data, _ := json.Marshal(s)
+	r.onState("g", data)
+	seat(t, r, "g", 1, alice)
+	seat(t, r, "g", 2, bob)
+
+	r.onMove("g", move(t, 1, 1, "a1", alice))
+	clock = clock.Add(20 * time.Second)
+	// Player 2 claims to have used no time
+	claimed, _ := json.Marshal(clocks{Remaining: [2]time.Duration{time.Minute, time.Minute}})
+	r.onMove("g", signedMove(t, moveMessage{Type: "place", To: "b2", Size: 1, Player: 2, Seq: 2, Clocks: claimed}, bob))
+
+	var published struct{ Clocks clocks }
+	json.Unmarshal(client.published[len(client.published)-1].payload, &published)
+	if got := published.Clocks.Remaining; got != [2]time.Duration{time.Minute, 40 * time.Second} {
+		t.Errorf("clocks %v, want Player 2 charged the 20s the move took to reach the referee", got)
+	}
+
+	clock = clock.Add(59 * time.Second)
+	r.checkTime()
+	if r.games["g"].s.Result != nil {
+		t.Fatal("flagged Player 1 with a second left")
+	}
+	clock = clock.Add(time.Second)
+	r.checkTime()
+	if res := r.games["g"].s.Result; res == nil || res.Termination != "time" || r.games["g"].s.Winner != 2 {
+		t.Errorf("Player 1 ran out of time, got %+v", res)
+	}
+}
@@ -0,0 +1,89 @@
+//go:build js && wasm
+
+// Command goblet-wasm compiles the rules engine to WebAssembly for browser
+// clients, so they can check moves locally before sending them. It sets a
+// global gobblet object with three functions, each taking a position string
+// (see engine.EncodePosition) and returning a plain object:
+//
+//	gobblet.legalMoves("Ab,.,./.,C,./.,.,a 2")  // {moves: ["P a1 M", ...]}
+//	gobblet.applyMove(position, "M b2 c3")     // {position, winner}
+//	gobblet.checkResult(position)              // {winner, stalemate}
+//
+// Failures come back as {error: "..."} rather than exceptions.
+//
+//	GOOS=js GOARCH=wasm go build -o gobblet.wasm ./cmd/goblet-wasm
+package main
+
+import (
+	"errors"
+	"goblets/engine"
+	"goblets/notation"
+	"syscall/js"
+)
+
+func main() {
+	js.Global().Set("gobblet", js.ValueOf(map[string]any{
+		"legalMoves":  js.FuncOf(legalMoves),
+		"applyMove":   js.FuncOf(applyMove),
+		"checkResult": js.FuncOf(checkResult),
+	}))
+	select {} // keep the functions callable
+}
+
+// legalMoves lists the moves the player to move can make, in notation.
+func legalMoves(this js.Value, args []js.Value) any {
+	s, err := position(args, 1)
+	if err != nil {
+		return failure(err)
+	}
+	moves := []any{}
+	for _, m := range engine.LegalMoves(s, s.Turn) {
+		moves = append(moves, notation.Format(m))
+	}
+	return map[string]any{"moves": moves}
+}
+
+// applyMove plays a move given in notation and returns the new position.
+func applyMove(this js.Value, args []js.Value) any {
+	s, err := position(args, 2)
+	if err != nil {
+		return failure(err)
+	}
+	m, err := notation.Parse(args[1].String())
+	if err != nil {
+		return failure(err)
+	}
+	next, err := s.Apply(m)
+	if err != nil {
+		return failure(err)
+	}
+	return map[string]any{"position": engine.EncodePosition(next), "winner": next.Winner}
+}
+
+// checkResult reports the winner, 0 for none, and whether the player to
+// move is stuck.
+func checkResult(this js.Value, args []js.Value) any {
+	s, err := position(args, 1)
+	if err != nil {
+		return failure(err)
+	}
+	return map[string]any{"winner": s.Winner, "stalemate": s.Stalemate()}
+}
+
+// position reads and checks the position string every function starts with.
+func position(args []js.Value, want int) (engine.State, error) {
+	if len(args) < want || args[0].Type() != js.TypeString {
+		return engine.State{}, errUsage
+	}
+	s, err := engine.DecodePosition(args[0].String())
+	if err != nil {
+		return engine.State{}, err
+	}
+	return s, engine.Validate(s)
+}
+
+var errUsage = errors.New("expected a position string and, for applyMove, a move")
+
+func failure(err error) any {
+	return map[string]any{"error": err.Error()}
+}
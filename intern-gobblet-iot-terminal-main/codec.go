@@ -0,0 +1,535 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"goblets/engine"
+	"goblets/wire"
+	"slices"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Wire formats for game states and move deltas, set by wire_format and
+// negotiated with the other sessions of the game. Receivers read all of
+// them, whatever their own setting.
+const (
+	wireJSON     = "json"
+	wireProtobuf = "protobuf"
+	wireCBOR     = "cbor"
+)
+
+// readCodecs are the formats this terminal reads, announced in presence,
+// in the order they are fallen back to.
+var readCodecs = []string{wireJSON, wireCBOR, wireProtobuf}
+
+// contentTypes mark each format on MQTT 5 publishes.
+var contentTypes = map[string]string{
+	wireJSON:     "application/json",
+	wireProtobuf: "application/x-protobuf",
+	wireCBOR:     "application/cbor",
+}
+
+var (
+	codecMu   sync.Mutex
+	codecUsed string // last format negotiated, to report changes
+)
+
+// wireCodec picks the format to publish in: wire_format when every other
+// online session and seat holder reads it, or else the first format they
+// all do. The goblet-referee reads JSON only, so refereed games stay on
+// JSON.
+func wireCodec(meta GameMeta) string {
+	if meta.Refereed {
+		return wireJSON
+	}
+	want := config.Conf.WireFormat
+	if !slices.Contains(readCodecs, want) {
+		want = wireJSON
+	}
+	peers := peerCodecs()
+	codec := ""
+	for _, c := range append([]string{want}, readCodecs...) {
+		if slices.IndexFunc(peers, func(p []string) bool { return !slices.Contains(p, c) }) < 0 {
+			codec = c
+			break
+		}
+	}
+
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	switch {
+	case codec == "":
+		if codecUsed != "none" {
+			fmt.Println("\n⚠ The clients in this game share no payload format; publishing JSON")
+			codecUsed = "none"
+		}
+	case codec == want:
+		codecUsed = ""
+	case codec != codecUsed:
+		fmt.Printf("\n🔤 Publishing %s instead of %s so every client in the game can read it\n", codec, want)
+		codecUsed = codec
+	}
+	return cmp.Or(codec, wireJSON)
+}
+
+// payloadCodec tells the format of a payload from its first byte: JSON
+// starts with '{' or '[', CBOR with a map, and protobuf with a field tag
+// that is neither.
+func payloadCodec(payload []byte) string {
+	switch {
+	case len(payload) == 0 || payload[0] == '{' || payload[0] == '[':
+		return wireJSON
+	case wire.IsCBORMap(payload):
+		return wireCBOR
+	}
+	return wireProtobuf
+}
+
+// jsonPayload reports whether payload is JSON.
+func jsonPayload(payload []byte) bool {
+	return len(payload) > 0 && payloadCodec(payload) == wireJSON
+}
+
+// payloadText is a payload as it is shown in the log.
+func payloadText(payload []byte) string {
+	if codec := payloadCodec(payload); codec != wireJSON {
+		return fmt.Sprintf("(%d bytes of %s)", len(payload), codec)
+	}
+	return string(payload)
+}
+
+// publishPayload publishes data marked with its format: by its content type
+// over MQTT 5, and over MQTT 3.1.1 by a /cbor suffix on the topic of a CBOR
+// message, so a board can subscribe to the messages it can read. Retained
+// states stay on their topic whatever the format.
+func publishPayload(topic string, qos byte, retained bool, data []byte) mqtt.Token {
+	codec := payloadCodec(data)
+	if c, ok := mqttClient.(*v5Client); ok {
+		return c.PublishTyped(topic, qos, retained, data, contentTypes[codec])
+	}
+	if codec == wireCBOR && !retained {
+		topic += "/" + wireCBOR
+	}
+	return mqttClient.Publish(topic, qos, retained, data)
+}
+
+// decodeDoc reads a JSON or CBOR document, for messages that have no
+// protobuf schema.
+func decodeDoc(payload []byte, v any) error {
+	if payloadCodec(payload) == wireCBOR {
+		return wire.UnmarshalCBOR(payload, v)
+	}
+	return json.Unmarshal(payload, v)
+}
+
+// encodeState serializes a game state in the negotiated wire format.
+func encodeState(state GameState) []byte {
+	switch wireCodec(state.Meta) {
+	case wireProtobuf:
+		if data, err := proto.Marshal(toWireState(state)); err == nil {
+			return data
+		}
+	case wireCBOR:
+		if data, err := wire.MarshalCBOR(state); err == nil {
+			return data
+		}
+	}
+	data, _ := json.Marshal(state)
+	return data
+}
+
+// decodeState reads a game state in any wire format.
+func decodeState(payload []byte, state *GameState) error {
+	if payloadCodec(payload) != wireProtobuf {
+		return decodeDoc(payload, state)
+	}
+	var w wire.GameState
+	if err := proto.Unmarshal(payload, &w); err != nil {
+		return err
+	}
+	return fromWireState(&w, state)
+}
+
+// encodeMove serializes a move delta in the negotiated wire format.
+func encodeMove(mm MoveMessage) []byte {
+	switch wireCodec(gameMeta) {
+	case wireProtobuf:
+		if data, err := proto.Marshal(toWireMove(mm)); err == nil {
+			return data
+		}
+	case wireCBOR:
+		if data, err := wire.MarshalCBOR(mm); err == nil {
+			return data
+		}
+	}
+	data, _ := json.Marshal(mm)
+	return data
+}
+
+// decodeMove reads a move delta in any wire format.
+func decodeMove(payload []byte, mm *MoveMessage) error {
+	if payloadCodec(payload) != wireProtobuf {
+		return decodeDoc(payload, mm)
+	}
+	var w wire.MoveMessage
+	if err := proto.Unmarshal(payload, &w); err != nil {
+		return err
+	}
+	*mm = MoveMessage{
+		Type:   w.Type,
+		From:   w.From,
+		To:     w.To,
+		Size:   int(w.Size),
+		Player: int(w.Player),
+		Seq:    int(w.Seq),
+		Time:   fromWireTime(w.Time),
+		Clocks: fromWireClocks(w.Clocks),
+	}
+	return nil
+}
+
+func toWireMove(mm MoveMessage) *wire.MoveMessage {
+	return &wire.MoveMessage{
+		Type:   mm.Type,
+		From:   mm.From,
+		To:     mm.To,
+		Size:   int32(mm.Size),
+		Player: int32(mm.Player),
+		Seq:    int32(mm.Seq),
+		Time:   wireTime(mm.Time),
+		Clocks: wireClocks(mm.Clocks),
+	}
+}
+
+func toWireState(s GameState) *wire.GameState {
+	w := &wire.GameState{
+		Board:      wireBoard(s.Board),
+		PlayerTurn: int32(s.PlayerTurn),
+		Winner:     int32(s.Winner),
+		Moves:      int32(s.Moves),
+		Paused:     s.Paused,
+		Stats: &wire.Stats{
+			Landings:  wireCounts(s.Stats.Landings),
+			Gobbles:   wireCounts(s.Stats.Gobbles),
+			SizeUsage: wireCounts(s.Stats.SizeUsage[:]),
+		},
+		Clocks:    wireClocks(s.Clocks),
+		Draw:      s.Draw,
+		Variant:   string(s.Variant),
+		RulesHash: s.RulesHash,
+		Seq:       s.Seq,
+		Sender:    s.Sender,
+		Meta:      wireMeta(s.Meta),
+		Rules:     wireRules(s.Rules),
+		Ruling:    wireAudit(s.Ruling),
+		ChatOff:   s.ChatOff,
+		Seat:      int32(s.Seat),
+	}
+	if s.Result != nil {
+		w.Result = &wire.Result{Outcome: string(s.Result.Outcome), Termination: string(s.Result.Termination)}
+	}
+	if len(s.Repetitions) > 0 {
+		w.Repetitions = make(map[string]int32, len(s.Repetitions))
+		for hash, n := range s.Repetitions {
+			w.Repetitions[hash] = int32(n)
+		}
+	}
+	for _, e := range s.History {
+		w.History = append(w.History, &wire.HistoryEntry{Player: int32(e.Player), Move: wireMove(e.Move), Undo: e.Undo, Time: wireTime(e.Time)})
+	}
+	if s.Control != nil {
+		w.Control = &wire.Control{Action: s.Control.Action, Player: int32(s.Control.Player)}
+	}
+	return w
+}
+
+func fromWireState(w *wire.GameState, s *GameState) error {
+	*s = GameState{
+		Board:      fromWireBoard(w.Board),
+		PlayerTurn: int(w.PlayerTurn),
+		Winner:     int(w.Winner),
+		Moves:      int(w.Moves),
+		Paused:     w.Paused,
+		Clocks:     fromWireClocks(w.Clocks),
+		Draw:       w.Draw,
+		Variant:    engine.Variant(w.Variant),
+		RulesHash:  w.RulesHash,
+		Seq:        w.Seq,
+		Sender:     w.Sender,
+		Meta:       fromWireMeta(w.Meta),
+		Rules:      fromWireRules(w.Rules),
+		Ruling:     fromWireAudit(w.Ruling),
+		ChatOff:    w.ChatOff,
+		Seat:       int(w.Seat),
+	}
+	if w.Stats != nil {
+		s.Stats.Landings = fromWireCounts(w.Stats.Landings)
+		s.Stats.Gobbles = fromWireCounts(w.Stats.Gobbles)
+		copy(s.Stats.SizeUsage[:], fromWireCounts(w.Stats.SizeUsage))
+	}
+	if w.Result != nil {
+		s.Result = &Result{Outcome: Outcome(w.Result.Outcome), Termination: Termination(w.Result.Termination)}
+	}
+	if len(w.Repetitions) > 0 {
+		s.Repetitions = make(map[string]int, len(w.Repetitions))
+		for hash, n := range w.Repetitions {
+			s.Repetitions[hash] = int(n)
+		}
+	}
+	for _, e := range w.History {
+		s.History = append(s.History, HistoryEntry{Player: int(e.Player), Move: fromWireMove(e.Move), Undo: e.Undo, Time: fromWireTime(e.Time)})
+	}
+	if w.Control != nil {
+		s.Control = &Control{Action: w.Control.Action, Player: int(w.Control.Player)}
+	}
+	return nil
+}
+
+func wireMeta(m GameMeta) *wire.GameMeta {
+	w := &wire.GameMeta{
+		Preset: m.Preset,
+		Rules: &wire.PresetRules{
+			BoardSize:   int32(m.Rules.BoardSize),
+			TimeControl: int32(m.Rules.TimeControl),
+			Assists: &wire.Assists{
+				Takebacks:      m.Rules.Assists.Takebacks,
+				ThreatWarnings: m.Rules.Assists.ThreatWarnings,
+				SimpleMessages: m.Rules.Assists.SimpleMessages,
+				LargeGlyphs:    m.Rules.Assists.LargeGlyphs,
+				Hints:          m.Rules.Assists.Hints,
+			},
+		},
+		Rated:       m.Rated,
+		Title:       m.Title,
+		Tags:        m.Tags,
+		FirstPlayer: int32(m.FirstPlayer),
+		FirstChoice: m.FirstChoice,
+		Refereed:    m.Refereed,
+		Tournament:  m.Tournament,
+	}
+	for _, p := range m.Players {
+		w.Players = append(w.Players, &wire.PlayerIdentity{Seat: int32(p.Seat), Id: p.ID, Name: p.Name})
+	}
+	if len(m.Themes) > 0 {
+		w.Themes = make(map[int32]string, len(m.Themes))
+		for seat, theme := range m.Themes {
+			w.Themes[int32(seat)] = theme
+		}
+	}
+	return w
+}
+
+func fromWireMeta(w *wire.GameMeta) GameMeta {
+	if w == nil {
+		return GameMeta{}
+	}
+	m := GameMeta{
+		Preset:      w.Preset,
+		Rated:       w.Rated,
+		Title:       w.Title,
+		Tags:        w.Tags,
+		FirstPlayer: int(w.FirstPlayer),
+		FirstChoice: w.FirstChoice,
+		Refereed:    w.Refereed,
+		Tournament:  w.Tournament,
+	}
+	if r := w.Rules; r != nil {
+		m.Rules = Rules{BoardSize: int(r.BoardSize), TimeControl: int(r.TimeControl)}
+		if a := r.Assists; a != nil {
+			m.Rules.Assists = Assists{
+				Takebacks:      a.Takebacks,
+				ThreatWarnings: a.ThreatWarnings,
+				SimpleMessages: a.SimpleMessages,
+				LargeGlyphs:    a.LargeGlyphs,
+				Hints:          a.Hints,
+			}
+		}
+	}
+	for _, p := range w.Players {
+		m.Players = append(m.Players, PlayerIdentity{Seat: int(p.Seat), ID: p.Id, Name: p.Name})
+	}
+	if len(w.Themes) > 0 {
+		m.Themes = make(map[int]string, len(w.Themes))
+		for seat, theme := range w.Themes {
+			m.Themes[int(seat)] = theme
+		}
+	}
+	return m
+}
+
+func wireRules(r *engine.Rules) *wire.Rules {
+	if r == nil {
+		return nil
+	}
+	return &wire.Rules{
+		Size:            int32(r.Size),
+		Sizes:           int32(r.Sizes),
+		Reserves:        int32(r.Reserves),
+		EntryOnEmpty:    r.EntryOnEmpty,
+		CoverReveal:     r.CoverReveal,
+		Memory:          r.Memory,
+		NoReserveGobble: r.NoReserveGobble,
+		GobbleOnThreat:  r.GobbleOnThreat,
+	}
+}
+
+func fromWireRules(w *wire.Rules) *engine.Rules {
+	if w == nil {
+		return nil
+	}
+	return &engine.Rules{
+		Size:            int(w.Size),
+		Sizes:           int(w.Sizes),
+		Reserves:        int(w.Reserves),
+		EntryOnEmpty:    w.EntryOnEmpty,
+		CoverReveal:     w.CoverReveal,
+		Memory:          w.Memory,
+		NoReserveGobble: w.NoReserveGobble,
+		GobbleOnThreat:  w.GobbleOnThreat,
+	}
+}
+
+func wireAudit(e *AuditEntry) *wire.AuditEntry {
+	if e == nil {
+		return nil
+	}
+	return &wire.AuditEntry{
+		Time:      wireTime(e.Time),
+		Role:      e.Role,
+		Action:    e.Action,
+		Text:      e.Text,
+		GameId:    e.GameID,
+		Moves:     int32(e.Moves),
+		PublicKey: e.PublicKey,
+		Signature: e.Signature,
+	}
+}
+
+func fromWireAudit(w *wire.AuditEntry) *AuditEntry {
+	if w == nil {
+		return nil
+	}
+	return &AuditEntry{
+		Time:      fromWireTime(w.Time),
+		Role:      w.Role,
+		Action:    w.Action,
+		Text:      w.Text,
+		GameID:    w.GameId,
+		Moves:     int(w.Moves),
+		PublicKey: w.PublicKey,
+		Signature: w.Signature,
+	}
+}
+
+func wireBoard(b Board) []*wire.Row {
+	rows := make([]*wire.Row, len(b))
+	for i, row := range b {
+		rows[i] = &wire.Row{Cells: make([]*wire.Stack, len(row))}
+		for j, stack := range row {
+			cell := &wire.Stack{}
+			for _, g := range stack {
+				cell.Pieces = append(cell.Pieces, &wire.Piece{Size: int32(g.Size), Owner: int32(g.Owner)})
+			}
+			rows[i].Cells[j] = cell
+		}
+	}
+	return rows
+}
+
+func fromWireBoard(rows []*wire.Row) Board {
+	if len(rows) == 0 {
+		return nil // boardOf fills in an empty board
+	}
+	b := make(Board, len(rows))
+	for i, row := range rows {
+		b[i] = make([]engine.Stack, len(row.Cells))
+		for j, cell := range row.Cells {
+			for _, p := range cell.Pieces {
+				b[i][j] = append(b[i][j], engine.Gobblet{Size: int(p.Size), Owner: int(p.Owner)})
+			}
+		}
+	}
+	return b
+}
+
+func wireMove(m engine.Move) *wire.Move {
+	return &wire.Move{FromRow: int32(m.FromRow), FromCol: int32(m.FromCol), Row: int32(m.Row), Col: int32(m.Col), Size: int32(m.Size)}
+}
+
+func fromWireMove(m *wire.Move) engine.Move {
+	if m == nil {
+		return engine.Move{}
+	}
+	return engine.Move{FromRow: int(m.FromRow), FromCol: int(m.FromCol), Row: int(m.Row), Col: int(m.Col), Size: int(m.Size)}
+}
+
+func wireCounts(grid [][]int) []*wire.Counts {
+	counts := make([]*wire.Counts, len(grid))
+	for i, row := range grid {
+		counts[i] = &wire.Counts{Values: make([]int32, len(row))}
+		for j, n := range row {
+			counts[i].Values[j] = int32(n)
+		}
+	}
+	return counts
+}
+
+func fromWireCounts(counts []*wire.Counts) [][]int {
+	if len(counts) == 0 {
+		return nil
+	}
+	grid := make([][]int, len(counts))
+	for i, row := range counts {
+		grid[i] = make([]int, len(row.Values))
+		for j, n := range row.Values {
+			grid[i][j] = int(n)
+		}
+	}
+	return grid
+}
+
+func wireClocks(c *Clocks) *wire.Clocks {
+	if c == nil {
+		return nil
+	}
+	return &wire.Clocks{
+		Remaining: []*durationpb.Duration{durationpb.New(c.Remaining[0]), durationpb.New(c.Remaining[1])},
+		TurnStart: wireTime(c.TurnStart),
+	}
+}
+
+func fromWireClocks(w *wire.Clocks) *Clocks {
+	if w == nil {
+		return nil
+	}
+	c := &Clocks{TurnStart: fromWireTime(w.TurnStart)}
+	for i, d := range w.Remaining {
+		if i < len(c.Remaining) {
+			c.Remaining[i] = d.AsDuration()
+		}
+	}
+	return c
+}
+
+// wireTime leaves a zero time out.
+func wireTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromWireTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// How the first player of a game was chosen, recorded in GameMeta.
+const (
+	firstByCreator  = "creator"
+	firstByCoinFlip = "coin flip"
+)
+
+// The coin flip is a commit-reveal exchange so neither side can cheat:
+// Player 1 commits to a secret, Player 2 answers with its own secret in the
+// clear, then Player 1 reveals. The low bit of the XOR of the first bytes
+// picks who moves first; Player 2 checks the reveal against the commitment.
+
+func flipTopic(part string) string {
+	return "gobblet/game/" + gameID + "/flip/" + part
+}
+
+// awaitRetained returns the first non-empty message on topic.
+func awaitRetained(topic string, timeout time.Duration) ([]byte, error) {
+	ch := make(chan []byte, 1)
+	token := mqttClient.Subscribe(topic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		if len(msg.Payload()) == 0 {
+			return
+		}
+		select {
+		case ch <- msg.Payload():
+		default:
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	defer mqttClient.Unsubscribe(topic)
+
+	select {
+	case payload := <-ch:
+		return payload, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("timed out waiting on %s", topic)
+	}
+}
+
+func flipWinner(secret1, secret2 []byte) int {
+	return 1 + int((secret1[0]^secret2[0])&1)
+}
+
+func publishRetained(topic string, data []byte) error {
+	token := mqttClient.Publish(topic, 1, true, data)
+	token.Wait()
+	return token.Error()
+}
+
+// runCoinFlip decides who moves first in a coin-flip game. Player 1 then
+// publishes the game state with the winner to move.
+func runCoinFlip() {
+	var err error
+	if playerID == 1 {
+		err = flipAsPlayer1()
+	} else {
+		err = flipAsPlayer2()
+	}
+	if err != nil {
+		fmt.Println("❌ Coin flip failed:", err)
+		os.Exit(1)
+	}
+}
+
+func flipAsPlayer1() error {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	commitment := sha256.Sum256(secret)
+	if err := publishRetained(flipTopic("commit"), commitment[:]); err != nil {
+		return err
+	}
+
+	fmt.Println("🪙 Waiting for Player 2 to join for the coin flip...")
+	theirs, err := awaitRetained(flipTopic("reveal2"), 10*time.Minute)
+	if err != nil {
+		return err
+	}
+	if err := publishRetained(flipTopic("reveal1"), secret); err != nil {
+		return err
+	}
+
+	first := flipWinner(secret, theirs)
+	mu.Lock()
+	playerTurn = first
+	gameMeta.FirstPlayer = first
+	mu.Unlock()
+	fmt.Printf("🪙 Coin flip: Player %d moves first\n", first)
+	saveGameState()
+	return nil
+}
+
+func flipAsPlayer2() error {
+	commitment, err := awaitRetained(flipTopic("commit"), time.Minute)
+	if err != nil {
+		return err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	if err := publishRetained(flipTopic("reveal2"), secret); err != nil {
+		return err
+	}
+
+	theirs, err := awaitRetained(flipTopic("reveal1"), time.Minute)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(theirs)
+	if len(theirs) == 0 || !bytes.Equal(sum[:], commitment) {
+		return fmt.Errorf("player 1's reveal does not match its commitment")
+	}
+
+	first := flipWinner(theirs, secret)
+	fmt.Printf("🪙 Coin flip: Player %d moves first (verified)\n", first)
+	for deadline := time.Now().Add(10 * time.Second); playerTurn == 0 && time.Now().Before(deadline); {
+		time.Sleep(200 * time.Millisecond)
+	}
+	if playerTurn != first {
+		return fmt.Errorf("player 1 started the game with Player %d to move, but the flip gave Player %d", playerTurn, first)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+// commands maps subcommand names to their entry points. Running without a
+// subcommand starts an interactive game.
+var commands = map[string]func(args []string){
+	"feed":         runFeed,
+	"loadtest":     runLoadtest,
+	"playback":     runPlayback,
+	"keys":         runKeys,
+	"games":        runGames,
+	"dispute":      runDispute,
+	"disputes":     runDisputes,
+	"explore":      runExplore,
+	"aggregate":    runAggregate,
+	"serve-api":    runServeAPI,
+	"quickplay":    runQuickplay,
+	"bot":          runBot,
+	"verify-cert":  runVerifyCert,
+	"bracket":      runBracket,
+	"league-flush": runLeagueFlush,
+	"rematch-from": runRematchFrom,
+	"backup":       runBackup,
+	"restore":      runRestore,
+	"event-schema": runEventSchema,
+	"selfplay":     runSelfPlay,
+	"classroom":    runClassroom,
+	"profile":      runProfile,
+	"report-bug":   runReportBug,
+	"uci":          runUCI,
+	"arena":        runArena,
+	"list":         runList,
+}
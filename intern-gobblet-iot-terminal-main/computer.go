@@ -0,0 +1,169 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"goblets/ai"
+	"goblets/bridge"
+	"goblets/config"
+	"goblets/engine"
+	"goblets/notation"
+	"os"
+	"sync"
+	"time"
+)
+
+// computerID is the seat prompt choice for playing against the built-in
+// computer. The local player takes seat 1 and the computer seat 2.
+const computerID = 5
+
+// computerSeat is the seat the computer plays, 0 when there is none.
+var computerSeat int
+
+// computerLimits returns the computer's strength from the ai config.
+func computerLimits() ai.Limits {
+	c := config.Conf.AI
+	l, ok := ai.Levels[c.Level]
+	if !ok {
+		fmt.Printf("⚠ Unknown ai.level %q, playing medium\n", c.Level)
+		l = ai.Levels["medium"]
+	}
+	switch c.Engine {
+	case ai.Minimax, ai.MCTS:
+		l.Engine = c.Engine
+	default:
+		fmt.Printf("⚠ Unknown ai.engine %q, using minimax\n", c.Engine)
+	}
+	if c.Playouts > 0 {
+		l.Playouts = c.Playouts
+	}
+	if c.Depth > 0 {
+		l.Depth = c.Depth
+	}
+	if c.TimeLimit > 0 {
+		l.Time = time.Duration(c.TimeLimit) * time.Millisecond
+	}
+	if c.BlunderPercent > 0 {
+		l.Blunder = float64(c.BlunderPercent) / 100
+	}
+	l.Book = openingBook()
+	return l
+}
+
+// computerMove asks the external engine for its move when there is one,
+// falling back to the built-in engine if it fails.
+func computerMove(s engine.State, limits ai.Limits, external *bridge.Engine) (engine.Move, bool) {
+	if external != nil {
+		m, err := external.BestMove(s, cmp.Or(limits.Time, time.Second))
+		if err == nil {
+			return m, true
+		}
+		fmt.Printf("\n⚠ %s failed (%v), playing the built-in engine's move\n", external.Name, err)
+	}
+	return ai.Choose(s, limits)
+}
+
+// startExternal starts the engine in ai.external, nil when there is none or
+// it won't start.
+func startExternal() *bridge.Engine {
+	if config.Conf.AI.External == "" {
+		return nil
+	}
+	e, err := bridge.Start(config.Conf.AI.External)
+	if err == nil {
+		err = e.NewGame()
+	}
+	if err != nil {
+		fmt.Println("⚠ Could not start the external engine, using the built-in one:", err)
+		return nil
+	}
+	fmt.Println("🖥 The computer's moves come from", e.Name)
+	return e
+}
+
+// runUCI serves the built-in engine over the external engine protocol on
+// stdin and stdout.
+func runUCI(args []string) {
+	fs := flag.NewFlagSet("uci", flag.ExitOnError)
+	level := fs.String("level", "medium", "easy, medium or hard")
+	fs.Parse(args)
+	l, ok := ai.Levels[*level]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "❌ Usage: uci [--level easy|medium|hard]")
+		os.Exit(1)
+	}
+	if err := bridge.Serve(os.Stdin, os.Stdout, "gobblet "+clientVersion+" "+*level, l); err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		os.Exit(1)
+	}
+}
+
+var (
+	bookOnce sync.Once
+	book     *ai.Book
+)
+
+// openingBook loads the book in ai.book once, nil when there is none.
+func openingBook() *ai.Book {
+	bookOnce.Do(func() {
+		path := config.Conf.AI.Book
+		if path == "" {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err == nil {
+			book, err = ai.ParseBook(data)
+		}
+		if err != nil {
+			fmt.Println("⚠ Could not load the opening book:", err)
+		}
+	})
+	return book
+}
+
+// startComputer seats the computer opposite the local player.
+func startComputer() {
+	playerID, computerSeat = 1, 2
+	fmt.Printf("🖥 You play Player 1 against the computer (%s).\n", config.Conf.AI.Level)
+	banter("start")
+	if gameMeta.FirstChoice == firstByCoinFlip && playerTurn == 0 {
+		go func() {
+			if err := flipAsPlayer2(); err != nil {
+				fmt.Println("❌ Computer coin flip failed:", err)
+			}
+		}()
+	}
+	go runComputer()
+}
+
+// runComputer plays the computer's moves through playMove, so they are
+// published like any other move and spectators can follow.
+func runComputer() {
+	limits := computerLimits()
+	external := startExternal()
+	if external != nil {
+		defer external.Close()
+	}
+	for {
+		mu.Lock()
+		ready := playerTurn == computerSeat && gameResult == nil && !paused
+		s := position(board.Clone(), playerTurn)
+		mu.Unlock()
+		if !ready {
+			time.Sleep(200 * time.Millisecond)
+			continue
+		}
+
+		m, ok := computerMove(s, limits, external)
+		if !ok {
+			return // stalemate ends the game
+		}
+		fmt.Println("\n🖥 Computer plays", notation.Format(m))
+		banterBefore(s, m)
+		if err := playMove(m); err != nil {
+			fmt.Println("❌ Computer move rejected:", moveErrorText(err))
+			return
+		}
+	}
+}
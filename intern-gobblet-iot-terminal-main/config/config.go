@@ -7,17 +7,258 @@ import (
 )
 
 type Config struct {
-	BrokerURL string `mapstructure:"broker_url"`
+	BrokerURL      string               `mapstructure:"broker_url"`
+	PlayerName     string               `mapstructure:"player_name"`
+	TraceFile      string               `mapstructure:"trace_file"` // record received messages here for playback
+	UpdateCheck    UpdateCheckConfig    `mapstructure:"update_check"`
+	StrictIdentity StrictIdentityConfig `mapstructure:"strict_identity"`
+	Privacy        PrivacyConfig        `mapstructure:"privacy"`
+	Aggregator     AggregatorConfig     `mapstructure:"aggregator"`
+	// OrderedDelivery wraps game states in sequenced envelopes so they are
+	// applied exactly once and in order.
+	OrderedDelivery bool `mapstructure:"ordered_delivery"`
+	// MoveDeltas publishes each move as a small message on the moves topic,
+	// with the full state only as an occasional retained snapshot.
+	MoveDeltas bool `mapstructure:"move_deltas"`
+	// WireFormat encodes published game states and moves as "json",
+	// "protobuf" (see wire/gobblet.proto) or "cbor", when the other clients
+	// in the game read it. All three are always read.
+	WireFormat string `mapstructure:"wire_format"`
+	// RefereeKey is the goblet-referee's public key (base64), which it
+	// prints on start. Refereed games only apply states it signed.
+	RefereeKey string `mapstructure:"referee_key"`
+	// ArbiterKey is the public key (base64, `keys export` on the
+	// arbiter's machine) dispute resolutions must be signed with.
+	ArbiterKey string          `mapstructure:"arbiter_key"`
+	API        APIConfig       `mapstructure:"api"`
+	Hooks      HooksConfig     `mapstructure:"hooks"`
+	Clock      ClockConfig     `mapstructure:"clock"`
+	Bandwidth  BandwidthConfig `mapstructure:"bandwidth"`
+	Device     DeviceConfig    `mapstructure:"device"`
+	League     LeagueConfig    `mapstructure:"league"`
+	Variants   VariantsConfig  `mapstructure:"variants"`
+	Backup     BackupConfig    `mapstructure:"backup"`
+	MQTT       MQTTConfig      `mapstructure:"mqtt"`
+	GameID     GameIDConfig    `mapstructure:"game_id"`
+	AI         AIConfig        `mapstructure:"ai"`
+	Analysis   AnalysisConfig  `mapstructure:"analysis"`
+	// Organization turns on classroom mode when it has a name.
+	Organization OrganizationConfig `mapstructure:"organization"`
+	// DuplicateSession decides what happens when you join your own seat
+	// twice: "takeover" moves it to the new session, "reject" keeps it.
+	DuplicateSession string `mapstructure:"duplicate_session"`
+	// Orientation turns this terminal's view of the board: "normal",
+	// "flipped" (180°), "left" or "right" (90°).
+	Orientation string `mapstructure:"orientation"`
+	// Variant is the rule set for new games: "junior" (3x3 Gobblet
+	// Gobblers) or "classic" (4x4 Gobblet).
+	Variant string `mapstructure:"variant"`
+	// BoardSize overrides the variant's board size for new games; lines
+	// must span the whole board. 0 keeps the variant's size.
+	BoardSize int `mapstructure:"board_size"`
+	// Theme is the unlocked piece theme to show in your games: "classic",
+	// "ember", "frost" or "gold".
+	Theme string `mapstructure:"theme"`
+	// Language picks the translations for localized texts: "en", "de" or
+	// "fr".
+	Language string `mapstructure:"language"`
+	// Animate briefly highlights each new move on the board: the path of a
+	// moved piece, and a flash when a piece gobbles another.
+	Animate bool `mapstructure:"animate"`
+}
+
+// VariantsConfig switches house rules on for new games. Joining players
+// play by the rules of the game, whatever their own settings.
+type VariantsConfig struct {
+	// Memory hides the pieces under each top piece from the players.
+	Memory bool `mapstructure:"memory"`
+	// NoReserveGobble stops new pieces from covering an opponent's piece.
+	NoReserveGobble bool `mapstructure:"no_reserve_gobble"`
+	// GobbleOnlyWhenThreatened only allows covering an opponent's piece in a
+	// line they are one short of.
+	GobbleOnlyWhenThreatened bool `mapstructure:"gobble_only_when_threatened"`
+}
+
+// DeviceConfig selects where the persistent device identity comes from:
+// "machine-id", "generated" (a secret in ~/.gobblet/device-id) or "auto".
+type DeviceConfig struct {
+	IDSource string `mapstructure:"id_source"`
+}
+
+// LeagueConfig sends finished games to an external league API. Template is
+// a Go text/template over the archive record; empty sends the record as JSON.
+type LeagueConfig struct {
+	URL        string `mapstructure:"url"`
+	AuthHeader string `mapstructure:"auth_header"` // e.g. "Authorization: Bearer <token>"
+	Template   string `mapstructure:"template"`
+	Retries    int    `mapstructure:"retries"`
+}
+
+// BackupConfig stores an encrypted copy of ~/.gobblet at URL, read with GET
+// and replaced with PUT (e.g. a pre-signed object storage URL).
+type BackupConfig struct {
+	URL        string `mapstructure:"url"`
+	AuthHeader string `mapstructure:"auth_header"` // e.g. "Authorization: Bearer <token>"
+	Passphrase string `mapstructure:"passphrase"`
+	AfterGame  bool   `mapstructure:"after_game"` // back up after every finished game
+}
+
+// OrganizationConfig is a classroom's roster and the limits its games are
+// played under. Clients and serve-api load the same block.
+type OrganizationConfig struct {
+	Name           string    `mapstructure:"name"`
+	Roster         []Student `mapstructure:"roster"`
+	AllowedPresets []string  `mapstructure:"allowed_presets"` // empty allows every preset
+	Chat           bool      `mapstructure:"chat"`
+	SessionMinutes int       `mapstructure:"session_minutes"` // 0 for no limit
+}
+
+// Student is one profile on the roster. Students play under their name.
+type Student struct {
+	ID   string `mapstructure:"id" json:"id"`
+	Name string `mapstructure:"name" json:"name"`
+}
+
+// MQTTConfig picks the protocol version spoken to the broker. MQTT 5 adds
+// expiry to retained game messages, version tags and response topics.
+type MQTTConfig struct {
+	Version     int `mapstructure:"version"`      // 3 for MQTT 3.1.1, or 5
+	StateExpiry int `mapstructure:"state_expiry"` // hours retained game messages live with MQTT 5, 0 for no expiry
+}
+
+// GameIDConfig shapes the random IDs of new games.
+type GameIDConfig struct {
+	Length   int    `mapstructure:"length"`
+	Alphabet string `mapstructure:"alphabet"`
+}
+
+// DefaultGameIDAlphabet leaves out characters easily misread for others.
+const DefaultGameIDAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// AnalysisConfig runs the engine over a finished game and prints how each
+// move compared with the best one.
+type AnalysisConfig struct {
+	AfterGame bool `mapstructure:"after_game"`
+	Depth     int  `mapstructure:"depth"` // plies searched per position
+}
+
+// AIConfig sets the strength of the built-in computer opponent. Level picks
+// "easy", "medium" or "hard"; the other fields override the level when set.
+type AIConfig struct {
+	Level          string `mapstructure:"level"`
+	Engine         string `mapstructure:"engine"`          // "minimax" or "mcts"
+	Personality    string `mapstructure:"personality"`     // "silent", "friendly" or "taunting"
+	Playouts       int    `mapstructure:"playouts"`        // random playouts per move with mcts
+	Depth          int    `mapstructure:"depth"`           // plies to search
+	TimeLimit      int    `mapstructure:"time_limit"`      // ms per move
+	BlunderPercent int    `mapstructure:"blunder_percent"` // chance of a random move
+	Book           string `mapstructure:"book"`            // opening book file written by selfplay --book
+	External       string `mapstructure:"external"`        // command line of an external engine to play instead
+}
+
+// BandwidthConfig sets a per-game data budget for metered cellular links.
+// A non-zero budget turns off optional traffic and reports usage.
+type BandwidthConfig struct {
+	BudgetKB int `mapstructure:"budget_kb"`
+}
+
+// ClockConfig controls the low-time warning and latency compensation on
+// timed games.
+type ClockConfig struct {
+	LowTimeWarning int  `mapstructure:"low_time_warning"` // seconds left before the clock flashes
+	Beep           bool `mapstructure:"beep"`
+	// LatencyCap is the most network round-trip time, in milliseconds,
+	// refunded on each move. 0 turns compensation off.
+	LatencyCap int `mapstructure:"latency_cap"`
+}
+
+// HooksConfig lists shell commands run on game events. Each command gets the
+// event as JSON on stdin and its name in GOBBLET_EVENT.
+type HooksConfig struct {
+	GameStart []string `mapstructure:"game_start"`
+	Move      []string `mapstructure:"move"`
+	GameEnd   []string `mapstructure:"game_end"`
+	Timeout   int      `mapstructure:"timeout"` // seconds per command
+}
+
+// APIConfig configures the game-creation API used by external matchmaking.
+type APIConfig struct {
+	Listen string `mapstructure:"listen"`
+	Token  string `mapstructure:"token"` // required as "Authorization: Bearer <token>"; serve-api won't start without it
+}
+
+// AggregatorConfig lists the brokers merged by the aggregate command.
+type AggregatorConfig struct {
+	Brokers []BrokerConfig `mapstructure:"brokers"`
+}
+
+// BrokerConfig names a broker so its games can be told apart.
+type BrokerConfig struct {
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
+}
+
+// PrivacyConfig pseudonymizes player identifiers in public feeds and shared
+// archive exports. Local records keep the real names.
+type PrivacyConfig struct {
+	Redact bool   `mapstructure:"redact"`
+	Salt   string `mapstructure:"salt"` // set per deployment so pseudonyms can't be reversed by guessing
+}
+
+// StrictIdentityConfig makes rated games refuse to start unless the opponent
+// proves a key listed in the known-keys registry.
+type StrictIdentityConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	KnownKeysFile string `mapstructure:"known_keys_file"` // default ~/.gobblet/keys/trusted
+	RegistryURL   string `mapstructure:"registry_url"`    // optional server API, GET <url>/<key>
+}
+
+// UpdateCheckConfig controls the startup check for new releases and
+// protocol deprecations. Leave ManifestURL empty to read the manifest from
+// the retained MQTT topic instead.
+type UpdateCheckConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	ManifestURL string `mapstructure:"manifest_url"`
 }
 
 var Conf Config
 
+// Dir is the directory holding config.yaml and user-defined preset files.
+const Dir = "./config"
+
 func init() {
-	viper.SetConfigName("config")   // name of config file (without extension)
-	viper.SetConfigType("yaml")     // REQUIRED if the config file does not have the extension in the name
-	viper.AddConfigPath("./config") // optionally look for config in the working directory
-	err := viper.ReadInConfig()     // Find and read the config file
-	if err != nil {                 // Handle errors reading the config file
+	viper.SetConfigName("config") // name of config file (without extension)
+	viper.SetConfigType("yaml")   // REQUIRED if the config file does not have the extension in the name
+	viper.SetDefault("update_check.enabled", true)
+	viper.SetDefault("ordered_delivery", true)
+	viper.SetDefault("move_deltas", true)
+	viper.SetDefault("wire_format", "json")
+	viper.SetDefault("api.listen", ":8081")
+	viper.SetDefault("hooks.timeout", 10)
+	viper.SetDefault("clock.low_time_warning", 30)
+	viper.SetDefault("clock.beep", true)
+	viper.SetDefault("clock.latency_cap", 500)
+	viper.SetDefault("device.id_source", "auto")
+	viper.SetDefault("league.retries", 3)
+	viper.SetDefault("mqtt.version", 3)
+	viper.SetDefault("mqtt.state_expiry", 168)
+	viper.SetDefault("game_id.length", 8)
+	viper.SetDefault("game_id.alphabet", DefaultGameIDAlphabet)
+	viper.SetDefault("ai.level", "medium")
+	viper.SetDefault("ai.engine", "minimax")
+	viper.SetDefault("ai.personality", "silent")
+	viper.SetDefault("analysis.depth", 4)
+	viper.SetDefault("language", "en")
+	viper.SetDefault("organization.chat", true)
+	viper.SetDefault("duplicate_session", "takeover")
+	viper.SetDefault("orientation", "normal")
+	viper.SetDefault("variant", "junior")
+}
+
+// Load reads config.yaml from dir into Conf, over the defaults.
+func Load(dir string) {
+	viper.AddConfigPath(dir)
+	if err := viper.ReadInConfig(); err != nil { // Find and read the config file
 		panic(fmt.Errorf("fatal error config file: %w", err))
 	}
 	if err := viper.Unmarshal(&Conf); err != nil {
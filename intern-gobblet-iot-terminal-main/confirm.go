@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A move is confirmed when the opponent answers its delta with an ack
+// carrying its Seq, or when the opponent or referee publishes a state that
+// includes it. Until then it is sent again with backoff, unless the
+// referee refuses it: sending it again would only be refused again.
+const (
+	confirmWait    = 2 * time.Second // before the first retransmission, doubled each time
+	confirmRetries = 4
+)
+
+var (
+	confirmMu   sync.Mutex
+	confirmed   int // highest own move confirmed
+	unconfirmed int // own move given up on, 0 when none
+	refused     int // own move the referee refused last, 0 when none
+)
+
+// sendAck confirms the opponent's move seq. Not waited on: it runs in the
+// message handler.
+func sendAck(seq int) {
+	if !seated() || mqttClient == nil {
+		return
+	}
+	data := encodeMove(MoveMessage{Type: deltaAck, Seq: seq, Player: playerID})
+	publishPayload(movesTopic(), 1, false, data)
+}
+
+// noteConfirmed records that our moves up to seq arrived.
+func noteConfirmed(seq int) {
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+	confirmed = max(confirmed, seq)
+	if unconfirmed != 0 && seq >= unconfirmed {
+		fmt.Printf("\n✅ Move %d confirmed after all.\n", unconfirmed)
+		unconfirmed = 0
+	}
+}
+
+// noteRefused records that the referee refused move seq.
+func noteRefused(seq int) {
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+	refused = seq
+}
+
+// settled reports whether move seq needs no more sending: it arrived, or
+// the referee refused it.
+func settled(seq int) bool {
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+	return confirmed >= seq || refused == seq
+}
+
+// unconfirmedMove is the own move given up on, 0 when none.
+func unconfirmedMove() int {
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+	return unconfirmed
+}
+
+// awaitConfirm sends move seq again with resend until it is confirmed,
+// and tells the player when it never is. Games against the computer have
+// nobody to confirm.
+func awaitConfirm(seq int, resend func()) {
+	if computerSeat != 0 || mqttClient == nil {
+		return
+	}
+	go func() {
+		wait := confirmWait
+		for range confirmRetries {
+			time.Sleep(wait)
+			if settled(seq) {
+				return
+			}
+			fmt.Printf("\n🔁 Move %d not confirmed yet, sending it again\n", seq)
+			resend()
+			wait *= 2
+		}
+		time.Sleep(wait)
+		if settled(seq) {
+			return
+		}
+		confirmMu.Lock()
+		unconfirmed = seq
+		confirmMu.Unlock()
+		fmt.Printf("\n⚠ Move %d not confirmed: the other side may not have it, so your boards could differ.\n", seq)
+	}()
+}
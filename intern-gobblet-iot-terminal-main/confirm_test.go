@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRefereeRefusalSettlesMove(t *testing.T) {
+	t.Cleanup(func() { confirmed, refused = 0, 0 })
+	old := playerID
+	t.Cleanup(func() { playerID = old })
+	playerID = 1
+	confirmed, refused = 4, 0
+
+	reject := func(player, moves int) {
+		data, _ := json.Marshal(Rejection{Player: player, Moves: moves, Reason: "illegal move"})
+		onRejection(nil, traceMessage{TraceEntry{Topic: rejectionTopic(), Binary: data}})
+	}
+	if settled(5) {
+		t.Fatal("move 5 settled before any answer")
+	}
+	reject(2, 5)
+	if settled(5) {
+		t.Error("the opponent's refusal stopped the resends; only the referee's should")
+	}
+	reject(refereeID, 5)
+	if !settled(5) {
+		t.Error("move 5 is still being resent after the referee refused it")
+	}
+	if settled(6) {
+		t.Error("the refusal of move 5 settled move 6")
+	}
+}
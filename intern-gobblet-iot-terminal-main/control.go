@@ -0,0 +1,83 @@
+package main
+
+import "fmt"
+
+// Control actions are typed like moves (3 to resign, 4 to offer or accept a
+// draw) but travel as the Control field of the published state.
+const (
+	actionResign = 3
+	actionDraw   = 4
+)
+
+const (
+	controlResign      = "resign"
+	controlDrawOffer   = "draw_offer"
+	controlDrawAccept  = "draw_accept"
+	controlDrawDecline = "draw_decline"
+)
+
+// Control is a non-move message from a player, carried in GameState so the
+// opponent reacts instead of waiting for a move that won't come.
+type Control struct {
+	Action string
+	Player int
+}
+
+var (
+	control       *Control // sent with the next published state only
+	drawOfferedBy int      // the opponent's open draw offer, 0 if none
+)
+
+// sendControl publishes the current state with c attached.
+func sendControl(c Control) {
+	control = &c
+	saveGameState()
+	control = nil
+}
+
+// resign concedes the game to the opponent.
+func resign() {
+	gameResult = newWinResult(3-playerID, TerminationResignation)
+	fmt.Println("🏳 You resigned.")
+	sendControl(Control{Action: controlResign, Player: playerID})
+	finishGame(gameResult)
+}
+
+// offerDraw offers a draw, or accepts the opponent's open offer.
+func offerDraw() {
+	if drawOfferedBy == 3-playerID {
+		answerDraw(true)
+		return
+	}
+	fmt.Println("🤝 Draw offered, waiting for your opponent...")
+	sendControl(Control{Action: controlDrawOffer, Player: playerID})
+}
+
+// answerDraw accepts or declines the opponent's draw offer.
+func answerDraw(accept bool) {
+	drawOfferedBy = 0
+	if !accept {
+		fmt.Println("🚫 Draw declined.")
+		sendControl(Control{Action: controlDrawDecline, Player: playerID})
+		return
+	}
+	gameResult = newDrawResult(TerminationAgreement)
+	sendControl(Control{Action: controlDrawAccept, Player: playerID})
+	finishGame(gameResult)
+}
+
+// onControl shows the opponent's control message. Results arrive in the
+// same state and are handled with it.
+func onControl(c Control) {
+	switch c.Action {
+	case controlResign:
+		fmt.Printf("\n🏳 Player %d resigned.\n", c.Player)
+	case controlDrawOffer:
+		drawOfferedBy = c.Player
+		fmt.Printf("\n🤝 Player %d offers a draw. Type 4 or 'accept' to agree, 'decline' to refuse.\n", c.Player)
+	case controlDrawAccept:
+		fmt.Printf("\n🤝 Player %d accepted the draw.\n", c.Player)
+	case controlDrawDecline:
+		fmt.Printf("\n🚫 Player %d declined the draw.\n", c.Player)
+	}
+}
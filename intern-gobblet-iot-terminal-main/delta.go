@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"goblets/engine"
+	"goblets/notation"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// With move deltas on, a move is published as a small MoveMessage on
+// gobblet/game/<id>/moves instead of the full state. Receivers apply it to
+// the board they hold, so a stale peer can't overwrite the whole board. The
+// full state still goes out retained as a snapshot every snapshotEvery
+// moves, at the end of the game and whenever a terminal that fell behind
+// asks for it.
+
+// snapshotEvery is how many moves pass between retained full states.
+const snapshotEvery = 10
+
+// Move message types.
+const (
+	deltaPlace  = "place"
+	deltaMove   = "move"
+	deltaResync = "resync" // asks the players for a full state
+	deltaAck    = "ack"    // confirms the move numbered Seq
+)
+
+// MoveMessage is one move, or a request for a full state.
+type MoveMessage struct {
+	Type   string
+	From   string    `json:",omitempty"` // square the piece leaves, e.g. "a1"; empty for a placement
+	To     string    `json:",omitempty"`
+	Size   int       `json:",omitempty"` // size of a placed piece
+	Player int       `json:",omitempty"`
+	Seq    int       // move count after the move; for resync, the count the sender holds
+	Time   time.Time `json:",omitempty"`
+	Clocks *Clocks   `json:",omitempty"` // the mover's clocks after the move, checked against ours
+	// Signature proves the mover holds the seat, for the referee in
+	// refereed games.
+	Signature []byte `json:",omitempty"`
+}
+
+func movesTopic() string {
+	return gameTopic(gameID, channelMoves)
+}
+
+func moveMessage(m engine.Move, player int) MoveMessage {
+	mm := MoveMessage{Type: deltaMove, To: notation.Square(m.Row, m.Col), Player: player, Seq: moveCount, Time: time.Now().UTC(), Clocks: clocks}
+	if m.IsPlacement() {
+		mm.Type, mm.Size = deltaPlace, m.Size
+	} else {
+		mm.From = notation.Square(m.FromRow, m.FromCol)
+	}
+	return mm
+}
+
+// move decodes the engine move of a place or move message.
+func (mm MoveMessage) move() (engine.Move, error) {
+	return notation.Delta(mm.Type, mm.From, mm.To, mm.Size)
+}
+
+// publishDelta sends the move just made, and the full state when a
+// snapshot is due.
+func publishDelta(m engine.Move, player int) {
+	mm := moveMessage(m, player)
+	if gameMeta.Refereed {
+		var err error
+		if mm, err = signMove(mm); err != nil {
+			fmt.Println("⚠ Could not sign the move for the referee:", err)
+		}
+	}
+	data := encodeMove(mm)
+	fmt.Println("📤 Sending move to AWS IoT Core:", logText(data))
+	token := publishPayload(movesTopic(), stateQoS(), false, data)
+	go watchAck(token)
+	awaitConfirm(moveCount, func() { publishPayload(movesTopic(), stateQoS(), false, data) })
+	if gameMeta.Refereed {
+		return // the referee publishes the state
+	}
+	if moveCount%snapshotEvery == 0 || gameResult != nil {
+		saveGameState() // logged when its echo arrives
+	} else {
+		logDeltaState()
+	}
+}
+
+// requestResync asks the players for a full state when we are behind.
+func requestResync() {
+	if mqttClient == nil { // replaying a trace
+		return
+	}
+	data := encodeMove(MoveMessage{Type: deltaResync, Seq: moveCount})
+	// Not waited on: this may run in a message handler
+	publishPayload(movesTopic(), 1, false, data)
+}
+
+// onMoveReceived applies a move delta to the local game.
+func onMoveReceived(client mqtt.Client, msg mqtt.Message) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var mm MoveMessage
+	if err := decodeMove(msg.Payload(), &mm); err != nil {
+		fmt.Println("❌ Error decoding move:", err)
+		return
+	}
+	if mm.Type == deltaAck {
+		if mm.Player != playerID {
+			noteConfirmed(mm.Seq)
+		}
+		return
+	}
+	if gameMeta.Refereed {
+		return // the referee answers with the state
+	}
+	if mm.Type == deltaResync {
+		if seated() && moveCount > mm.Seq && mqttClient != nil {
+			publishGameState(encodeState(nextState()))
+		}
+		return
+	}
+	if mm.Seq <= moveCount || board == nil {
+		if board != nil && mm.Player != playerID {
+			sendAck(mm.Seq) // a retransmission: our ack may have been lost
+		}
+		return // our own move, a duplicate, or older than the game we hold
+	}
+	if playerID != spectatorID {
+		fmt.Println("📥 Received move from AWS IoT Core:", logText(msg.Payload()))
+	}
+	if mm.Seq > moveCount+1 {
+		requestResync() // we missed a move: wait for the full state
+		return
+	}
+
+	m, err := mm.move()
+	if err == nil && mm.Player != playerTurn {
+		err = fmt.Errorf("it is Player %d's turn", playerTurn)
+	}
+	var next engine.State
+	if err == nil {
+		next, err = makeMove(m)
+	}
+	if err != nil {
+		rejectState(GameState{Moves: mm.Seq}, fmt.Sprintf("Rejected an illegal move from Player %d: %v", mm.Player, err))
+		return
+	}
+	sendAck(mm.Seq)
+	noteConfirmed(mm.Seq - 1) // they answered our last move
+	if mm.Clocks != nil && clocks != nil {
+		if err := clocks.check(mm.Clocks); err != nil { // makeMove charged ours
+			fmt.Println("⚠ Kept our own clocks:", err)
+		}
+	}
+	history[len(history)-1].Time = mm.Time
+	if gameResult == nil {
+		playerTurn = next.Turn
+	}
+	logDeltaState()
+
+	if playerID == spectatorID {
+		requestRedraw()
+	} else {
+		printBoard()
+	}
+	if moveCount > hookedMoves {
+		hookedMoves = moveCount
+		fireHook(hookMove, currentState(), false)
+	}
+	if gameResult != nil {
+		finishGame(gameResult)
+	}
+}
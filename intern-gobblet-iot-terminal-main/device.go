@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"goblets/config"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// deviceID identifies this machine across runs, unlike the per-run client
+// ID, so seats and statistics stay attributable to a device after restarts.
+var deviceID string
+
+var machineIDFiles = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+
+func deviceIDFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".gobblet", "device-id")
+}
+
+// loadDeviceID returns the stable device identity, bound to the OS machine
+// ID when available or to a secret generated on first run. The machine ID
+// is hashed so it never leaves the device.
+func loadDeviceID() (string, error) {
+	source := config.Conf.Device.IDSource
+	if source == "auto" || source == "machine-id" {
+		for _, file := range machineIDFiles {
+			if data, err := os.ReadFile(file); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+				return hashDeviceSecret(strings.TrimSpace(string(data))), nil
+			}
+		}
+		if source == "machine-id" {
+			return "", fmt.Errorf("no machine ID found in %s", strings.Join(machineIDFiles, ", "))
+		}
+	}
+
+	data, err := os.ReadFile(deviceIDFile())
+	if err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		return hashDeviceSecret(strings.TrimSpace(string(data))), nil
+	}
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(deviceIDFile()), 0o700); err != nil {
+		return "", err
+	}
+	encoded := hex.EncodeToString(secret)
+	if err := os.WriteFile(deviceIDFile(), []byte(encoded+"\n"), 0o600); err != nil {
+		return "", err
+	}
+	return hashDeviceSecret(encoded), nil
+}
+
+func hashDeviceSecret(secret string) string {
+	sum := sha256.Sum256([]byte("gobblet-device:" + secret))
+	return "dev-" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"goblets/config"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Dispute is a player's signed claim that the published result is wrong,
+// carrying its local audit log so the arbiter can re-verify the moves.
+type Dispute struct {
+	GameID    string
+	Player    int
+	Reason    string
+	LogHash   string
+	Log       []StateLogEntry
+	Time      time.Time
+	PublicKey []byte
+	Signature []byte `json:",omitempty"`
+}
+
+// DisputeResolution is the arbiter's signed, binding answer to a dispute.
+type DisputeResolution struct {
+	GameID    string
+	LogHash   string
+	Upheld    bool
+	Result    *Result `json:",omitempty"`
+	Text      string
+	Time      time.Time
+	PublicKey []byte
+	Signature []byte `json:",omitempty"`
+}
+
+func disputeTopic(id string) string {
+	return "gobblet/game/" + id + "/disputes"
+}
+
+func resolutionTopic(id string) string {
+	return "gobblet/game/" + id + "/disputes/resolution"
+}
+
+func (d Dispute) signedBytes() []byte {
+	d.Signature = nil
+	data, _ := json.Marshal(d)
+	return data
+}
+
+// Verify checks the dispute signature against the embedded public key.
+func (d Dispute) Verify() bool {
+	return len(d.PublicKey) == ed25519.PublicKeySize && ed25519.Verify(d.PublicKey, d.signedBytes(), d.Signature)
+}
+
+func (r DisputeResolution) signedBytes() []byte {
+	r.Signature = nil
+	data, _ := json.Marshal(r)
+	return data
+}
+
+// Verify checks the resolution signature against the embedded public key.
+func (r DisputeResolution) Verify() bool {
+	return len(r.PublicKey) == ed25519.PublicKeySize && ed25519.Verify(r.PublicKey, r.signedBytes(), r.Signature)
+}
+
+// arbiterKey returns the configured arbiter_key.
+func arbiterKey() (ed25519.PublicKey, error) {
+	if config.Conf.ArbiterKey == "" {
+		return nil, errors.New("set arbiter_key in config.yaml to the arbiter's public key before filing a dispute")
+	}
+	pub, err := base64.StdEncoding.DecodeString(config.Conf.ArbiterKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("arbiter_key in config.yaml is not an ed25519 public key")
+	}
+	return pub, nil
+}
+
+// fromArbiter reports whether a resolution is signed by the arbiter. Anyone
+// can sign a resolution with a key of their own.
+func (r DisputeResolution) fromArbiter(arbiter ed25519.PublicKey) bool {
+	return bytes.Equal(r.PublicKey, arbiter) && r.Verify()
+}
+
+// runDispute files a dispute against a finished game and waits for the
+// arbiter's resolution.
+func runDispute(args []string) {
+	fs := flag.NewFlagSet("dispute", flag.ExitOnError)
+	wait := fs.Duration("wait", 10*time.Minute, "how long to wait for a resolution")
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		fmt.Println("Usage: dispute [--wait 10m] <gameID> <reason>")
+		os.Exit(1)
+	}
+	gameID = fs.Arg(0)
+	reason := strings.Join(fs.Args()[1:], " ")
+
+	record, err := readArchiveRecord(gameID)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	entries, err := readStateLog(gameID)
+	if err != nil || len(entries) == 0 {
+		log.Fatal("❌ No audit log recorded for game ", gameID)
+	}
+	if err := verifyStateLogChain(entries); err != nil {
+		log.Fatal("❌ Local audit log is corrupt: ", err)
+	}
+
+	arbiter, err := arbiterKey()
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	key, err := loadSigningKey()
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	dispute := Dispute{
+		GameID:    gameID,
+		Player:    record.Player,
+		Reason:    reason,
+		LogHash:   entries[len(entries)-1].Hash,
+		Log:       entries,
+		Time:      time.Now().UTC(),
+		PublicKey: key.Public().(ed25519.PublicKey),
+	}
+	dispute.Signature = ed25519.Sign(key, dispute.signedBytes())
+
+	connectMQTT()
+	resolutions := make(chan DisputeResolution, 1)
+	token := mqttClient.Subscribe(resolutionTopic(gameID), 1, func(client mqtt.Client, msg mqtt.Message) {
+		var r DisputeResolution
+		if json.Unmarshal(msg.Payload(), &r) != nil || r.LogHash != dispute.LogHash {
+			return
+		}
+		if !r.fromArbiter(arbiter) {
+			fmt.Println("\n⚠ Ignored a resolution not signed by the arbiter_key")
+			return
+		}
+		select {
+		case resolutions <- r:
+		default:
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error:", token.Error())
+	}
+
+	data, _ := json.Marshal(dispute)
+	if token := mqttClient.Publish(disputeTopic(gameID), 1, true, data); token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Could not file dispute:", token.Error())
+	}
+	fmt.Printf("📨 Dispute filed for game %s (audit log %s). Waiting for the arbiter...\n", gameID, dispute.LogHash[:12])
+
+	select {
+	case r := <-resolutions:
+		printResolution(r)
+		if r.Upheld && r.Result != nil {
+			record.Result = *r.Result
+			saveArchiveRecord(record)
+			fmt.Println("✅ Archive updated with the corrected result.")
+		}
+	case <-time.After(*wait):
+		fmt.Println("⚠ No resolution yet. The dispute stays open on the broker.")
+	}
+}
+
+func printResolution(r DisputeResolution) {
+	verdict := "rejected"
+	if r.Upheld {
+		verdict = "upheld"
+	}
+	fmt.Printf("⚖ Dispute %s: %s\n", verdict, r.Text)
+	if r.Result != nil {
+		fmt.Println("⚖ Binding result:", r.Result)
+	}
+}
+
+// runDisputes is the arbiter desk: it re-verifies incoming disputes for a
+// game and publishes signed resolutions.
+func runDisputes(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: disputes <gameID>")
+		os.Exit(1)
+	}
+	gameID = args[0]
+	connectMQTT()
+
+	var current *Dispute
+	token := mqttClient.Subscribe(disputeTopic(gameID), 1, func(client mqtt.Client, msg mqtt.Message) {
+		if len(msg.Payload()) == 0 {
+			return // retained dispute cleared after resolution
+		}
+		var d Dispute
+		if err := json.Unmarshal(msg.Payload(), &d); err != nil || !d.Verify() || len(d.Log) == 0 {
+			fmt.Println("\n❌ Ignoring malformed or unsigned dispute")
+			return
+		}
+		fmt.Printf("\n📨 Dispute from Player %d: %s\n", d.Player, d.Reason)
+		if err := verifyStateLogChain(d.Log); err != nil {
+			fmt.Println("❌ Audit log chain:", err)
+		} else if d.Log[len(d.Log)-1].Hash != d.LogHash {
+			fmt.Println("❌ Audit log does not end at the referenced hash")
+		} else if err := verifyMoveChain(d.Log); err != nil {
+			fmt.Println("❌ Move chain:", err)
+		} else {
+			fmt.Printf("✅ Audit log %s verified: %d states, every move legal\n", d.LogHash[:12], len(d.Log))
+		}
+		mu.Lock()
+		current = &d
+		mu.Unlock()
+		fmt.Print("arbiter> ")
+	})
+	if token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error:", token.Error())
+	}
+
+	fmt.Println("⚖ Waiting for disputes. Commands: uphold <1|2|draw> <text> | reject <text>")
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("arbiter> ")
+		if !scanner.Scan() {
+			return
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		mu.Lock()
+		d := current
+		mu.Unlock()
+		if d == nil {
+			fmt.Println("❌ No open dispute.")
+			continue
+		}
+
+		resolution := DisputeResolution{GameID: gameID, LogHash: d.LogHash, Time: time.Now().UTC()}
+		switch {
+		case fields[0] == "reject":
+			resolution.Text = strings.Join(fields[1:], " ")
+		case fields[0] == "uphold" && len(fields) >= 2 && (fields[1] == "1" || fields[1] == "2"):
+			resolution.Upheld = true
+			resolution.Result = newWinResult(int(fields[1][0]-'0'), TerminationAdjudication)
+			resolution.Text = strings.Join(fields[2:], " ")
+		case fields[0] == "uphold" && len(fields) >= 2 && fields[1] == "draw":
+			resolution.Upheld = true
+			resolution.Result = newDrawResult(TerminationAdjudication)
+			resolution.Text = strings.Join(fields[2:], " ")
+		default:
+			fmt.Println("❌ Usage: uphold <1|2|draw> <text> | reject <text>")
+			continue
+		}
+
+		if err := publishResolution(resolution, d); err != nil {
+			fmt.Println("❌ Could not publish resolution:", err)
+			continue
+		}
+		printResolution(resolution)
+		mu.Lock()
+		current = nil
+		mu.Unlock()
+	}
+}
+
+// publishResolution signs and publishes a resolution, records it in the
+// game's audit topic and, when upheld, republishes the final state with the
+// corrected result.
+func publishResolution(r DisputeResolution, d *Dispute) error {
+	key, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+	r.PublicKey = key.Public().(ed25519.PublicKey)
+	r.Signature = ed25519.Sign(key, r.signedBytes())
+
+	data, _ := json.Marshal(r)
+	if token := mqttClient.Publish(resolutionTopic(gameID), 1, true, data); token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	// Clear the retained dispute now that it has been answered
+	mqttClient.Publish(disputeTopic(gameID), 1, true, []byte{}).Wait()
+
+	verdict := "dispute rejected"
+	if r.Upheld {
+		verdict = "dispute upheld"
+	}
+	if err := publishAudit(verdict, r.Text); err != nil {
+		return err
+	}
+
+	if r.Upheld && r.Result != nil {
+		final, err := d.Log[len(d.Log)-1].gameState()
+		if err != nil {
+			return err
+		}
+		final.Result = r.Result
+		final.Winner = r.Result.Winner()
+		final.Seq, final.Sender = final.Seq+1, clientID
+		data, _ := json.Marshal(final)
+		token := mqttClient.Publish(stateTopic(), 1, true, data)
+		token.Wait()
+		return token.Error()
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+)
+
+func TestResolutionFromArbiter(t *testing.T) {
+	arbiterPub, arbiter, _ := ed25519.GenerateKey(nil)
+	_, player, _ := ed25519.GenerateKey(nil)
+	sign := func(key ed25519.PrivateKey) DisputeResolution {
+		r := DisputeResolution{GameID: "g", LogHash: "abc", Upheld: true, Result: newWinResult(1, TerminationAdjudication), Time: time.Now().UTC()}
+		r.PublicKey = key.Public().(ed25519.PublicKey)
+		r.Signature = ed25519.Sign(key, r.signedBytes())
+		return r
+	}
+
+	if r := sign(arbiter); !r.fromArbiter(arbiterPub) {
+		t.Error("refused the arbiter's resolution")
+	}
+	if r := sign(player); !r.Verify() || r.fromArbiter(arbiterPub) {
+		t.Error("accepted a self-signed resolution")
+	}
+	r := sign(arbiter)
+	r.Result = newWinResult(2, TerminationAdjudication)
+	if r.fromArbiter(arbiterPub) {
+		t.Error("accepted a resolution changed after signing")
+	}
+}
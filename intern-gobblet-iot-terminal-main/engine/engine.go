@@ -0,0 +1,349 @@
+// Package engine holds the Gobblet Gobblers rules: the board, moves and win
+// detection. It does no I/O, so bots, the referee service and other front
+// ends can share it and it can be tested on its own.
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by Apply. Front ends compare against these with
+// errors.Is and choose their own wording.
+var (
+	ErrGameOver        = errors.New("the game is already over")
+	ErrOutOfBounds     = errors.New("out of bounds")
+	ErrBadSize         = errors.New("no such piece size")
+	ErrReserveEmpty    = errors.New("no piece of that size left in the reserve")
+	ErrEntryNotEmpty   = errors.New("new pieces must enter on an empty cell")
+	ErrNoPiece         = errors.New("no piece to move")
+	ErrNotYourPiece    = errors.New("you can only move your own pieces")
+	ErrSmallerOnLarger = errors.New("cannot place a smaller piece on a larger one")
+	ErrReserveGobble   = errors.New("new pieces may not cover an opponent's piece")
+	ErrNotThreatening  = errors.New("only pieces in a line one short of complete can be gobbled")
+)
+
+// Gobblet is one piece. Sizes go from 1 (smallest) up to the variant's
+// largest size.
+type Gobblet struct {
+	Size  int
+	Owner int
+}
+
+// Stack is the pile of pieces on one cell, bottom to top.
+type Stack []Gobblet
+
+// Board is the square grid of stacks, indexed [row][col].
+type Board [][]Stack
+
+// MinSize and MaxSize bound the board sizes the engine plays. Lines are as
+// long as the board is wide, so a 5x5 board needs five in a row.
+const (
+	MinSize = 3
+	MaxSize = 9
+)
+
+// NewBoard returns an empty size x size board.
+func NewBoard(size int) Board {
+	b := make(Board, size)
+	for i := range b {
+		b[i] = make([]Stack, size)
+	}
+	return b
+}
+
+// Size returns the width and height of the board.
+func (b Board) Size() int {
+	return len(b)
+}
+
+// Square reports whether b is a supported size and every row is as long as
+// the board is tall. Boards received from other terminals should be checked
+// before use.
+func (b Board) Square() bool {
+	if len(b) < MinSize || len(b) > MaxSize {
+		return false
+	}
+	for _, row := range b {
+		if len(row) != len(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// Top returns the visible piece on a stack.
+func (s Stack) Top() (Gobblet, bool) {
+	if len(s) == 0 {
+		return Gobblet{}, false
+	}
+	return s[len(s)-1], true
+}
+
+// Clone deep-copies the board so changes never touch the original stacks.
+func (b Board) Clone() Board {
+	c := make(Board, len(b))
+	for i := range b {
+		c[i] = make([]Stack, len(b[i]))
+		for j := range b[i] {
+			c[i][j] = append(Stack(nil), b[i][j]...)
+		}
+	}
+	return c
+}
+
+// Move is either a placement of a new piece or a move of a visible piece.
+type Move struct {
+	FromRow, FromCol int // -1 for a placement
+	Row, Col         int
+	Size             int // size of a placed piece, 0 for a move
+}
+
+// Place builds a placement of a new piece.
+func Place(row, col, size int) Move {
+	return Move{FromRow: -1, FromCol: -1, Row: row, Col: col, Size: size}
+}
+
+// Shift builds a move of the visible piece at fromRow, fromCol.
+func Shift(fromRow, fromCol, row, col int) Move {
+	return Move{FromRow: fromRow, FromCol: fromCol, Row: row, Col: col}
+}
+
+// IsPlacement reports whether m places a new piece.
+func (m Move) IsPlacement() bool {
+	return m.FromRow < 0
+}
+
+// State is a position with the player to move.
+type State struct {
+	Board   Board
+	Turn    int
+	Variant Variant
+	// Rules replaces the variant's rules when set, e.g. with house rules.
+	Rules *Rules
+	// Winner is set once a player owns a line; Uncovered means the loser
+	// revealed it by lifting a piece.
+	Winner    int
+	Uncovered bool
+}
+
+func (b Board) inBounds(row, col int) bool {
+	return row >= 0 && row < len(b) && col >= 0 && col < len(b)
+}
+
+// Apply plays m for the player to move and returns the new state. The
+// receiver is left unchanged.
+func (s State) Apply(m Move) (State, error) {
+	rules := s.rules()
+	if s.Winner != 0 {
+		return s, ErrGameOver
+	}
+	if !s.Board.inBounds(m.Row, m.Col) || (!m.IsPlacement() && !s.Board.inBounds(m.FromRow, m.FromCol)) {
+		return s, ErrOutOfBounds
+	}
+
+	var piece Gobblet
+	opponent := 3 - s.Turn
+	if m.IsPlacement() {
+		if m.Size < 1 || m.Size > rules.Sizes {
+			return s, fmt.Errorf("%w: sizes go from 1 to %d", ErrBadSize, rules.Sizes)
+		}
+		if !rules.InReserve(s.Board, s.Turn, m.Size) {
+			return s, ErrReserveEmpty
+		}
+		if top, ok := s.Board[m.Row][m.Col].Top(); ok && rules.EntryOnEmpty && (top.Owner == s.Turn || !inThreat(s.Board, m.Row, m.Col, opponent)) {
+			return s, ErrEntryNotEmpty
+		}
+		if top, ok := s.Board[m.Row][m.Col].Top(); ok && rules.NoReserveGobble && top.Owner == opponent {
+			return s, ErrReserveGobble
+		}
+		piece = Gobblet{Size: m.Size, Owner: s.Turn}
+	} else {
+		top, ok := s.Board[m.FromRow][m.FromCol].Top()
+		if !ok {
+			return s, ErrNoPiece
+		}
+		if top.Owner != s.Turn {
+			return s, ErrNotYourPiece
+		}
+		piece = top
+	}
+	if top, ok := s.Board[m.Row][m.Col].Top(); ok && top.Size >= piece.Size {
+		return s, ErrSmallerOnLarger
+	}
+	if top, ok := s.Board[m.Row][m.Col].Top(); ok && rules.GobbleOnThreat && top.Owner == opponent && !inThreat(s.Board, m.Row, m.Col, opponent) {
+		return s, ErrNotThreatening
+	}
+
+	next := State{Board: s.Board.Clone(), Turn: opponent, Variant: s.Variant, Rules: s.Rules}
+	revealed := false
+	if !m.IsPlacement() {
+		from := next.Board[m.FromRow][m.FromCol]
+		next.Board[m.FromRow][m.FromCol] = from[:len(from)-1]
+		revealed = HasLine(next.Board, opponent)
+	}
+	next.Board[m.Row][m.Col] = append(next.Board[m.Row][m.Col], piece)
+	// Lifting a piece that uncovers an opponent's line loses, unless the
+	// variant lets the same piece cover that line again
+	if revealed && (!rules.CoverReveal || HasLine(next.Board, opponent)) {
+		next.Winner, next.Uncovered = opponent, true
+	}
+	if next.Winner == 0 {
+		next.Winner = Resolve(next.Board, s.Turn)
+	}
+	if next.Winner != 0 {
+		next.Turn = s.Turn
+	}
+	return next, nil
+}
+
+// inThreat reports whether the piece at row, col is part of a line where
+// player owns every cell but one.
+func inThreat(b Board, row, col, player int) bool {
+	for _, line := range Lines(b.Size()) {
+		through, owned := false, 0
+		for _, cell := range line {
+			if cell == [2]int{row, col} {
+				through = true
+			}
+			if top, ok := b[cell[0]][cell[1]].Top(); ok && top.Owner == player {
+				owned++
+			}
+		}
+		if through && owned >= len(line)-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// LegalMoves lists every placement and move player can make in s. A
+// finished game has none.
+func LegalMoves(s State, player int) []Move {
+	if s.Winner != 0 {
+		return nil
+	}
+	s.Turn = player
+	b := s.Board
+	n := b.Size()
+	var candidates []Move
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			top, occupied := b[i][j].Top()
+			for size := top.Size + 1; size <= s.rules().Sizes; size++ {
+				candidates = append(candidates, Place(i, j, size))
+			}
+			if !occupied || top.Owner != player {
+				continue
+			}
+			for r := 0; r < n; r++ {
+				for c := 0; c < n; c++ {
+					if target, ok := b[r][c].Top(); !ok || target.Size < top.Size {
+						candidates = append(candidates, Shift(i, j, r, c))
+					}
+				}
+			}
+		}
+	}
+	var moves []Move
+	for _, m := range candidates {
+		if _, err := s.Apply(m); err == nil {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+// Stalemate reports whether the game is unfinished but the player to move
+// has nothing to play.
+func (s State) Stalemate() bool {
+	return s.Winner == 0 && len(LegalMoves(s, s.Turn)) == 0
+}
+
+// Equal reports whether two boards hold the same stacks.
+func Equal(a, b Board) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		for j := range a[i] {
+			if len(a[i][j]) != len(b[i][j]) {
+				return false
+			}
+			for k := range a[i][j] {
+				if a[i][j][k] != b[i][j][k] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Lines lists every row, column and diagonal of a size x size board as
+// cell coordinates.
+func Lines(size int) [][][2]int {
+	var lines [][][2]int
+	diag, anti := make([][2]int, size), make([][2]int, size)
+	for i := 0; i < size; i++ {
+		row, col := make([][2]int, size), make([][2]int, size)
+		for k := 0; k < size; k++ {
+			row[k], col[k] = [2]int{i, k}, [2]int{k, i}
+		}
+		lines = append(lines, row, col)
+		diag[i], anti[i] = [2]int{i, i}, [2]int{i, size - 1 - i}
+	}
+	return append(lines, diag, anti)
+}
+
+// HasLine reports whether player owns a complete line on b.
+func HasLine(b Board, player int) bool {
+	for _, line := range Lines(b.Size()) {
+		owned := 0
+		for _, cell := range line {
+			if top, ok := b[cell[0]][cell[1]].Top(); ok && top.Owner == player {
+				owned++
+			}
+		}
+		if owned == len(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the winner on b right after mover's move, or 0. When the
+// move leaves lines for both players, the opponent's line was uncovered by
+// the move and wins; the mover wins only with a line of their own alone.
+func Resolve(b Board, mover int) int {
+	switch {
+	case HasLine(b, 3-mover):
+		return 3 - mover
+	case HasLine(b, mover):
+		return mover
+	}
+	return 0
+}
+
+// CheckWin returns the player owning a complete line on b, or 0. It does
+// not know who moved last, so when both players own a line the result
+// depends on the board; use Resolve after a move.
+func CheckWin(b Board) int {
+	for _, line := range Lines(b.Size()) {
+		first, ok := b[line[0][0]][line[0][1]].Top()
+		if !ok {
+			continue
+		}
+		owned := true
+		for _, cell := range line[1:] {
+			if top, ok := b[cell[0]][cell[1]].Top(); !ok || top.Owner != first.Owner {
+				owned = false
+				break
+			}
+		}
+		if owned {
+			return first.Owner
+		}
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+// uncoverBoard has Player 2's line along the top row, hidden under Player
+// 1's large piece on a1, and two medium Player 1 pieces on row 2.
+func uncoverBoard() Board {
+	b := NewBoard(3)
+	b[0][0] = Stack{{Size: 1, Owner: 2}, {Size: 3, Owner: 1}}
+	b[0][1] = Stack{{Size: 1, Owner: 2}}
+	b[0][2] = Stack{{Size: 2, Owner: 2}}
+	b[1][1] = Stack{{Size: 2, Owner: 1}}
+	b[1][2] = Stack{{Size: 2, Owner: 1}}
+	return b
+}
+
+func TestApplyUncover(t *testing.T) {
+	coverReveal := &Rules{Size: 3, Sizes: 3, CoverReveal: true}
+	for _, tc := range []struct {
+		name      string
+		rules     *Rules
+		move      Move
+		winner    int
+		uncovered bool
+	}{
+		{"revealed line loses", nil, Shift(0, 0, 2, 2), 2, true},
+		{"covering it again doesn't help without CoverReveal", nil, Shift(0, 0, 0, 1), 2, true},
+		{"CoverReveal saves the game by covering the line", coverReveal, Shift(0, 0, 0, 1), 0, false},
+		{"CoverReveal still loses off the line", coverReveal, Shift(0, 0, 2, 2), 2, true},
+		{"a double line goes to the opponent", nil, Shift(0, 0, 1, 0), 2, true},
+		{"a double line goes to the opponent under CoverReveal", coverReveal, Shift(0, 0, 1, 0), 2, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := State{Board: uncoverBoard(), Turn: 1, Rules: tc.rules}
+			next, err := s.Apply(tc.move)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.Winner != tc.winner || next.Uncovered != tc.uncovered {
+				t.Errorf("winner %d uncovered %v, want %d %v", next.Winner, next.Uncovered, tc.winner, tc.uncovered)
+			}
+			if tc.winner != 0 && next.Turn != 1 {
+				t.Errorf("turn passed to %d after the game ended", next.Turn)
+			}
+		})
+	}
+}
+
+func TestApplyLeavesReceiverUnchanged(t *testing.T) {
+	s := State{Board: uncoverBoard(), Turn: 1}
+	before := s.Board.Clone()
+	if _, err := s.Apply(Shift(0, 0, 2, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(s.Board, before) {
+		t.Error("Apply changed the board it was given")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	p1, p2 := Gobblet{Size: 1, Owner: 1}, Gobblet{Size: 1, Owner: 2}
+	row := func(owner Gobblet, r int) Board {
+		b := NewBoard(3)
+		for c := range 3 {
+			b[r][c] = Stack{owner}
+		}
+		return b
+	}
+	both := row(p1, 0)
+	for c := range 3 {
+		both[2][c] = Stack{p2}
+	}
+
+	for _, tc := range []struct {
+		name  string
+		board Board
+		mover int
+		want  int
+	}{
+		{"no line", NewBoard(3), 1, 0},
+		{"mover's line", row(p1, 1), 1, 1},
+		{"opponent's line", row(p2, 1), 1, 2},
+		{"both lines go to the opponent", both, 1, 2},
+		{"both lines, Player 2 moved", both, 2, 1},
+		{"column", boardWith(3, Stack{p2}, Stack{}, Stack{}, Stack{p2}, Stack{}, Stack{}, Stack{p2}), 2, 2},
+		{"diagonal", boardWith(3, Stack{p1}, Stack{}, Stack{}, Stack{}, Stack{p1}, Stack{}, Stack{}, Stack{}, Stack{p1}), 2, 1},
+		{"covered line", boardWith(3, Stack{p1, {Size: 2, Owner: 2}}, Stack{p1}, Stack{p1}), 1, 0},
+	} {
+		if got := Resolve(tc.board, tc.mover); got != tc.want {
+			t.Errorf("%s: Resolve(mover %d) = %d, want %d", tc.name, tc.mover, got, tc.want)
+		}
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	mine, theirs := Gobblet{Size: 1, Owner: 1}, Gobblet{Size: 1, Owner: 2}
+	large := Gobblet{Size: 3, Owner: 1}
+	for _, tc := range []struct {
+		name string
+		s    State
+		move Move
+		want error
+	}{
+		{"game over", State{Board: NewBoard(3), Turn: 1, Winner: 2}, Place(0, 0, 1), ErrGameOver},
+		{"off the board", State{Board: NewBoard(3), Turn: 1}, Place(3, 0, 1), ErrOutOfBounds},
+		{"from off the board", State{Board: NewBoard(3), Turn: 1}, Shift(0, -2, 0, 0), ErrOutOfBounds},
+		{"size too large", State{Board: NewBoard(3), Turn: 1}, Place(0, 0, 4), ErrBadSize},
+		{"size zero", State{Board: NewBoard(3), Turn: 1}, Place(0, 0, 0), ErrBadSize},
+		{"reserve used up", State{Board: boardWith(3, Stack{mine}, Stack{mine}), Turn: 1}, Place(2, 2, 1), ErrReserveEmpty},
+		{"classic entry on a piece", State{Board: boardWith(4, Stack{mine}), Turn: 1, Variant: Classic}, Place(0, 0, 4), ErrEntryNotEmpty},
+		{"nothing to move", State{Board: NewBoard(3), Turn: 1}, Shift(0, 0, 1, 1), ErrNoPiece},
+		{"opponent's piece", State{Board: boardWith(3, Stack{theirs}), Turn: 1}, Shift(0, 0, 1, 1), ErrNotYourPiece},
+		{"smaller on larger", State{Board: boardWith(3, Stack{large}), Turn: 2}, Place(0, 0, 1), ErrSmallerOnLarger},
+		{"same size", State{Board: boardWith(3, Stack{theirs}), Turn: 1}, Place(0, 0, 1), ErrSmallerOnLarger},
+		{"reserve gobble under house rules", State{Board: boardWith(3, Stack{theirs}), Turn: 1, Rules: &Rules{Size: 3, Sizes: 3, NoReserveGobble: true}}, Place(0, 0, 3), ErrReserveGobble},
+		{"gobble without a threat", State{Board: boardWith(3, Stack{theirs}, Stack{}, Stack{}, Stack{}, Stack{large}), Turn: 1, Rules: &Rules{Size: 3, Sizes: 3, GobbleOnThreat: true}}, Shift(1, 1, 0, 0), ErrNotThreatening},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := tc.s.Apply(tc.move)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("Apply(%+v) = %v, want %v", tc.move, err, tc.want)
+			}
+			if !Equal(next.Board, tc.s.Board) || next.Turn != tc.s.Turn {
+				t.Error("a refused move changed the state")
+			}
+		})
+	}
+}
+
+func TestApplyAllowedGobbles(t *testing.T) {
+	theirs := Gobblet{Size: 1, Owner: 2}
+	// Player 2 is one short of the top row, so the house rule allows the gobble
+	threat := State{Board: boardWith(3, Stack{theirs}, Stack{theirs}, Stack{}, Stack{}, Stack{{Size: 3, Owner: 1}}), Turn: 1, Rules: &Rules{Size: 3, Sizes: 3, GobbleOnThreat: true}}
+	if _, err := threat.Apply(Shift(1, 1, 0, 0)); err != nil {
+		t.Errorf("gobbling a threatening piece: %v", err)
+	}
+	// Classic lets a new piece gobble in a line the opponent is one short of
+	classic := State{Board: boardWith(4, Stack{theirs}, Stack{theirs}, Stack{theirs}), Turn: 1, Variant: Classic}
+	if _, err := classic.Apply(Place(0, 0, 4)); err != nil {
+		t.Errorf("classic entry gobbling a threat: %v", err)
+	}
+}
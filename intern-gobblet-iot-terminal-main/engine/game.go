@@ -0,0 +1,85 @@
+package engine
+
+import "fmt"
+
+// RepetitionLimit is how many times a position may occur before the game
+// is drawn.
+const RepetitionLimit = 3
+
+// Terminations of a game ended by a move.
+const (
+	TerminationLine       = "line"           // a player owns a complete line
+	TerminationUncovered  = "uncovered line" // the loser lifted a piece off the winner's line
+	TerminationRepetition = "repetition"     // the same position RepetitionLimit times
+	TerminationStalemate  = "stalemate"      // the player to move has no legal move
+)
+
+// Result is how a move ended the game.
+type Result struct {
+	Winner      int // 0 for a draw
+	Termination string
+}
+
+// Outcome writes the result in score notation: 1-0, 0-1 or ½-½.
+func (r Result) Outcome() string {
+	switch r.Winner {
+	case 1:
+		return "1-0"
+	case 2:
+		return "0-1"
+	}
+	return "½-½"
+}
+
+// Game is a position with the bookkeeping every front end keeps around
+// it, so terminals, bots and the referee end games the same way.
+type Game struct {
+	State
+	Moves       int            // moves played so far
+	Repetitions map[string]int // how often each position occurred, by RepetitionKey
+	Result      *Result        // set once a move ended the game
+}
+
+// RepetitionKey identifies a position, the board and the player to move,
+// for repetition counting.
+func RepetitionKey(b Board, toMove int) string {
+	return fmt.Sprintf("%016x", State{Board: b, Turn: toMove}.Hash())
+}
+
+// Play applies m for the player to move: it counts the move and the
+// position it leaves, and sets the result when the move wins, repeats the
+// position RepetitionLimit times or leaves the opponent nothing to play.
+// The turn passes only while the game goes on. The receiver and its
+// repetition counts are left unchanged, so a refused move touches nothing.
+func (g Game) Play(m Move) (Game, error) {
+	if g.Result != nil {
+		return g, ErrGameOver
+	}
+	mover := g.Turn
+	next, err := g.State.Apply(m)
+	if err != nil {
+		return g, err
+	}
+
+	out := Game{State: next, Moves: g.Moves + 1, Repetitions: make(map[string]int, len(g.Repetitions)+1)}
+	for k, v := range g.Repetitions {
+		out.Repetitions[k] = v
+	}
+	key := RepetitionKey(next.Board, 3-mover)
+	out.Repetitions[key]++
+
+	switch {
+	case next.Uncovered:
+		out.Result = &Result{Winner: next.Winner, Termination: TerminationUncovered}
+	case next.Winner != 0:
+		out.Result = &Result{Winner: next.Winner, Termination: TerminationLine}
+	case out.Repetitions[key] >= RepetitionLimit:
+		out.Result = &Result{Termination: TerminationRepetition}
+	case next.Stalemate():
+		out.Result = &Result{Termination: TerminationStalemate}
+	}
+	if out.Result != nil {
+		out.Turn = mover
+	}
+	return out, nil
+}
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGamePlay(t *testing.T) {
+	g := Game{State: State{Board: NewBoard(3), Turn: 1}}
+	next, err := g.Play(Place(1, 1, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Moves != 1 || next.Turn != 2 || next.Result != nil {
+		t.Errorf("after one move: moves %d, turn %d, result %v", next.Moves, next.Turn, next.Result)
+	}
+	if next.Repetitions[RepetitionKey(next.Board, 2)] != 1 {
+		t.Errorf("the new position wasn't counted: %v", next.Repetitions)
+	}
+	if len(g.Repetitions) != 0 || g.Board[1][1] != nil {
+		t.Error("Play changed the game it was given")
+	}
+	if _, err := next.Play(Place(1, 1, 2)); !errors.Is(err, ErrSmallerOnLarger) {
+		t.Errorf("an illegal move: %v", err)
+	}
+}
+
+func TestGamePlayEndings(t *testing.T) {
+	// A full board with no line once Player 1 fills c3, with one size so
+	// nothing can be gobbled
+	full := NewBoard(3)
+	for i, owner := range []int{1, 2, 1, 1, 2, 2, 2, 1} {
+		full[i/3][i%3] = Stack{{Size: 1, Owner: owner}}
+	}
+	oneSize := &Rules{Size: 3, Sizes: 1, Reserves: 5}
+
+	for _, tc := range []struct {
+		name string
+		g    Game
+		move Move
+		want Result
+	}{
+		{"line", Game{State: State{Board: boardWith(3, Stack{{Size: 1, Owner: 1}}, Stack{{Size: 1, Owner: 1}}), Turn: 1}}, Place(0, 2, 2), Result{Winner: 1, Termination: TerminationLine}},
+		{"uncovered line", Game{State: State{Board: uncoverBoard(), Turn: 1}}, Shift(0, 0, 2, 2), Result{Winner: 2, Termination: TerminationUncovered}},
+		{"stalemate", Game{State: State{Board: full, Turn: 1, Rules: oneSize}}, Place(2, 2, 1), Result{Termination: TerminationStalemate}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := tc.g.Play(tc.move)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.Result == nil || *next.Result != tc.want {
+				t.Fatalf("result %v, want %v", next.Result, tc.want)
+			}
+			if next.Turn != tc.g.Turn {
+				t.Errorf("the turn passed to %d after the game ended", next.Turn)
+			}
+			if _, err := next.Play(tc.move); !errors.Is(err, ErrGameOver) {
+				t.Errorf("a move after the end: %v, want %v", err, ErrGameOver)
+			}
+		})
+	}
+}
+
+func TestResultOutcome(t *testing.T) {
+	for r, want := range map[Result]string{{Winner: 1}: "1-0", {Winner: 2}: "0-1", {}: "½-½"} {
+		if got := r.Outcome(); got != want {
+			t.Errorf("%+v.Outcome() = %q, want %q", r, got, want)
+		}
+	}
+}
+
+func TestRepetitionCounting(t *testing.T) {
+	g := Game{State: State{Board: NewBoard(3), Turn: 1}}
+	opening := []Move{Place(0, 0, 1), Place(2, 2, 1)}
+	// Both players shuffle a piece out and back, so the position after
+	// the opening returns every four moves
+	shuffle := []Move{Shift(0, 0, 0, 1), Shift(2, 2, 2, 1), Shift(0, 1, 0, 0), Shift(2, 1, 2, 2)}
+	moves := append(opening, append(append(shuffle, shuffle...), shuffle[:2]...)...)
+
+	for _, tc := range []struct {
+		ply  int // after this many moves
+		seen int // times the position on the board has occurred
+		draw bool
+	}{
+		{2, 1, false},
+		{3, 1, false},
+		{6, 2, false},
+		{7, 2, false},
+		{9, 2, false},
+		{10, 3, true},
+	} {
+		for g.Moves < tc.ply {
+			var err error
+			if g, err = g.Play(moves[g.Moves]); err != nil {
+				t.Fatalf("move %d: %v", g.Moves+1, err)
+			}
+		}
+		toMove := 1 + tc.ply%2 // Player 1 made the odd moves
+		key := RepetitionKey(g.Board, toMove)
+		if got := g.Repetitions[key]; got != tc.seen {
+			t.Errorf("after move %d the position was seen %d times, want %d", tc.ply, got, tc.seen)
+		}
+		drawn := g.Result != nil && *g.Result == Result{Termination: TerminationRepetition}
+		if drawn != tc.draw {
+			t.Errorf("after move %d: result %v, want a draw %v", tc.ply, g.Result, tc.draw)
+		}
+	}
+}
+
+func TestRepetitionKeyIncludesTurn(t *testing.T) {
+	b := boardWith(3, Stack{{Size: 1, Owner: 1}})
+	if RepetitionKey(b, 1) == RepetitionKey(b, 2) {
+		t.Error("the same board with a different player to move has the same key")
+	}
+	if RepetitionKey(b, 1) != RepetitionKey(b.Clone(), 1) {
+		t.Error("equal positions have different keys")
+	}
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Position strings are a compact, FEN-like text form of a State, e.g.
+//
+//	Ab,.,./.,C,./.,.,a 2
+//
+// Rows are separated by "/" from the top and cells by ",". Each cell lists
+// its stack bottom to top, one letter per piece: the letter gives the size
+// (a is the smallest) and upper case is player 1, lower case player 2. An
+// empty cell is ".". The number after the board is the player to move, and
+// a third field names the variant when it isn't junior. Reserves are not
+// written out: they follow from the pieces on the board.
+
+// EncodePosition writes s as a position string.
+func EncodePosition(s State) string {
+	var sb strings.Builder
+	for i, row := range s.Board {
+		if i > 0 {
+			sb.WriteByte('/')
+		}
+		for j, stack := range row {
+			if j > 0 {
+				sb.WriteByte(',')
+			}
+			if len(stack) == 0 {
+				sb.WriteByte('.')
+			}
+			for _, g := range stack {
+				letter := byte('a' + g.Size - 1)
+				if g.Owner == 1 {
+					letter = byte('A' + g.Size - 1)
+				}
+				sb.WriteByte(letter)
+			}
+		}
+	}
+	fmt.Fprintf(&sb, " %d", s.Turn)
+	if s.Variant != "" && s.Variant != Junior {
+		sb.WriteString(" " + string(s.Variant))
+	}
+	return sb.String()
+}
+
+// DecodePosition reads a position string written by EncodePosition. The
+// winner is worked out from the board.
+func DecodePosition(text string) (State, error) {
+	fields := strings.Fields(text)
+	if len(fields) < 2 || len(fields) > 3 {
+		return State{}, errors.New("position needs a board and the player to move")
+	}
+	var s State
+	if len(fields) == 3 {
+		s.Variant = Variant(fields[2])
+		if !s.Variant.Valid() {
+			return State{}, fmt.Errorf("unknown variant %q", fields[2])
+		}
+	}
+	turn, err := strconv.Atoi(fields[1])
+	if err != nil || (turn != 1 && turn != 2) {
+		return State{}, fmt.Errorf("player to move must be 1 or 2, not %q", fields[1])
+	}
+	s.Turn = turn
+
+	rows := strings.Split(fields[0], "/")
+	s.Board = NewBoard(len(rows))
+	for i, row := range rows {
+		cells := strings.Split(row, ",")
+		if len(cells) != len(rows) {
+			return State{}, fmt.Errorf("row %d has %d cells, want %d", i+1, len(cells), len(rows))
+		}
+		for j, cell := range cells {
+			if cell == "." {
+				continue
+			}
+			if cell == "" {
+				return State{}, fmt.Errorf("row %d, cell %d is blank, write an empty cell as '.'", i+1, j+1)
+			}
+			for _, r := range cell {
+				var g Gobblet
+				switch {
+				case r >= 'A' && r <= 'Z':
+					g = Gobblet{Size: int(r-'A') + 1, Owner: 1}
+				case r >= 'a' && r <= 'z':
+					g = Gobblet{Size: int(r-'a') + 1, Owner: 2}
+				default:
+					return State{}, fmt.Errorf("bad piece %q in row %d", r, i+1)
+				}
+				if g.Size > s.rules().Sizes {
+					return State{}, fmt.Errorf("%w: %q in row %d", ErrBadSize, r, i+1)
+				}
+				if top, ok := s.Board[i][j].Top(); ok && top.Size >= g.Size {
+					return State{}, fmt.Errorf("%w: row %d, cell %d", ErrSmallerOnLarger, i+1, j+1)
+				}
+				s.Board[i][j] = append(s.Board[i][j], g)
+			}
+		}
+	}
+	if !s.Board.Square() {
+		return State{}, fmt.Errorf("boards must be %dx%d to %dx%d", MinSize, MinSize, MaxSize, MaxSize)
+	}
+	s.Winner = Resolve(s.Board, 3-s.Turn)
+	return s, nil
+}
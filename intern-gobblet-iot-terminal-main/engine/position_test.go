@@ -0,0 +1,55 @@
+package engine
+
+import "testing"
+
+func TestPositionRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    State
+		text string
+	}{
+		{"empty junior", State{Board: NewBoard(3), Turn: 1}, ".,.,./.,.,./.,.,. 1"},
+		{"junior stacks", State{Board: boardWith(3, Stack{{Size: 1, Owner: 1}, {Size: 2, Owner: 2}}, Stack{}, Stack{}, Stack{}, Stack{{Size: 3, Owner: 1}}), Turn: 2}, "Ab,.,./.,C,./.,.,. 2"},
+		{"classic", State{Board: boardWith(4, Stack{{Size: 1, Owner: 2}, {Size: 2, Owner: 1}, {Size: 4, Owner: 2}}), Turn: 1, Variant: Classic}, "aBd,.,.,./.,.,.,./.,.,.,./.,.,.,. 1 classic"},
+		{"5x5 board", State{Board: boardWith(5, Stack{}, Stack{}, Stack{}, Stack{}, Stack{{Size: 2, Owner: 1}}), Turn: 2}, ".,.,.,.,B/.,.,.,.,./.,.,.,.,./.,.,.,.,./.,.,.,.,. 2"},
+		{"won", State{Board: boardWith(3, Stack{{Size: 1, Owner: 1}}, Stack{{Size: 1, Owner: 1}}, Stack{{Size: 2, Owner: 1}}), Turn: 1, Winner: 1}, "A,A,B/.,.,./.,.,. 1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := EncodePosition(tc.s); got != tc.text {
+				t.Errorf("EncodePosition() = %q, want %q", got, tc.text)
+			}
+			got, err := DecodePosition(tc.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(got.Board, tc.s.Board) || got.Turn != tc.s.Turn || got.Variant.String() != tc.s.Variant.String() || got.Winner != tc.s.Winner {
+				t.Errorf("DecodePosition(%q) = %+v, want %+v", tc.text, got, tc.s)
+			}
+			if again := EncodePosition(got); again != tc.text {
+				t.Errorf("re-encoded as %q", again)
+			}
+		})
+	}
+}
+
+func TestDecodePositionRejectsMalformed(t *testing.T) {
+	for _, text := range []string{
+		"",
+		".,.,./.,.,./.,.,.",             // no player to move
+		".,.,./.,.,./.,.,. 3",           // no such player
+		".,.,./.,.,./.,.,. x",           // not a number
+		".,.,./.,.,./.,.,. 1 giant",     // unknown variant
+		".,.,./.,.,./.,.,. 1 classic x", // too many fields
+		".,./.,. 1",                     // too small
+		".,.,./.,./.,.,. 1",             // ragged row
+		"A1,.,./.,.,./.,.,. 1",          // not a piece letter
+		"D,.,./.,.,./.,.,. 1",           // size 4 in junior
+		"Ba,.,./.,.,./.,.,. 1",          // smaller on larger
+		"AA,.,./.,.,./.,.,. 1",          // same size stacked
+		",.,./.,.,./.,.,. 1",            // empty cell not written as "."
+	} {
+		if s, err := DecodePosition(text); err == nil {
+			t.Errorf("DecodePosition(%q) = %+v, want an error", text, s)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by Validate.
+var (
+	ErrBadVariant    = errors.New("unknown variant")
+	ErrBadRules      = errors.New("rules outside what the engine supports")
+	ErrBadBoard      = errors.New("board is not square or has an unsupported size")
+	ErrBadPiece      = errors.New("piece has no such size or owner")
+	ErrStackTooDeep  = errors.New("stack is deeper than the number of piece sizes")
+	ErrBadStack      = errors.New("stack does not get larger towards the top")
+	ErrTooManyPieces = errors.New("more pieces than the variant allows")
+	ErrBadTurn       = errors.New("turn must be player 1 or 2")
+)
+
+// Validate checks the invariants every reachable state keeps, so states
+// received from other terminals can be refused when corrupted or forged:
+// stacks no deeper than the number of sizes, each player within their
+// pieces of every size, and so on. It does not replay the game: a state can
+// pass and still be unreachable.
+func Validate(s State) error {
+	if !s.Variant.Valid() {
+		return fmt.Errorf("%w %q", ErrBadVariant, s.Variant)
+	}
+	if !s.Board.Square() {
+		return ErrBadBoard
+	}
+	if s.Turn != 1 && s.Turn != 2 {
+		return fmt.Errorf("%w, got %d", ErrBadTurn, s.Turn)
+	}
+
+	rules := s.rules()
+	if err := rules.check(); err != nil {
+		return err
+	}
+	for i := range s.Board {
+		for j, stack := range s.Board[i] {
+			if len(stack) > rules.Sizes {
+				return fmt.Errorf("%w: %d pieces at row %d col %d", ErrStackTooDeep, len(stack), i, j)
+			}
+			for k, g := range stack {
+				if g.Size < 1 || g.Size > rules.Sizes || (g.Owner != 1 && g.Owner != 2) {
+					return fmt.Errorf("%w: size %d owner %d at row %d col %d", ErrBadPiece, g.Size, g.Owner, i, j)
+				}
+				if k > 0 && stack[k-1].Size >= g.Size {
+					return fmt.Errorf("%w at row %d col %d", ErrBadStack, i, j)
+				}
+			}
+		}
+	}
+
+	for player := 1; player <= 2; player++ {
+		for size, left := range rules.Reserve(s.Board, player) {
+			if left < 0 {
+				return fmt.Errorf("%w: player %d has %d of size %d", ErrTooManyPieces, player, rules.pieces(s.Board)-left, size+1)
+			}
+		}
+	}
+	return nil
+}
+
+// check keeps rules within the engine's tables: board sizes it plays, no
+// more piece sizes than the Zobrist keys cover, and a reserve count that
+// fits in rulesKey.
+func (r Rules) check() error {
+	switch {
+	case r.Size < MinSize || r.Size > MaxSize:
+		return fmt.Errorf("%w: boards are %d to %d wide, got %d", ErrBadRules, MinSize, MaxSize, r.Size)
+	case r.Sizes < 1 || r.Sizes > maxPieceSizes:
+		return fmt.Errorf("%w: pieces come in 1 to %d sizes, got %d", ErrBadRules, maxPieceSizes, r.Sizes)
+	case r.Reserves < 0 || r.Reserves > MaxSize*MaxSize:
+		return fmt.Errorf("%w: %d reserve stacks", ErrBadRules, r.Reserves)
+	}
+	return nil
+}
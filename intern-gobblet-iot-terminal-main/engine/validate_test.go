@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+// boardWith returns an empty n×n board with stacks placed from the top
+// left, one per cell.
+func boardWith(n int, stacks ...Stack) Board {
+	b := NewBoard(n)
+	for k, st := range stacks {
+		b[k/n][k%n] = st
+	}
+	return b
+}
+
+func TestValidate(t *testing.T) {
+	small, large := Gobblet{Size: 1, Owner: 1}, Gobblet{Size: 3, Owner: 1}
+	for _, tc := range []struct {
+		name string
+		s    State
+		want error
+	}{
+		{"empty junior", State{Board: NewBoard(3), Turn: 1}, nil},
+		{"two small pieces in junior", State{Board: boardWith(3, Stack{small}, Stack{small}), Turn: 2}, nil},
+		{"three small pieces in junior", State{Board: boardWith(3, Stack{small}, Stack{small}, Stack{small}), Turn: 2}, ErrTooManyPieces},
+		{"three of a size under house rules", State{Board: boardWith(3, Stack{large}, Stack{large}, Stack{large}), Turn: 1, Rules: &Rules{Size: 3, Sizes: 3, Memory: true}}, ErrTooManyPieces},
+		{"four small pieces on a 5x5 board", State{Board: boardWith(5, Stack{small}, Stack{small}, Stack{small}, Stack{small}), Turn: 2}, nil},
+		{"five small pieces on a 5x5 board", State{Board: boardWith(5, Stack{small}, Stack{small}, Stack{small}, Stack{small}, Stack{small}), Turn: 2}, ErrTooManyPieces},
+		{"three of a size in classic", State{Board: boardWith(4, Stack{small}, Stack{small}, Stack{small}), Turn: 2, Variant: Classic}, nil},
+		{"four of a size in classic", State{Board: boardWith(4, Stack{small}, Stack{small}, Stack{small}, Stack{small}), Turn: 2, Variant: Classic}, ErrTooManyPieces},
+		{"tower of one size", State{Board: boardWith(3, Stack{small, {Size: 1, Owner: 2}}), Turn: 1}, ErrBadStack},
+		{"stack too deep", State{Board: boardWith(3, Stack{small, {Size: 2, Owner: 2}, large, {Size: 4, Owner: 2}}), Turn: 1}, ErrStackTooDeep},
+		{"piece without an owner", State{Board: boardWith(3, Stack{{Size: 2}}), Turn: 1}, ErrBadPiece},
+		{"piece too large", State{Board: boardWith(3, Stack{{Size: 4, Owner: 1}}), Turn: 2}, ErrBadPiece},
+		{"no player to move", State{Board: NewBoard(3), Turn: 3}, ErrBadTurn},
+		{"unknown variant", State{Board: NewBoard(3), Turn: 1, Variant: "giant"}, ErrBadVariant},
+		{"five piece sizes", State{Board: boardWith(3, Stack{{Size: 5, Owner: 1}}), Turn: 2, Rules: &Rules{Size: 3, Sizes: 5}}, ErrBadRules},
+		{"no piece sizes", State{Board: NewBoard(3), Turn: 1, Rules: &Rules{Size: 3}}, ErrBadRules},
+		{"default board too large", State{Board: NewBoard(3), Turn: 1, Rules: &Rules{Size: 12, Sizes: 3}}, ErrBadRules},
+		{"negative reserves", State{Board: NewBoard(4), Turn: 1, Rules: &Rules{Size: 4, Sizes: 4, Reserves: -1}}, ErrBadRules},
+		{"ragged board", State{Board: Board{{{}, {}, {}}, {{}, {}}, {{}, {}, {}}}, Turn: 1}, ErrBadBoard},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.s)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("Validate() = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestJuniorReserveRunsOut(t *testing.T) {
+	s := State{Board: NewBoard(3), Turn: 1}
+	for _, m := range []Move{Place(0, 0, 1), Place(2, 2, 3), Place(0, 2, 1), Place(2, 0, 3)} {
+		var err error
+		if s, err = s.Apply(m); err != nil {
+			t.Fatalf("%v: %v", m, err)
+		}
+	}
+	if got := s.rules().Reserve(s.Board, 1); got[0] != 0 || got[1] != 2 || got[2] != 2 {
+		t.Errorf("player 1's reserve is %v, want [0 2 2]", got)
+	}
+	if _, err := s.Apply(Place(1, 1, 1)); !errors.Is(err, ErrReserveEmpty) {
+		t.Errorf("a third small piece: %v, want %v", err, ErrReserveEmpty)
+	}
+	for _, m := range LegalMoves(s, 1) {
+		if m.IsPlacement() && m.Size == 1 {
+			t.Errorf("LegalMoves offers %v with no small piece left", m)
+		}
+	}
+}
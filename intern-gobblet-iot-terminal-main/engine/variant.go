@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Variant selects a rule set. The zero value plays Junior.
+type Variant string
+
+const (
+	// Junior is Gobblet Gobblers: a 3x3 board and three piece sizes.
+	Junior Variant = "junior"
+	// Classic is the original Gobblet: a 4x4 board, four sizes and three
+	// nested reserve stacks per player.
+	Classic Variant = "classic"
+)
+
+// Rules are the parameters that differ between variants.
+type Rules struct {
+	Size  int // default board width and height
+	Sizes int // piece sizes, from 1 to Sizes
+	// Reserves is the number of nested reserve stacks per player. Each holds
+	// one piece of every size and only its top piece can be played. 0 means
+	// the pieces lie loose beside the board, one less of each size than the
+	// board is wide: two on the 3x3 board, as in Gobblet Gobblers.
+	Reserves int
+	// EntryOnEmpty makes new pieces enter on an empty cell, unless they
+	// gobble an opponent's piece in a line the opponent is one short of.
+	EntryOnEmpty bool
+	// CoverReveal lets a piece that uncovers an opponent's line save the
+	// game by landing on that line.
+	CoverReveal bool
+
+	// House rules, off in every variant unless switched on.
+
+	// Memory hides the pieces under each top piece, so players have to
+	// remember them. Front ends apply it when drawing.
+	Memory bool `json:",omitempty"`
+	// NoReserveGobble stops new pieces from covering an opponent's piece.
+	NoReserveGobble bool `json:",omitempty"`
+	// GobbleOnThreat only lets a piece cover an opponent's piece that is in
+	// a line the opponent is one short of.
+	GobbleOnThreat bool `json:",omitempty"`
+}
+
+// Hash identifies a rule set, so terminals can check they play the same
+// rules.
+func (r Rules) Hash() string {
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// rules returns the rules s is played by.
+func (s State) rules() Rules {
+	if s.Rules != nil {
+		return *s.Rules
+	}
+	return s.Variant.Rules()
+}
+
+// Valid reports whether v names a known variant.
+func (v Variant) Valid() bool {
+	return v == "" || v == Junior || v == Classic
+}
+
+// String names the variant, reporting the zero value as junior.
+func (v Variant) String() string {
+	if v == "" {
+		return string(Junior)
+	}
+	return string(v)
+}
+
+// Rules returns the rule parameters of v.
+func (v Variant) Rules() Rules {
+	if v == Classic {
+		return Rules{Size: 4, Sizes: 4, Reserves: 3, EntryOnEmpty: true, CoverReveal: true}
+	}
+	return Rules{Size: 3, Sizes: 3}
+}
+
+// pieces returns how many pieces of each size a player has in a game on b.
+func (r Rules) pieces(b Board) int {
+	if r.Reserves > 0 {
+		return r.Reserves
+	}
+	return max(b.Size()-1, 1)
+}
+
+// Reserve returns how many pieces of each size player has not brought onto
+// b yet, indexed by size-1.
+func (r Rules) Reserve(b Board, player int) []int {
+	left := make([]int, r.Sizes)
+	for i := range left {
+		left[i] = r.pieces(b)
+	}
+	for i := range b {
+		for j := range b[i] {
+			for _, g := range b[i][j] {
+				if g.Owner == player && g.Size >= 1 && g.Size <= r.Sizes {
+					left[g.Size-1]--
+				}
+			}
+		}
+	}
+	return left
+}
+
+// InReserve reports whether player can bring a piece of size onto b. With
+// reserve stacks it must be on top of one of them: because the stacks are
+// nested, that is the case when more pieces of size than of size+1 are left.
+func (r Rules) InReserve(b Board, player, size int) bool {
+	left := r.Reserve(b, player)
+	if r.Reserves == 0 {
+		return left[size-1] > 0
+	}
+	above := 0
+	if size < r.Sizes {
+		above = left[size]
+	}
+	return left[size-1] > above
+}
@@ -0,0 +1,87 @@
+package engine
+
+// maxPieceSizes is the most piece sizes any variant uses. Stacks grow
+// strictly, so it also bounds their depth.
+const maxPieceSizes = 4
+
+// Zobrist keys, one per (cell, depth in the stack, owner, size) plus one
+// for Player 2 to move. They are generated from a fixed seed, so every
+// terminal computes the same hash for the same position.
+var (
+	zobristPieces [MaxSize * MaxSize][maxPieceSizes][2][maxPieceSizes]uint64
+	zobristTurn   uint64
+)
+
+// mix is the splitmix64 finalizer.
+func mix(z uint64) uint64 {
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+func init() {
+	seed := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 { // splitmix64
+		seed += 0x9e3779b97f4a7c15
+		return mix(seed)
+	}
+	for cell := range zobristPieces {
+		for depth := range zobristPieces[cell] {
+			for owner := range zobristPieces[cell][depth] {
+				for size := range zobristPieces[cell][depth][owner] {
+					zobristPieces[cell][depth][owner][size] = next()
+				}
+			}
+		}
+	}
+	zobristTurn = next()
+}
+
+// ZobristKey is the key of piece g at the given depth (0 is the bottom) of
+// the stack on row, col. XOR it in or out of a hash to update it as pieces
+// are placed and lifted.
+func ZobristKey(row, col, depth int, g Gobblet) uint64 {
+	return zobristPieces[row*MaxSize+col][depth][g.Owner-1][g.Size-1]
+}
+
+// Hash returns the Zobrist hash of the pieces on b. The board must satisfy
+// Validate.
+func (b Board) Hash() uint64 {
+	var h uint64
+	for i := range b {
+		for j := range b[i] {
+			for depth, g := range b[i][j] {
+				h ^= ZobristKey(i, j, depth, g)
+			}
+		}
+	}
+	return h
+}
+
+// rulesKey tells rule sets and board sizes apart in State.Hash. Junior on
+// its 3x3 board adds nothing, so hashes saved before the rules were hashed,
+// in opening books and repetition counts, stay valid. New fields of Rules
+// need a bit here.
+func rulesKey(r Rules, size int) uint64 {
+	r.Size = size
+	if r == Junior.Rules() {
+		return 0
+	}
+	var flags uint64
+	for i, on := range [...]bool{r.EntryOnEmpty, r.CoverReveal, r.Memory, r.NoReserveGobble, r.GobbleOnThreat} {
+		if on {
+			flags |= 1 << i
+		}
+	}
+	return mix(uint64(size) | uint64(r.Sizes)<<8 | uint64(r.Reserves)<<16 | flags<<24)
+}
+
+// Hash returns the Zobrist hash of the position: the board, who moves and
+// the rules it is played by.
+func (s State) Hash() uint64 {
+	h := s.Board.Hash() ^ rulesKey(s.rules(), s.Board.Size())
+	if s.Turn == 2 {
+		h ^= zobristTurn
+	}
+	return h
+}
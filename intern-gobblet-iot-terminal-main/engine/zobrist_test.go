@@ -0,0 +1,68 @@
+package engine
+
+import "testing"
+
+func play(t *testing.T, s State, moves ...Move) State {
+	t.Helper()
+	for _, m := range moves {
+		var err error
+		if s, err = s.Apply(m); err != nil {
+			t.Fatalf("%+v: %v", m, err)
+		}
+	}
+	return s
+}
+
+func TestHashTranspositions(t *testing.T) {
+	start := State{Board: NewBoard(3), Turn: 1}
+	a := play(t, start, Place(0, 0, 1), Place(2, 2, 2), Place(1, 1, 3), Place(0, 2, 1))
+	b := play(t, start, Place(1, 1, 3), Place(0, 2, 1), Place(0, 0, 1), Place(2, 2, 2))
+	if a.Hash() != b.Hash() {
+		t.Error("the same position reached in another order hashes differently")
+	}
+	// The same pieces arrived at by a move rather than a placement
+	c := play(t, start, Place(0, 0, 1), Place(2, 2, 2), Shift(0, 0, 1, 0), Place(2, 0, 2))
+	d := play(t, start, Place(0, 1, 1), Place(2, 0, 2), Shift(0, 1, 1, 0), Place(2, 2, 2))
+	if !Equal(c.Board, d.Board) || c.Turn != d.Turn {
+		t.Fatal("the two lines should reach the same position")
+	}
+	if c.Hash() != d.Hash() {
+		t.Error("a transposition through moves hashes differently")
+	}
+
+	if a.Hash() == (State{Board: a.Board, Turn: 3 - a.Turn}).Hash() {
+		t.Error("the player to move doesn't change the hash")
+	}
+	if a.Hash() == play(t, a, Place(2, 0, 3)).Hash() {
+		t.Error("a move doesn't change the hash")
+	}
+}
+
+func TestHashDependsOnRules(t *testing.T) {
+	b := boardWith(4, Stack{{Size: 1, Owner: 1}}, Stack{{Size: 2, Owner: 2}})
+	hashes := map[uint64]string{}
+	for name, s := range map[string]State{
+		"junior rules":       {Board: b, Turn: 1},
+		"classic":            {Board: b, Turn: 1, Variant: Classic},
+		"memory rule":        {Board: b, Turn: 1, Rules: &Rules{Size: 3, Sizes: 3, Memory: true}},
+		"no reserve gobbles": {Board: b, Turn: 1, Rules: &Rules{Size: 3, Sizes: 3, NoReserveGobble: true}},
+		"entry on empty":     {Board: b, Turn: 1, Rules: &Rules{Size: 4, Sizes: 4, Reserves: 3, EntryOnEmpty: true}},
+	} {
+		h := s.Hash()
+		if other, ok := hashes[h]; ok {
+			t.Errorf("%s and %s hash the same", name, other)
+		}
+		hashes[h] = name
+	}
+
+	// The same pieces on a larger board are another position
+	small := boardWith(3, Stack{{Size: 1, Owner: 1}})
+	large := boardWith(4, Stack{{Size: 1, Owner: 1}})
+	if (State{Board: small, Turn: 1}).Hash() == (State{Board: large, Turn: 1}).Hash() {
+		t.Error("a 3x3 and a 4x4 board with the same pieces hash the same")
+	}
+	// Junior on 3x3 hashes the pieces alone, as before the rules counted
+	if got := (State{Board: small, Turn: 1}).Hash(); got != small.Hash() {
+		t.Errorf("junior hash %x, want the board's %x", got, small.Hash())
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// eventsTopic is the prefix for lifecycle events, published on
+// gobblet/events/<type> so AWS IoT rules can route them by topic alone.
+const eventsTopic = "gobblet/events"
+
+const (
+	eventGameCreated  = "game_created"
+	eventPlayerJoined = "player_joined"
+	eventGameEnded    = "game_ended"
+)
+
+// EventHeader starts every event.
+type EventHeader struct {
+	Type   string    `json:"type"`
+	GameID string    `json:"game_id"`
+	Time   time.Time `json:"time"`
+}
+
+// GameCreatedEvent is published once by whoever creates a game.
+type GameCreatedEvent struct {
+	EventHeader
+	Preset  string           `json:"preset"`
+	Variant string           `json:"variant"`
+	Rated   bool             `json:"rated"`
+	Title   string           `json:"title,omitempty"`
+	Players []PlayerIdentity `json:"players,omitempty"` // set for games created through the API
+}
+
+// PlayerJoinedEvent is published by each player when they take their seat.
+type PlayerJoinedEvent struct {
+	EventHeader
+	Seat int    `json:"seat"`
+	Name string `json:"name,omitempty"` // pseudonymized when privacy.redact is set
+}
+
+// GameEndedEvent is published once, by the terminal that made the last move.
+type GameEndedEvent struct {
+	EventHeader
+	Outcome     string           `json:"outcome"` // "1-0", "0-1" or "½-½"
+	Termination string           `json:"termination"`
+	Winner      int              `json:"winner"` // 0 for a draw
+	Moves       int              `json:"moves"`
+	Rated       bool             `json:"rated"`
+	Players     []PlayerIdentity `json:"players,omitempty"`
+}
+
+// eventTypes lists every event for the generated schema.
+var eventTypes = []struct {
+	Name  string
+	Value any
+}{
+	{eventGameCreated, GameCreatedEvent{}},
+	{eventPlayerJoined, PlayerJoinedEvent{}},
+	{eventGameEnded, GameEndedEvent{}},
+}
+
+func newEventHeader(event, id string) EventHeader {
+	return EventHeader{Type: event, GameID: id, Time: time.Now().UTC()}
+}
+
+// publishEvent sends event on its type's topic. Events are not retained:
+// rules only act on them as they happen.
+func publishEvent(header EventHeader, event any) {
+	data, _ := json.Marshal(event)
+	token := mqttClient.Publish(eventsTopic+"/"+header.Type, 1, false, data)
+	if token.Wait() && token.Error() != nil {
+		fmt.Printf("⚠ Could not publish %s event: %v\n", header.Type, token.Error())
+	}
+}
+
+func gameCreatedEvent(id string, state GameState) GameCreatedEvent {
+	return GameCreatedEvent{
+		EventHeader: newEventHeader(eventGameCreated, id),
+		Preset:      state.Meta.Preset,
+		Variant:     state.Variant.String(),
+		Rated:       state.Meta.Rated,
+		Title:       state.Meta.Title,
+		Players:     state.Meta.Players,
+	}
+}
+
+func publishGameCreated() {
+	e := gameCreatedEvent(gameID, currentState())
+	publishEvent(e.EventHeader, e)
+}
+
+func publishPlayerJoined() {
+	e := PlayerJoinedEvent{
+		EventHeader: newEventHeader(eventPlayerJoined, gameID),
+		Seat:        playerID,
+		Name:        publicName(config.Conf.PlayerName),
+	}
+	publishEvent(e.EventHeader, e)
+}
+
+func publishGameEnded(r *Result) {
+	e := GameEndedEvent{
+		EventHeader: newEventHeader(eventGameEnded, gameID),
+		Outcome:     string(r.Outcome),
+		Termination: string(r.Termination),
+		Winner:      r.Winner(),
+		Moves:       moveCount,
+		Rated:       gameMeta.Rated,
+		Players:     gameMeta.Players,
+	}
+	publishEvent(e.EventHeader, e)
+}
+
+// jsonSchema describes t as JSON Schema, following encoding/json's rules for
+// field names, omitempty and embedded structs.
+func jsonSchema(t reflect.Type) map[string]any {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Pointer:
+		return jsonSchema(t.Elem())
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := []string{}
+		var add func(t reflect.Type)
+		add = func(t reflect.Type) {
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				if f.Anonymous {
+					add(f.Type)
+					continue
+				}
+				name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+				if !f.IsExported() || name == "-" {
+					continue
+				}
+				if name == "" {
+					name = f.Name
+				}
+				properties[name] = jsonSchema(f.Type)
+				if !strings.Contains(opts, "omitempty") {
+					required = append(required, name)
+				}
+			}
+		}
+		add(t)
+		return map[string]any{"type": "object", "properties": properties, "required": required}
+	}
+	return map[string]any{}
+}
+
+// runEventSchema prints the JSON Schema of every event, keyed by topic.
+func runEventSchema(args []string) {
+	defs := map[string]any{}
+	for _, e := range eventTypes {
+		schema := jsonSchema(reflect.TypeOf(e.Value))
+		schema["title"] = eventsTopic + "/" + e.Name
+		schema["properties"].(map[string]any)["type"] = map[string]any{"const": e.Name}
+		defs[e.Name] = schema
+	}
+	data, _ := json.MarshalIndent(map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$defs":   defs,
+	}, "", "  ")
+	fmt.Println(string(data))
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"goblets/engine"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// explorerEdge is one continuation from a position, with the results of the
+// archived games that played it.
+type explorerEdge struct {
+	Move  string
+	Next  string
+	Games int
+	Wins  [3]int // [0] draws, [1] player 1 wins, [2] player 2 wins
+}
+
+// positionKey encodes every stack on the board, bottom to top.
+func positionKey(b Board) string {
+	var sb strings.Builder
+	for i := range b {
+		for j := range b[i] {
+			for _, g := range b[i][j] {
+				fmt.Fprintf(&sb, "%d%d", g.Owner, g.Size)
+			}
+			sb.WriteByte('/')
+		}
+	}
+	return sb.String()
+}
+
+// describeMove names the action that turned prev into next.
+func describeMove(prev, next Board) string {
+	var from, to string
+	for i := range next {
+		for j := range next[i] {
+			switch {
+			case len(next[i][j]) > len(prev[i][j]):
+				top := next[i][j][len(next[i][j])-1]
+				to = fmt.Sprintf("%d %d (P%d size %d)", i, j, top.Owner, top.Size)
+			case len(next[i][j]) < len(prev[i][j]):
+				from = fmt.Sprintf("%d %d", i, j)
+			}
+		}
+	}
+	if from == "" {
+		return "place at " + to
+	}
+	return "move " + from + " → " + to
+}
+
+// buildOpeningTree links positions from every archived game that still has
+// its audit log.
+func buildOpeningTree() (map[string][]*explorerEdge, map[string]Board, int, error) {
+	records, err := loadArchive()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	tree := make(map[string][]*explorerEdge)
+	empty := engine.NewBoard(engine.Junior.Rules().Size)
+	boards := map[string]Board{positionKey(empty): empty}
+	used := 0
+	for _, record := range records {
+		entries, err := readStateLog(record.GameID)
+		if err != nil {
+			continue
+		}
+		used++
+
+		prev := empty
+		for _, e := range entries {
+			state, err := e.gameState()
+			if err != nil {
+				continue
+			}
+			state.Board = boardOf(state)
+			if positionKey(state.Board) == positionKey(prev) {
+				continue
+			}
+			if err := checkTransition(prev, state.Board, state.PlayerTurn); err != nil {
+				break // log started mid-game or is inconsistent, stop following it
+			}
+			from, to := positionKey(prev), positionKey(state.Board)
+			boards[to] = state.Board
+
+			var edge *explorerEdge
+			for _, existing := range tree[from] {
+				if existing.Next == to {
+					edge = existing
+				}
+			}
+			if edge == nil {
+				edge = &explorerEdge{Move: describeMove(prev, state.Board), Next: to}
+				tree[from] = append(tree[from], edge)
+			}
+			edge.Games++
+			edge.Wins[record.Result.Winner()]++
+			prev = state.Board
+		}
+	}
+	for _, edges := range tree {
+		sort.Slice(edges, func(i, j int) bool { return edges[i].Games > edges[j].Games })
+	}
+	return tree, boards, used, nil
+}
+
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
+
+// runExplore walks the opening tree built from the archive, starting from
+// the empty board.
+func runExplore(args []string) {
+	tree, boards, games, err := buildOpeningTree()
+	if err != nil {
+		fmt.Println("❌ Could not read archive:", err)
+		os.Exit(1)
+	}
+	if games == 0 {
+		fmt.Println("No archived games with audit logs to explore yet.")
+		return
+	}
+
+	path := []string{positionKey(engine.NewBoard(engine.Junior.Rules().Size))}
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		current := path[len(path)-1]
+		board = boards[current]
+		printBoard()
+
+		edges := tree[current]
+		fmt.Printf("Continuations from %d archived games (ply %d):\n", games, len(path)-1)
+		if len(edges) == 0 {
+			fmt.Println("  (no games continue from here)")
+		}
+		for i, e := range edges {
+			fmt.Printf("  %d) %-32s %3d games  P1 %3d%%  draw %3d%%  P2 %3d%%\n",
+				i+1, e.Move, e.Games, percent(e.Wins[1], e.Games), percent(e.Wins[0], e.Games), percent(e.Wins[2], e.Games))
+		}
+		fmt.Print("explore [number | b = back | q = quit]> ")
+
+		if !scanner.Scan() {
+			return
+		}
+		input := strings.TrimSpace(scanner.Text())
+		switch input {
+		case "q":
+			return
+		case "b":
+			if len(path) > 1 {
+				path = path[:len(path)-1]
+			}
+		default:
+			n, err := strconv.Atoi(input)
+			if err != nil || n < 1 || n > len(edges) {
+				fmt.Println("❌ Pick a continuation number, b or q.")
+				continue
+			}
+			path = append(path, edges[n-1].Next)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"goblets/config"
+	"log"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	feedTopic = "gobblet/feed"
+	feedLines = 20 // lines kept on a feed display
+)
+
+// playerLabel returns how a player is shown in public feed lines. Only the
+// local player's configured name is known, so the opponent is shown by number.
+func playerLabel(player int) string {
+	if player == playerID && config.Conf.PlayerName != "" {
+		return fmt.Sprintf("Player ‘%s’", publicName(config.Conf.PlayerName))
+	}
+	return fmt.Sprintf("Player %d", player)
+}
+
+// publishFeed sends a human-readable event line to the venue-wide feed.
+func publishFeed(line string) {
+	if budgetMode() {
+		return
+	}
+	game := "Game " + gameID
+	if gameMeta.Title != "" {
+		game += fmt.Sprintf(" (%s)", gameMeta.Title)
+	}
+	token := mqttClient.Publish(feedTopic, 0, false, fmt.Sprintf("%s: %s", game, line))
+	token.Wait()
+}
+
+// publishResultFeed announces a finished game on the feed.
+func publishResultFeed(r *Result) {
+	if r.IsDraw() {
+		publishFeed(fmt.Sprintf("draw by %s after %d moves", r.Termination, moveCount))
+		return
+	}
+	publishFeed(fmt.Sprintf("%s wins in %d moves", playerLabel(r.Winner()), moveCount))
+}
+
+// runFeed scrolls the venue feed on a public display device.
+func runFeed(args []string) {
+	connectMQTT()
+
+	lines := make(chan string, 16)
+	token := mqttClient.Subscribe(feedTopic, 0, func(client mqtt.Client, msg mqtt.Message) {
+		lines <- string(msg.Payload())
+	})
+	if token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error:", token.Error())
+	}
+
+	var shown []string
+	render := func() {
+		clearScreen()
+		fmt.Println("🏆 Gobblet Gobblers — Live Results")
+		fmt.Println()
+		for _, line := range shown {
+			fmt.Println(line)
+		}
+	}
+	render()
+	for line := range lines {
+		shown = append(shown, fmt.Sprintf("%s  %s", time.Now().Format("15:04"), line))
+		if len(shown) > feedLines {
+			shown = shown[len(shown)-feedLines:]
+		}
+		render()
+	}
+}
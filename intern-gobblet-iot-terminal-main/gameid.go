@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"goblets/config"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// gameIDProbe is how long to wait for a retained state before taking an ID
+// as free.
+const gameIDProbe = 2 * time.Second
+
+// newGameID draws a random game ID from game_id.alphabet, game_id.length
+// characters long.
+func newGameID() string {
+	c := config.Conf.GameID
+	alphabet := []rune(c.Alphabet)
+	if len(alphabet) < 2 || c.Length < 1 {
+		fmt.Println("⚠ Invalid game_id settings, using 8 characters of the default alphabet")
+		alphabet, c.Length = []rune(config.DefaultGameIDAlphabet), 8
+	}
+	id := make([]rune, c.Length)
+	for i := range id {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		id[i] = alphabet[n.Int64()]
+	}
+	return string(id)
+}
+
+// validGameID reports whether id can name a game topic.
+func validGameID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, "/+# ")
+}
+
+// gameExists reports whether a game state is retained under id. When the
+// broker can't be asked it returns an error rather than call the ID free.
+func gameExists(id string) (bool, error) {
+	found := make(chan struct{}, 1)
+	topic := gameTopic(id, channelState)
+	token := mqttClient.Subscribe(topic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		if len(msg.Payload()) > 0 {
+			select {
+			case found <- struct{}{}:
+			default:
+			}
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		return false, fmt.Errorf("could not check game ID %s: %w", id, token.Error())
+	}
+	defer mqttClient.Unsubscribe(topic).Wait()
+	select {
+	case <-found:
+		return true, nil
+	case <-time.After(gameIDProbe):
+		return false, nil
+	}
+}
+
+// freshGameID draws IDs until one isn't in use on the connected broker.
+func freshGameID() (string, error) {
+	for {
+		id := newGameID()
+		exists, err := gameExists(id)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return id, nil
+		}
+		fmt.Printf("⚠ Game ID %s is already taken, drawing another\n", id)
+	}
+}
+
+// connectNewGame picks an unused ID for a new game and connects under it, so
+// the connection's will names the right game.
+func connectNewGame() {
+	for {
+		gameID = newGameID()
+		connectMQTT()
+		exists, err := gameExists(gameID)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		if !exists {
+			return
+		}
+		fmt.Printf("⚠ Game ID %s is already taken, drawing another\n", gameID)
+		mqttClient.Disconnect(250)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// unreachableClient fails every subscription.
+type unreachableClient struct{ mqtt.Client }
+
+func (unreachableClient) Subscribe(string, byte, mqtt.MessageHandler) mqtt.Token {
+	return failedToken{err: errors.New("not connected")}
+}
+
+func TestFreshGameIDFailsClosed(t *testing.T) {
+	saved := mqttClient
+	t.Cleanup(func() { mqttClient = saved })
+	mqttClient = unreachableClient{}
+
+	if exists, err := gameExists("abc"); err == nil {
+		t.Errorf("gameExists = %v with no error on a failed subscription", exists)
+	}
+	if id, err := freshGameID(); err == nil {
+		t.Errorf("freshGameID handed out %q without checking it", id)
+	}
+}
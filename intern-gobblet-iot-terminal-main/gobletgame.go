@@ -3,41 +3,82 @@ package main
 import (
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"goblets/config"
+	"goblets/engine"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 )
 
-type Gobblet struct {
-	Size  int
-	Owner int
-}
-
-type Stack []Gobblet
-type Board [3][3]Stack
+// The board types live in the engine; these aliases keep the client code short.
+type (
+	Gobblet = engine.Gobblet
+	Stack   = engine.Stack
+	Board   = engine.Board
+)
 
 type GameState struct {
 	Board      Board
 	PlayerTurn int
-	Winner     int // ✅ New field to track winner
+	Winner     int     // ✅ New field to track winner
+	Result     *Result `json:",omitempty"`
+	Meta       GameMeta
+	Moves      int
+	Paused     bool
+	Stats      GameStats
+	Clocks     *Clocks `json:",omitempty"`
+	// Draw is set when the game ended without a winner.
+	Draw        bool           `json:",omitempty"`
+	Repetitions map[string]int `json:",omitempty"` // position hash -> occurrences
+	History     []HistoryEntry `json:",omitempty"` // every move and takeback, in order
+	Variant     engine.Variant `json:",omitempty"` // empty for junior
+	Control     *Control       `json:",omitempty"` // resignation or draw message sent with this state
+	Rules       *engine.Rules  `json:",omitempty"` // house rules, replacing the variant's
+	RulesHash   string         `json:",omitempty"` // hash of the rules in play, see gameRules
+	// Seq numbers published states across all senders; receivers discard a
+	// state older than the last one they applied. 0 from older clients.
+	Seq    uint64 `json:",omitempty"`
+	Sender string `json:",omitempty"` // client ID of the publisher
+	// Ruling is the referee's signed audit entry for the pause, resumption
+	// or adjudication in this state, see checkRuling.
+	Ruling *AuditEntry `json:",omitempty"`
+	// ChatOff is set by the goblet-referee in organizations with chat off.
+	ChatOff bool `json:",omitempty"`
+	// Seat is the publishing player's seat in a refereed game; the state
+	// is signed with the key of their seat claim.
+	Seat int `json:",omitempty"`
 }
 
 var (
 	board      Board
+	variant    engine.Variant
+	houseRules *engine.Rules // nil to play the variant's rules
+	boardSize  int           // board size for new games, 0 for the variant's
 	playerTurn = 1
+	moveCount  int
+	paused     bool
+	gameResult *Result
 	gameID     string
+	gameMeta   GameMeta
+	stateSeq   uint64 // Seq of the last state applied or published
+	seqSender  string // its Sender
 	playerID   int
 	mqttClient mqtt.Client
+	clientID   string
 	mu         sync.Mutex
+	gameRouted bool // the game's channels have their handlers
+	chatOff    bool // the referee turned chat off for this game
 )
 
 func clearScreen() {
@@ -51,22 +92,137 @@ func clearScreen() {
 
 func printBoard() {
 	// clearScreen()
-	fmt.Println("\nCurrent Board:")
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			if len(board[i][j]) == 0 {
-				fmt.Print("  .   ")
+	if label := gameMeta.label(); label != "" {
+		fmt.Printf("\n📛 %s", label)
+	}
+	printFrames(animationFrames())
+	printReserves()
+	if line := presenceLine(); line != "" {
+		fmt.Println(line)
+	}
+	if gameMeta.Rules.Assists.ThreatWarnings {
+		warnThreats()
+	}
+	if playerID == refereeID {
+		printStacks()
+	}
+	if playerID == spectatorID {
+		printHeatMap()
+	}
+}
+
+// boardOf returns the board of a received state, filling in an empty board
+// when the sender left it out.
+func boardOf(state GameState) Board {
+	if state.Board == nil {
+		return engine.NewBoard(state.Variant.Rules().Size)
+	}
+	return state.Board
+}
+
+// validState checks a received state against the engine's invariants. The
+// turn is still 0 while a coin flip decides who starts.
+func validState(state GameState) error {
+	s := engine.State{Board: boardOf(state), Turn: state.PlayerTurn, Variant: state.Variant, Rules: state.Rules}
+	if s.Turn == 0 && state.Moves == 0 {
+		s.Turn = 1
+	}
+	if state.RulesHash != "" && state.RulesHash != rulesOf(state).Hash() {
+		return errors.New("the rules hash does not match the rules")
+	}
+	return engine.Validate(s)
+}
+
+// position returns the engine state of b with turn to move, under the
+// game's variant and house rules.
+func position(b Board, turn int) engine.State {
+	return engine.State{Board: b, Turn: turn, Variant: variant, Rules: houseRules}
+}
+
+// gameRules returns the rules the current game is played by.
+func gameRules() engine.Rules {
+	return rulesOf(GameState{Variant: variant, Rules: houseRules})
+}
+
+// rulesOf returns the rules a received state is played by.
+func rulesOf(state GameState) engine.Rules {
+	if state.Rules != nil {
+		return *state.Rules
+	}
+	return state.Variant.Rules()
+}
+
+// configuredRules returns the variant's rules with the house rules from the
+// config switched on, or nil when none are.
+func configuredRules(v engine.Variant) *engine.Rules {
+	c := config.Conf.Variants
+	if !c.Memory && !c.NoReserveGobble && !c.GobbleOnlyWhenThreatened {
+		return nil
+	}
+	r := v.Rules()
+	r.Memory, r.NoReserveGobble, r.GobbleOnThreat = c.Memory, c.NoReserveGobble, c.GobbleOnlyWhenThreatened
+	return &r
+}
+
+func printCompactBoard(w io.Writer) {
+	fmt.Fprintln(w, "\nCurrent Board:")
+	for i := range board {
+		for j := range board[i] {
+			if stack := viewStack(i, j); len(stack) == 0 {
+				fmt.Fprint(w, highlight(i, j, "  .  ")+" ")
 			} else {
-				top := board[i][j][len(board[i][j])-1]
-				fmt.Printf(" %d%d   ", top.Owner, top.Size)
+				top := stack[len(stack)-1]
+				fmt.Fprint(w, highlight(i, j, pieceText(top))+" ")
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
-func setupMQTT() {
+// newMQTTClient builds a client for the configured broker using the device
+// certificates in the working directory.
+func newMQTTClient(clientID string) mqtt.Client {
+	willTopic, will := lastWill()
+	client := newBrokerClient(config.Conf.BrokerURL, clientID, willTopic, will)
+	if batchWindow > 0 {
+		client = newBatchingClient(client, batchWindow)
+	}
+	if budgetMode() {
+		return meteredClient{client}
+	}
+	return client
+}
+
+// newBrokerClient builds a client for any broker using the device
+// certificates, speaking the MQTT version set in mqtt.version. A non-nil
+// will is published by the broker on willTopic if the connection is lost.
+func newBrokerClient(brokerURL, clientID, willTopic string, will []byte) mqtt.Client {
+	tlsConfig := deviceTLSConfig()
+	if config.Conf.MQTT.Version == 5 {
+		client, err := newV5Client(brokerURL, clientID, tlsConfig, willTopic, will)
+		if err != nil {
+			log.Fatal("❌ Invalid broker URL:", err)
+		}
+		return client
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(brokerURL).
+		SetClientID(clientID).
+		SetTLSConfig(tlsConfig).
+		SetKeepAlive(30 * time.Second). // ✅ Ensure connection stays active
+		SetPingTimeout(20 * time.Second).
+		SetAutoReconnect(true) // ✅ Reconnect if disconnected
+	if will != nil {
+		opts.SetBinaryWill(willTopic, will, 1, false)
+	}
+
+	return mqtt.NewClient(opts)
+}
+
+// deviceTLSConfig loads the device certificates from the working directory.
+func deviceTLSConfig() *tls.Config {
 	certpool := x509.NewCertPool()
 	pemCerts, err := ioutil.ReadFile("root-CA.pem")
 	if err != nil {
@@ -79,45 +235,75 @@ func setupMQTT() {
 		log.Fatal("Error loading certificates:", err)
 	}
 
-	opts := mqtt.NewClientOptions().
-		AddBroker(config.Conf.BrokerURL).
-		SetClientID(fmt.Sprintf("GobbletPlayer-%d", time.Now().UnixNano())).
-		SetTLSConfig(&tls.Config{
-			Certificates: []tls.Certificate{cert},
-			RootCAs:      certpool,
-		}).
-		SetKeepAlive(30 * time.Second). // ✅ Ensure connection stays active
-		SetPingTimeout(20 * time.Second).
-		SetAutoReconnect(true) // ✅ Reconnect if disconnected
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      certpool,
+	}
+}
 
-	mqttClient = mqtt.NewClient(opts)
+func connectMQTT() {
+	if deviceID == "" {
+		id, err := loadDeviceID()
+		if err != nil {
+			log.Fatal("❌ Could not load device identity:", err)
+		}
+		deviceID = id
+	}
+	clientID = fmt.Sprintf("GobbletPlayer-%s-%d", deviceID, time.Now().UnixNano())
+	mqttClient = newMQTTClient(clientID)
 	if token := mqttClient.Connect(); token.Wait() && token.Error() != nil {
 		log.Fatal("❌ MQTT Connection Error:", token.Error())
 	}
+}
+
+func setupMQTT() {
+	connectMQTT()
+	subscribeGame()
+	watchInvites()
+}
 
-	topic := "gobblet/game/" + gameID
+// subscribeGame subscribes to the channels of the current game, each routed
+// to its handler by the dispatcher.
+func subscribeGame() {
+	topic := gameTopic(gameID, "#")
 	fmt.Println("✅ Connected to AWS IoT Core! Subscribing to:", topic)
 
+	if !gameRouted { // routes outlive a switch to another game, see dropQuickplayGame
+		route(channelState, unbatched(ordered(traced(onMessageReceived))))
+		route(channelMoves, traced(onMoveReceived))
+		route(channelChat, onChat)
+		gameRouted = true
+	}
 	// ✅ Use QoS 1 for reliable message delivery
-	if token := mqttClient.Subscribe(topic, 1, onMessageReceived); token.Wait() && token.Error() != nil {
+	if token := subscribeChannels(); token.Wait() && token.Error() != nil {
 		log.Fatal("❌ Subscription Error:", token.Error())
 	}
 	fmt.Println("✅ Subscribed to topic:", topic)
+
+	if token := mqttClient.Subscribe(auditTopic(), 1, traced(onAuditReceived)); token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error:", token.Error())
+	}
 }
 
 func loadGameState() bool {
-	topic := "gobblet/game/" + gameID
+	topic := stateTopic()
 
 	stateChan := make(chan GameState, 1) // ✅ Channel to receive the first valid game state
 
 	// ✅ Subscribe to retained message
 	token := mqttClient.Subscribe(topic, 1, func(client mqtt.Client, msg mqtt.Message) {
 		var state GameState
-		err := json.Unmarshal(msg.Payload(), &state)
+		err := decodeState(unwrapPayload(msg.Payload()), &state)
 		if err != nil {
 			fmt.Println("❌ Error decoding game state from IoT Core:", err)
 			return
 		}
+		if state.Meta.Refereed {
+			// Shown until the referee's signed state arrives; moves are checked against the referee's own copy
+			if err := verifyRefereeState(unwrapPayload(msg.Payload())); err != nil {
+				fmt.Println("⚠ Not the referee's state yet:", err)
+			}
+		}
 
 		// ✅ Load the game state
 		select {
@@ -131,40 +317,117 @@ func loadGameState() bool {
 		fmt.Println("❌ Error subscribing to game state:", token.Error())
 		return false
 	}
+	defer mqttClient.AddRoute(topic, dispatch) // hand the channel back to the dispatcher
 
 	// ✅ Wait for the first message or timeout after 2 seconds
 	select {
 	case state := <-stateChan:
-		board = state.Board
+		if err := validState(state); err != nil {
+			fmt.Println("❌ The saved game is corrupt:", err)
+			os.Exit(1)
+		}
+		variant = state.Variant
+		houseRules = state.Rules
+		board = boardOf(state)
 		playerTurn = state.PlayerTurn
+		gameMeta = state.Meta
+		chatOff = state.ChatOff
+		stateSeq, seqSender = state.Seq, state.Sender
+		moveCount = state.Moves
+		paused = state.Paused
+		gameResult = state.Result
+		ruling = state.Ruling
+		gameStats = state.Stats.fit(board.Size(), gameRules().Sizes)
+		clocks = receivedClocks(state.Clocks, gameMeta.Rules)
+		positionCounts = state.Repetitions
+		history = state.History
 		fmt.Println("✅ Game state loaded from AWS IoT Core retained message!")
+		if config.Conf.MoveDeltas {
+			requestResync() // the snapshot may be a few moves old
+		}
+		if houseRules != nil {
+			printHouseRules()
+		}
 
 		// ✅ Immediately print the board
 		printBoard()
 
 		// ✅ If a winner exists, display it on all terminals
-		if state.Winner != 0 {
+		if state.Result != nil {
+			announceResult(state.Result)
+		} else if state.Winner != 0 {
 			fmt.Printf("🎉 Player %d wins!\n", state.Winner)
 		}
 
 		return true
 	case <-time.After(2 * time.Second): // Timeout to avoid infinite waiting
+		if migrateLegacyState() {
+			return loadGameState()
+		}
 		fmt.Println("⚠ No retained game session found in IoT Core. Creating a new session.")
 		return false
 	}
 }
 
+// currentState builds the GameState to publish from the local game.
+func currentState() GameState {
+	result := gameResult
+	if result == nil {
+		result = lineResult(checkWin())
+	}
+	state := GameState{Board: board, PlayerTurn: playerTurn, Result: result, Meta: gameMeta, Moves: moveCount, Paused: paused, Stats: gameStats, Clocks: clocks, Repetitions: positionCounts, History: history, Variant: variant, Control: control, Rules: houseRules, RulesHash: gameRules().Hash(), Seq: stateSeq, Sender: seqSender, Ruling: ruling, ChatOff: chatOff}
+	if result != nil {
+		state.Winner = result.Winner()
+		state.Draw = result.IsDraw()
+	}
+	return state
+}
+
+// nextState numbers the current state for publishing.
+func nextState() GameState {
+	stateSeq++
+	seqSender = clientID
+	return currentState()
+}
+
+// staleSeq reports whether state is older than the last one applied. Our
+// own latest state coming back from the broker is not stale. States
+// without a Seq are only let through while a terminal that doesn't number
+// them takes part.
+func staleSeq(state GameState) bool {
+	if state.Seq == 0 && legacyPeer() {
+		return false
+	}
+	return state.Seq < stateSeq || (state.Seq == stateSeq && state.Sender != seqSender)
+}
+
 func saveGameState() {
-	winner := checkWin()
-	state := GameState{Board: board, PlayerTurn: playerTurn, Winner: winner}
+	sendState(nextState())
+}
 
-	data, _ := json.Marshal(state)
-	topic := "gobblet/game/" + gameID
+// sendState publishes a state taken with nextState. Callers holding mu
+// can take the state and send it after unlocking, so the lock isn't held
+// while the broker acknowledges.
+func sendState(state GameState) {
+	winner := state.Winner
 
-	fmt.Println("📤 Sending game state to AWS IoT Core:", string(data))
+	if gameMeta.Refereed && (playerID == 1 || playerID == 2) {
+		state.Seat = playerID
+	}
+	data := encodeState(state)
+	if state.Seat != 0 {
+		signed, err := signState(data)
+		if err != nil {
+			fmt.Println("❌ Could not sign the state for the referee:", err)
+			return
+		}
+		data = signed
+	}
+
+	fmt.Println("📤 Sending game state to AWS IoT Core:", logText(data))
 
 	// ✅ Retain message and ensure Player 2 receives the latest state
-	token := mqttClient.Publish(topic, 1, true, data)
+	token := publishGameState(data)
 	token.Wait()
 
 	if winner != 0 {
@@ -174,17 +437,16 @@ func saveGameState() {
 
 func publishMove() {
 	mu.Lock()
-	winner := checkWin()
-	state := GameState{Board: board, PlayerTurn: playerTurn, Winner: winner}
+	state := nextState()
 	mu.Unlock()
+	winner := state.Winner
 
-	data, _ := json.Marshal(state)
-	topic := "gobblet/game/" + gameID
+	data := encodeState(state)
 
-	fmt.Println("📤 Sending move to AWS IoT Core:", string(data))
+	fmt.Println("📤 Sending move to AWS IoT Core:", logText(data))
 
 	// ✅ Ensure message is retained so opponent sees the latest move
-	token := mqttClient.Publish(topic, 1, true, data)
+	token := publishGameState(data)
 	token.Wait()
 
 	// ✅ Immediately print the board for both players
@@ -202,156 +464,423 @@ func onMessageReceived(client mqtt.Client, msg mqtt.Message) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	fmt.Println("📥 Received move from AWS IoT Core:", string(msg.Payload()))
+	if playerID != spectatorID {
+		fmt.Println("📥 Received move from AWS IoT Core:", logText(msg.Payload()))
+	}
 
 	var state GameState
-	err := json.Unmarshal(msg.Payload(), &state)
+	err := decodeState(msg.Payload(), &state)
 	if err != nil {
-		fmt.Println("❌ Error decoding state:", err)
+		rejectState(state, fmt.Sprint("Error decoding state: ", err))
+		return
+	}
+	if gameMeta.Refereed && state.Sender != refereeSender {
+		return // only the referee service's states count
+	}
+	if gameMeta.Refereed {
+		if err := verifyRefereeState(msg.Payload()); err != nil {
+			fmt.Println("⚠ Ignored a state in the referee's name:", err)
+			return
+		}
+	}
+	if state.Sender != "" && state.Sender != clientID {
+		noteConfirmed(state.Moves) // a full state from the opponent or referee includes our moves
+	}
+	if playerID == spectatorID && staleState(state) {
+		return
+	}
+	if staleSeq(state) {
+		fmt.Printf("⚠ Ignored a stale state (#%d, we have #%d)\n", state.Seq, stateSeq)
 		return
 	}
 
+	if state.Variant.String() != variant.String() && board != nil {
+		rejectState(state, fmt.Sprintf("Ignored a %s state for this %s game", state.Variant, variant))
+		return
+	}
+	if board != nil && rulesOf(state).Hash() != gameRules().Hash() {
+		rejectState(state, "Ignored a state played under different rules than this game")
+		return
+	}
+	if next := boardOf(state); !next.Square() || (board != nil && next.Size() != board.Size()) {
+		rejectState(state, fmt.Sprintf("Ignored a state with a %dx%d board for this %dx%d game", len(next), len(next), board.Size(), board.Size()))
+		return
+	}
+	// ✅ Refuse corrupted or forged states instead of overwriting the board
+	if err := validState(state); err != nil {
+		rejectState(state, fmt.Sprint("Protocol error, ignored an invalid state: ", err))
+		return
+	}
+	if err := checkRuling(state); err != nil {
+		rejectState(state, fmt.Sprint("Ignored a referee decision: ", err))
+		return
+	}
+
+	// ✅ The opponent's moves must be legal from the board we hold
+	if board != nil {
+		if err := checkProgress(state); err != nil {
+			rejectState(state, fmt.Sprintf("Rejected an illegal move from Player %d: %v", playerTurn, err))
+			return
+		}
+	}
+	appendStateLog(msg.Payload())
+
+	// ✅ Retained echoes of the state we already show don't need a redraw
+	echo := board != nil && boardOf(state).Hash() == board.Hash() && state.PlayerTurn == playerTurn &&
+		state.Moves == moveCount && state.Paused == paused && state.Result == nil
+
+	moved, mover := board != nil && state.Moves > moveCount, playerTurn
+
 	// ✅ Ensure board updates properly
-	board = state.Board
+	variant = state.Variant
+	houseRules = state.Rules
+	board = boardOf(state)
 	playerTurn = state.PlayerTurn
+	gameMeta = state.Meta
+	chatOff = state.ChatOff
+	if state.Seq != 0 {
+		stateSeq, seqSender = state.Seq, state.Sender
+	}
+	claimTheme()
+	moveCount = state.Moves
+	paused = state.Paused
+	gameResult = state.Result
+	ruling = state.Ruling
+	gameStats = state.Stats.fit(board.Size(), gameRules().Sizes)
+	adoptClocks(state.Clocks, moved, mover)
+	positionCounts = state.Repetitions
+	history = state.History
+
+	switch {
+	case echo:
+	case playerID == spectatorID:
+		requestRedraw()
+	default:
+		printBoard() // ✅ Force print board immediately for both players
+	}
+	if state.Control != nil && state.Control.Player != playerID {
+		onControl(*state.Control)
+	}
 
-	printBoard() // ✅ Force print board immediately for both players
+	if state.Moves > hookedMoves {
+		hookedMoves = state.Moves
+		fireHook(hookMove, state, false)
+	}
 
 	// ✅ If there's a winner, show it
-	if state.Winner != 0 {
+	if state.Result != nil {
+		finishGame(state.Result)
+	} else if state.Winner != 0 {
 		fmt.Printf("🎉 Player %d wins!\n", state.Winner)
+		closePrompt()
 		os.Exit(0) // Ensure game stops when there's a winner
-	} else {
+	} else if !echo {
 		fmt.Println("✅ Board updated from AWS IoT Core!")
 	}
 }
 
-func placePiece(row, col, size int) bool {
-	if size < 1 || size > 3 {
-		fmt.Println("❌ Invalid move: Goblet size must be between 1 and 3!")
-		return false
+// finishGame announces, publishes and archives the result, then exits.
+func finishGame(result *Result) {
+	announceResult(result)
+	printHeatMap()
+	if config.Conf.Analysis.AfterGame {
+		printAnalysis(result)
 	}
-
-	if row < 0 || row >= 3 || col < 0 || col >= 3 {
-		fmt.Println("❌ Invalid move: Out of bounds!")
-		return false
+	// The terminal that made the last move reports it to the venue feed
+	if playerID == playerTurn {
+		publishResultFeed(result)
+		publishGameEnded(result)
 	}
+	clearLobby() // in case a seat was never taken
+	archiveGame(result)
+	recordProfileResult(result)
+	reportBandwidth()
+	fireHook(hookGameEnd, currentState(), true)
+	flushBatches()
+	closePrompt()
+	os.Exit(0) // Ensure game stops when there's a winner
+}
 
-	if len(board[row][col]) > 0 && board[row][col][len(board[row][col])-1].Size >= size {
-		fmt.Println("❌ Invalid move: Cannot place a smaller piece on a larger one!")
-		return false
+func placePiece(row, col, size int) error {
+	return playMove(engine.Place(row, col, size))
+}
+
+func movePiece(fromRow, fromCol, toRow, toCol int) error {
+	return playMove(engine.Shift(fromRow, fromCol, toRow, toCol))
+}
+
+// playMove applies a move for the player to move and publishes the result.
+// Illegal moves return the engine's error for the caller to explain.
+func playMove(m engine.Move) error {
+	// ✅ A move made after the flag fell doesn't count
+	checkFlag()
+
+	player := playerTurn
+	next, err := makeMove(m)
+	if err != nil {
+		return err
 	}
 
-	// ✅ Place the goblet before checking for a win
-	board[row][col] = append(board[row][col], Gobblet{Size: size, Owner: playerTurn})
+	if config.Conf.MoveDeltas || gameMeta.Refereed {
+		if gameResult == nil {
+			playerTurn = next.Turn
+		}
+		publishDelta(m, player)
+		printBoard()
+		if gameResult != nil {
+			banterResult(gameResult)
+			announceResult(gameResult)
+		}
+		return nil
+	}
 
 	// ✅ Save game state and publish move
 	saveGameState()
 	publishMove()
 
+	if gameResult != nil {
+		banterResult(gameResult)
+		announceResult(gameResult)
+		return nil
+	}
+
 	// ✅ If a winner is detected, print the message and return
 	winner := checkWin()
 	if winner != 0 {
 		fmt.Printf("🎉 Player %d wins!\n", winner)
-		return true
+		return nil
 	}
 
 	// ✅ Switch turn after move and publish immediately
-	playerTurn = 3 - playerTurn
+	playerTurn = next.Turn
 	publishMove()
 
-	return true
+	return nil
 }
 
-func movePiece(fromRow, fromCol, toRow, toCol int) bool {
-	if fromRow < 0 || fromRow >= 3 || fromCol < 0 || fromCol >= 3 || toRow < 0 || toRow >= 3 || toCol < 0 || toCol >= 3 {
-		fmt.Println("❌ Invalid move: Out of bounds!")
-		return false
-	}
-	if len(board[fromRow][fromCol]) == 0 {
-		fmt.Println("❌ Invalid move: No piece to move!")
-		return false
+// makeMove applies m for the player to move to the local game and keeps
+// its bookkeeping: history, stats, clocks, repetitions and the result. The
+// turn is left for the caller to pass on.
+func makeMove(m engine.Move) (engine.State, error) {
+	g := engine.Game{State: position(board, playerTurn), Moves: moveCount, Repetitions: positionCounts}
+	next, err := g.Play(m)
+	if err != nil {
+		return next.State, err
 	}
-	top := board[fromRow][fromCol][len(board[fromRow][fromCol])-1]
-	if top.Owner != playerTurn {
-		fmt.Println("❌ Invalid move: You can only move your own pieces!")
-		return false
+
+	// ✅ Place the goblet before checking for a win
+	piece, _ := next.Board[m.Row][m.Col].Top()
+	recordLanding(m.Row, m.Col, piece)
+	recordHistory(m)
+	drawOfferedBy = 0 // ✅ Moving on declines an open draw offer
+	board = next.Board
+	moveCount = next.Moves
+	positionCounts = next.Repetitions
+	if clocks != nil {
+		clocks.charge(playerTurn)
 	}
-	if len(board[toRow][toCol]) > 0 && board[toRow][toCol][len(board[toRow][toCol])-1].Size >= top.Size {
-		fmt.Println("❌ Invalid move: Cannot place a smaller piece on a larger one!")
-		return false
+	// ✅ Uncovered lines, repetitions and stalemates end the game like a line
+	if next.Result != nil {
+		gameResult = engineResult(next.Result)
 	}
+	return next.State, nil
+}
 
-	// ✅ Move the piece
-	board[fromRow][fromCol] = board[fromRow][fromCol][:len(board[fromRow][fromCol])-1]
-	board[toRow][toCol] = append(board[toRow][toCol], top)
+// checkStalemate ends a game saved before stalemates were detected, where
+// the local player has no legal move.
+func checkStalemate() {
+	mu.Lock()
+	stuck := gameResult == nil && position(board, playerTurn).Stalemate()
+	var state GameState
+	if stuck {
+		gameResult = newDrawResult(TerminationStalemate)
+		state = nextState()
+	}
+	mu.Unlock()
+	if stuck {
+		sendState(state)
+		finishGame(state.Result)
+	}
+}
 
-	// ✅ Save game state and publish move
-	saveGameState()
-	publishMove()
+// checkWin returns the winner of the current game, or 0. The result set
+// by the engine after the last move decides when both players own a line.
+func checkWin() int {
+	if gameResult != nil {
+		return gameResult.Winner()
+	}
+	return engine.CheckWin(board)
+}
 
-	// ✅ If a winner is detected, print the message and return
-	winner := checkWin()
-	if winner != 0 {
-		fmt.Printf("🎉 Player %d wins!\n", winner)
-		return true
+func main() {
+	config.Load(config.Dir)
+	presetName := flag.String("preset", defaultPreset, "rule preset to use when creating a new game")
+	rated := flag.Bool("rated", false, "create a rated game")
+	refereed := flag.Bool("refereed", false, "create a game checked by the goblet-referee service")
+	title := flag.String("title", "", "human-readable title for a new game")
+	tags := flag.String("tags", "", "comma-separated tags for a new game")
+	tournament := flag.String("tournament", "", "ID of the tournament bracket a new game is part of")
+	first := flag.String("first", "1", "who moves first in a new game: 1, 2 or random (verifiable coin flip)")
+	view := flag.String("orientation", config.Conf.Orientation, "board view: normal, flipped, left or right")
+	rules := flag.String("variant", config.Conf.Variant, "rule set for a new game: junior (3x3) or classic (4x4)")
+	flag.IntVar(&boardSize, "board-size", config.Conf.BoardSize, "board size for a new game, 3-9 (0 for the variant's size)")
+	joinID := flag.String("game-id", "", "ID of an existing game to join; leave out to be asked")
+	flag.Parse()
+
+	if boardSize != 0 && (boardSize < engine.MinSize || boardSize > engine.MaxSize) {
+		fmt.Printf("❌ --board-size must be between %d and %d\n", engine.MinSize, engine.MaxSize)
+		os.Exit(1)
 	}
 
-	// ✅ Switch turn after move and publish immediately
-	playerTurn = 3 - playerTurn
-	publishMove()
+	if !engine.Variant(*rules).Valid() {
+		fmt.Println("❌ --variant must be junior or classic")
+		os.Exit(1)
+	}
 
-	return true
-}
+	if !validOrientation(*view) {
+		fmt.Println("❌ --orientation must be normal, flipped, left or right")
+		os.Exit(1)
+	}
+	orientation = *view
 
-func checkWin() int {
-	// Check rows and columns
-	for i := 0; i < 3; i++ {
-		if winner := checkLine(board[i][0], board[i][1], board[i][2]); winner != 0 {
-			return winner
-		}
-		if winner := checkLine(board[0][i], board[1][i], board[2][i]); winner != 0 {
-			return winner
+	if flag.NArg() > 0 {
+		command, ok := commands[flag.Arg(0)]
+		if !ok {
+			fmt.Println("❌ Unknown command:", flag.Arg(0))
+			os.Exit(1)
 		}
+		command(flag.Args()[1:])
+		return
 	}
-	// Check diagonals
-	if winner := checkLine(board[0][0], board[1][1], board[2][2]); winner != 0 {
-		return winner
+
+	openPrompt()
+	if *joinID == "" {
+		*joinID, _ = readPrompt("Enter a Game ID to join, or press Enter to start a new game: ")
 	}
-	if winner := checkLine(board[0][2], board[1][1], board[2][0]); winner != 0 {
-		return winner
+	if *joinID != "" && !validGameID(*joinID) {
+		fmt.Println("❌ Invalid Game ID! It can't contain spaces, '/', '+' or '#'.")
+		os.Exit(1)
 	}
-	return 0
-}
+	if *tournament != "" && !validGameID(*tournament) {
+		fmt.Println("❌ Invalid tournament ID! It can't contain spaces, '/', '+' or '#'.")
+		os.Exit(1)
+	}
+
+	if *joinID != "" {
+		gameID = *joinID
+		setupMQTT()
+	} else {
+		connectNewGame()
+		subscribeGame()
+		watchInvites()
+	}
+	checkForUpdates()
 
-func checkLine(a, b, c Stack) int {
-	if len(a) > 0 && len(b) > 0 && len(c) > 0 {
-		if a[len(a)-1].Owner == b[len(b)-1].Owner && b[len(b)-1].Owner == c[len(c)-1].Owner {
-			return a[len(a)-1].Owner
+	fmt.Println("🔍 Checking for existing game session...")
+	if !loadGameState() {
+		if *joinID != "" {
+			fmt.Printf("❌ There is no game %s. Check the ID, find open games with 'list', or press Enter at the prompt to start a new one.\n", gameID)
+			os.Exit(1)
+		}
+		fmt.Println("🆕 Creating new game session.")
+		preset, err := resolvePreset(*presetName, newGameSize(engine.Variant(*rules)))
+		if err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
 		}
+		meta := GameMeta{Rated: *rated, Title: *title, Tags: splitTags(*tags), Tournament: *tournament, Refereed: *refereed}
+		variant = engine.Variant(*rules)
+		houseRules = configuredRules(variant)
+		switch *first {
+		case "1", "2":
+			meta.FirstPlayer, meta.FirstChoice = int((*first)[0]-'0'), firstByCreator
+		case "random":
+			meta.FirstChoice = firstByCoinFlip
+		default:
+			fmt.Println("❌ --first must be 1, 2 or random")
+			os.Exit(1)
+		}
+		createGame(preset, meta)
+		fmt.Printf("🆔 Your Game ID is %s. Share it with your opponent, or they can find the game with 'list'.\n", gameID)
 	}
-	return 0
-}
 
-func main() {
-	fmt.Print("Enter a 5-digit Game ID: ")
-	fmt.Scan(&gameID)
+	seat, _ := readPrompt("Enter Player Number (1 , 2) or (3 for Spectating) or (4 for Referee) or (5 vs Computer): ")
+	playerID, _ = strconv.Atoi(seat)
+	if playerID == computerID {
+		startComputer()
+	}
 
-	if len(gameID) != 5 {
-		fmt.Println("❌ Invalid Game ID! Must be 5 digits.")
-		os.Exit(1)
+	if playerID == refereeID {
+		printBoard()
+		runReferee()
+		return
 	}
 
-	setupMQTT()
+	playGame()
+}
 
-	fmt.Println("🔍 Checking for existing game session...")
-	if !loadGameState() {
-		fmt.Println("🆕 No game found. Creating new game session.")
-		saveGameState()
+// newGameSize is the board size of a new game of variant v: --board-size,
+// or the variant's own.
+func newGameSize(v engine.Variant) int {
+	if boardSize != 0 {
+		return boardSize
 	}
+	return v.Rules().Size
+}
 
-	fmt.Print("Enter Player Number (1 , 2) or (3 for Spectating): ")
-	fmt.Scan(&playerID)
+// createGame publishes the initial state of a new game using the preset's
+// rules and the given metadata.
+func createGame(preset Preset, meta GameMeta) {
+	meta.Preset = preset.Name
+	meta.Rules = preset.Rules
+	gameMeta = meta
+	board = engine.NewBoard(newGameSize(variant))
+	clocks = newClocks(meta.Rules)
+	switch {
+	case meta.FirstChoice == firstByCoinFlip:
+		playerTurn = 0 // nobody moves until the coin flip is done
+	case meta.FirstPlayer != 0:
+		playerTurn = meta.FirstPlayer
+	}
+	fmt.Printf("📋 Using preset %q: %s\n", preset.Name, preset.Description)
+	if houseRules != nil {
+		printHouseRules()
+	}
+	saveGameState()
+	publishFeed(fmt.Sprintf("new %s game started", preset.Name))
+	publishGameCreated()
+	advertiseGame()
+}
+
+// playGame runs the interactive game loop for the chosen seat.
+func playGame() {
+	if seated() {
+		takeSeat()
+	}
+	if playerID == 1 || playerID == 2 {
+		if err := checkRoster(); err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
+		}
+		claimSeat()
+		syncLobbySeats()
+		if err := verifyOpponentIdentity(); err != nil {
+			fmt.Println("❌ Refusing to start rated game:", err)
+			os.Exit(1)
+		}
+		if gameMeta.FirstChoice == firstByCoinFlip && playerTurn == 0 {
+			runCoinFlip()
+		}
+		watchTakebacks()
+		watchLatency()
+		watchRejections()
+		watchLink()
+		watchSessionLimit()
+		publishPlayerJoined()
+		myTheme = chooseTheme()
+		claimTheme()
+	}
+	watchPresence()
 
 	// ✅ Player 2 continuously checks for updates
 	// ✅ Player 2 continuously checks for updates
@@ -363,69 +892,114 @@ func main() {
 		}
 	}()
 
+	hookedMoves = moveCount
+	fireHook(hookGameStart, currentState(), false)
+	input := startInputReader()
+	var premove *Action
+
 	for {
 		printBoard()
 
 		// ✅ Spectator Mode: Keep watching the game
 		if playerID == 3 {
 			fmt.Print("\r👀 You are now Spectating the Game")
-			continue
+			runSpectatorView() // board updates are drawn as they arrive
 		}
 
 		// ✅ Player should see "Waiting for opponent's move..." only ONCE
 		if playerTurn != playerID {
+			fmt.Print("\n" + hintBar(false))
 			fmt.Print("\nWaiting for opponent's move...") // ✅ Print only once
-			for playerTurn != playerID {
-				time.Sleep(1 * time.Second) // ✅ Keep checking silently
+			for playerTurn != playerID && playerID != spectatorID {
+				select {
+				case line, ok := <-input:
+					if !ok {
+						shutdown()
+					}
+					if handlePromptCommand(line, false) {
+						continue
+					}
+					a, err := parseAction(line)
+					if err != nil {
+						fmt.Println("\n❌", err)
+						continue
+					}
+					if a.Kind == actionResign || a.Kind == actionDraw {
+						applyAction(a) // not a move, so it isn't queued
+						continue
+					}
+					a = a.fromView()
+					premove = &a
+					fmt.Printf("\n⏩ Premove queued: %s\n", line)
+				case <-time.After(1 * time.Second): // ✅ Keep checking silently
+					tickClock()
+				}
 			}
 			fmt.Println() // ✅ Move to a new line after waiting
+			if playerID == spectatorID {
+				continue // our seat was taken over by another session
+			}
+		}
+
+		// ✅ Hold moves while the referee has the game paused
+		if paused {
+			fmt.Print("\n⏸ Game paused by the referee...")
+			for paused {
+				time.Sleep(1 * time.Second)
+			}
+			fmt.Println()
 		}
 
 		// ✅ Check if the game has ended before making a move
 		if winner := checkWin(); winner != 0 {
 			printBoard()
 			fmt.Printf("🎉 Player %d wins!\n", winner)
+			closePrompt()
 			os.Exit(0)
 		}
-
-		fmt.Printf("Player %d, choose action: (1) PLACE = '1 x y size', (2) MOVE = '2 x1 y1 x2 y2': ", playerTurn)
-		var action, row, col, size, toRow, toCol int
-
-		_, err := fmt.Scan(&action)
-		if err != nil {
-			fmt.Println("Invalid input, please try again.")
-			time.Sleep(2 * time.Second)
-			continue
-		}
-
-		if action == 1 {
-			_, err = fmt.Scan(&row, &col, &size)
-			if err != nil {
-				fmt.Println("❌ Invalid input for place action. Try again.")
-				time.Sleep(2 * time.Second)
+		checkStalemate()
+
+		// ✅ Play a queued premove instantly if it is still legal
+		if premove != nil {
+			a := *premove
+			premove = nil
+			err := applyAction(a)
+			if err == nil {
 				continue
 			}
+			fmt.Println("⚠ Premove is no longer legal, discarded:", moveErrorText(err))
+		}
 
-			if !placePiece(row, col, size) {
-				fmt.Println("❌ Invalid placement. Try again.")
-				time.Sleep(2 * time.Second)
-				continue
-			}
-		} else if action == 2 {
-			_, err = fmt.Scan(&row, &col, &toRow, &toCol)
-			if err != nil {
-				fmt.Println("❌ Invalid input for move action. Try again.")
-				time.Sleep(2 * time.Second)
+		fmt.Println(hintBar(true))
+		fmt.Printf("Player %d, choose action: (1) PLACE = '1 x y size' or a reserve letter, (2) MOVE = '2 x1 y1 x2 y2': ", playerTurn)
+		line, ok := readTurnInput(input)
+		if !ok {
+			shutdown()
+		}
+		if playerID == spectatorID {
+			continue
+		}
+		if handlePromptCommand(line, true) {
+			continue
+		}
+		if size, ok := reserveItem(line); ok {
+			if line, ok = pickPlacement(size, input); !ok {
 				continue
 			}
-
-			if !movePiece(row, col, toRow, toCol) {
-				fmt.Println("❌ Invalid move. Try again.")
-				time.Sleep(2 * time.Second)
-				continue
+		}
+		a, err := parseAction(line)
+		if err != nil {
+			fmt.Println("❌", err)
+			time.Sleep(2 * time.Second)
+			continue
+		}
+		a = a.fromView()
+		if err := applyAction(a); err != nil {
+			if a.Kind == actionPlace {
+				fmt.Printf("❌ Invalid placement: %s. Try again.\n", moveErrorText(err))
+			} else {
+				fmt.Printf("❌ Invalid move: %s. Try again.\n", moveErrorText(err))
 			}
-		} else {
-			fmt.Println("❌ Invalid action! Use 1 to place, 2 to move.")
 			time.Sleep(2 * time.Second)
 			continue
 		}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// healthStale is how long a daemon's main loop may go without a beat
+// before it counts as hung.
+const healthStale = 30 * time.Second
+
+// lastBeat is when the daemon's main loop last reported progress, in Unix
+// nanoseconds; 0 for daemons without a loop, which are healthy while
+// connected.
+var lastBeat atomic.Int64
+
+// healthBeat records that the main loop is making progress.
+func healthBeat() {
+	lastBeat.Store(time.Now().UnixNano())
+}
+
+// healthy reports whether the broker connection is up and the main loop
+// isn't stuck.
+func healthy() bool {
+	beat := lastBeat.Load()
+	stuck := beat != 0 && time.Since(time.Unix(0, beat)) > healthStale
+	return mqttClient != nil && mqttClient.IsConnected() && !stuck
+}
+
+// healthz answers fleet supervisors: 200 when healthy, 503 otherwise.
+func healthz(w http.ResponseWriter, r *http.Request) {
+	if !healthy() {
+		http.Error(w, "unhealthy", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// serveHealth serves /healthz on addr in the background.
+func serveHealth(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", healthz)
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Println("⚠ Health endpoint stopped:", err)
+		}
+	}()
+}
+
+// sdNotify sends a state such as "READY=1" to systemd. It does nothing
+// when not run as a notify service.
+func sdNotify(state string) {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return
+	}
+	if strings.HasPrefix(addr, "@") {
+		addr = "\x00" + addr[1:] // abstract socket
+	}
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.Write([]byte(state))
+}
+
+// daemonReady tells systemd the daemon is up, once its broker subscription
+// is confirmed, and starts the watchdog pings if systemd asked for them.
+// Pings stop while the daemon is unhealthy, so systemd restarts it.
+func daemonReady() {
+	sdNotify("READY=1")
+	usec, err := strconv.Atoi(os.Getenv("WATCHDOG_USEC"))
+	if err != nil || usec <= 0 {
+		return
+	}
+	go func() {
+		for range time.Tick(time.Duration(usec) * time.Microsecond / 2) {
+			if healthy() {
+				sdNotify("WATCHDOG=1")
+			}
+		}
+	}()
+}
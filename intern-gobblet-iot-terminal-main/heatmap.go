@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GameStats counts where the action happened during a game.
+type GameStats struct {
+	Landings  [][]int  // pieces placed or moved onto each cell
+	Gobbles   [][]int  // pieces covered on each cell
+	SizeUsage [2][]int // [player-1][size-1] pieces placed or moved
+}
+
+// newGrid returns an n x n grid of zero counts.
+func newGrid(n int) [][]int {
+	grid := make([][]int, n)
+	for i := range grid {
+		grid[i] = make([]int, n)
+	}
+	return grid
+}
+
+// gridFits reports whether grid is n x n.
+func gridFits(grid [][]int, n int) bool {
+	if len(grid) != n {
+		return false
+	}
+	for _, row := range grid {
+		if len(row) != n {
+			return false
+		}
+	}
+	return true
+}
+
+// fit returns s shaped for an n x n board and sizes piece sizes. Stats
+// arrive from peers, so a grid of any other shape is started afresh.
+func (s GameStats) fit(n, sizes int) GameStats {
+	if !gridFits(s.Landings, n) {
+		s.Landings = newGrid(n)
+	}
+	if !gridFits(s.Gobbles, n) {
+		s.Gobbles = newGrid(n)
+	}
+	for p := range s.SizeUsage {
+		if len(s.SizeUsage[p]) < sizes {
+			s.SizeUsage[p] = append(s.SizeUsage[p], make([]int, sizes-len(s.SizeUsage[p]))...)
+		}
+	}
+	return s
+}
+
+var gameStats GameStats
+
+// recordLanding updates the statistics for a piece landing on a cell.
+func recordLanding(row, col int, piece Gobblet) {
+	gameStats = gameStats.fit(board.Size(), gameRules().Sizes)
+	gameStats.Landings[row][col]++
+	if len(board[row][col]) > 0 {
+		gameStats.Gobbles[row][col]++
+	}
+	gameStats.SizeUsage[piece.Owner-1][piece.Size-1]++
+}
+
+// heatShades goes from cold to hot as 256-color backgrounds.
+var heatShades = []int{236, 24, 31, 142, 178, 202, 196}
+
+func heatColor(value, max int) string {
+	if max == 0 || value == 0 {
+		return fmt.Sprintf("\033[48;5;%dm", heatShades[0])
+	}
+	idx := 1 + value*(len(heatShades)-2)/max
+	return fmt.Sprintf("\033[48;5;%dm", heatShades[idx])
+}
+
+func printHeatGrid(title string, grid [][]int) {
+	max := 0
+	for i := range grid {
+		for j := range grid[i] {
+			if grid[i][j] > max {
+				max = grid[i][j]
+			}
+		}
+	}
+	fmt.Println(title)
+	for i := range grid {
+		fmt.Print("  ")
+		for j := range grid[i] {
+			fmt.Printf("%s %2d %s", heatColor(grid[i][j], max), grid[i][j], colorReset)
+		}
+		fmt.Println()
+	}
+}
+
+// printHeatMap renders the per-cell activity and piece-size usage.
+func printHeatMap() {
+	fmt.Println("\n🔥 Heat map")
+	printHeatGrid("Landings:", gameStats.Landings)
+	printHeatGrid("Gobbles:", gameStats.Gobbles)
+	fmt.Println("Piece sizes used (smallest to largest):")
+	for p := 0; p < 2; p++ {
+		counts := make([]string, variant.Rules().Sizes)
+		for s := range counts {
+			n := 0
+			if s < len(gameStats.SizeUsage[p]) {
+				n = gameStats.SizeUsage[p][s]
+			}
+			counts[s] = fmt.Sprint(n)
+		}
+		fmt.Printf("  Player %d: %s\n", p+1, strings.Join(counts, " / "))
+	}
+	fmt.Println()
+}
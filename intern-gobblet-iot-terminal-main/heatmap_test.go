@@ -0,0 +1,38 @@
+package main
+
+import (
+	"goblets/engine"
+	"testing"
+)
+
+func TestStatsFit(t *testing.T) {
+	for name, s := range map[string]GameStats{
+		"empty":            {},
+		"short inner rows": {Landings: [][]int{{1}, {2}, {3}}, Gobbles: [][]int{{}, {}, {}}},
+		"too many rows":    {Landings: newGrid(4), Gobbles: newGrid(4)},
+		"short size usage": {Landings: newGrid(3), Gobbles: newGrid(3), SizeUsage: [2][]int{{1}, nil}},
+	} {
+		got := s.fit(3, 3)
+		if !gridFits(got.Landings, 3) || !gridFits(got.Gobbles, 3) || len(got.SizeUsage[0]) < 3 || len(got.SizeUsage[1]) < 3 {
+			t.Errorf("%s: not shaped for a 3x3 board with three sizes: %+v", name, got)
+		}
+	}
+
+	kept := GameStats{Landings: [][]int{{1, 0, 0}, {0, 2, 0}, {0, 0, 0}}, Gobbles: newGrid(3), SizeUsage: [2][]int{{1, 0, 2}, {0, 0, 0}}}
+	if got := kept.fit(3, 3); got.Landings[1][1] != 2 || got.SizeUsage[0][2] != 2 {
+		t.Errorf("counts of the right shape were lost: %+v", got)
+	}
+}
+
+func TestRecordLandingSurvivesPeerStats(t *testing.T) {
+	oldBoard, oldStats := board, gameStats
+	t.Cleanup(func() { board, gameStats = oldBoard, oldStats })
+
+	board = engine.NewBoard(3)
+	// A peer's stats with the right number of rows but short ones
+	gameStats = GameStats{Landings: [][]int{{}, {}, {}}, Gobbles: [][]int{{}, {}, {}}}
+	recordLanding(2, 2, Gobblet{Size: 3, Owner: 2})
+	if gameStats.Landings[2][2] != 1 || gameStats.SizeUsage[1][2] != 1 {
+		t.Errorf("landing not counted: %+v", gameStats)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"goblets/engine"
+	"strings"
+)
+
+// promptCommand is one thing a player can type at the game prompt.
+type promptCommand struct {
+	Usage       string
+	Hint        string // short label for the hint bar
+	Description string
+	AnyTime     bool        // usable while waiting for the opponent too
+	Enabled     func() bool // nil = always available
+}
+
+// promptCommands is the command reference shown by help and the hint bar.
+// Commands that depend on the game's rules or negotiated features provide
+// an Enabled check.
+var promptCommands = []promptCommand{
+	{Usage: "1 x y size", Hint: "place", Description: "place a new piece of the given size on row x, column y (or 'P b2 L')", AnyTime: true},
+	{Usage: "S, M, L", Hint: "pick", Description: "pick a piece from your reserve, then type the cell to place it on"},
+	{Usage: "2 x1 y1 x2 y2", Hint: "move", Description: "move one of your visible pieces to another cell (or 'M a1 c3')", AnyTime: true},
+	{Usage: "3", Hint: "resign", Description: "resign the game", AnyTime: true, Enabled: seated},
+	{Usage: "4", Hint: "draw", Description: "offer a draw, or accept your opponent's offer", AnyTime: true, Enabled: seated},
+	{Usage: "hint", Hint: "hint", Description: "ask the computer for a good move, without playing it", Enabled: hintsAllowed},
+	{Usage: "moves", Hint: "moves", Description: "list the moves played so far", AnyTime: true},
+	{Usage: "position", Hint: "position", Description: "print the position as a short string to share or paste", AnyTime: true},
+	{Usage: "undo", Hint: "undo", Description: "ask your opponent to let you take back your last move", AnyTime: true, Enabled: canUndo},
+	{Usage: "redo", Hint: "redo", Description: "play the move you just took back again", Enabled: func() bool { _, ok := undoneMove(); return ok }},
+	{Usage: "accept", Hint: "accept", Description: "agree to your opponent's draw offer or takeback", AnyTime: true, Enabled: answerPending},
+	{Usage: "decline", Hint: "decline", Description: "refuse your opponent's draw offer or takeback", AnyTime: true, Enabled: answerPending},
+	{Usage: "/say text", Hint: "chat", Description: "send a chat message to the players and spectators", AnyTime: true, Enabled: chatAllowed},
+	{Usage: "pane", Hint: "pane", Description: "switch the side pane between moves, chat and status (or Ctrl+O)", AnyTime: true},
+	{Usage: "snapshot", Hint: "snapshot", Description: "save the session's internal state and recent messages for a bug report", AnyTime: true},
+	{Usage: "flip", Hint: "flip", Description: "turn the board view around for the player across the table", AnyTime: true},
+	{Usage: "help", Hint: "help", Description: "show this command reference", AnyTime: true},
+}
+
+func seated() bool {
+	return playerID == 1 || playerID == 2
+}
+
+// answerPending reports whether the opponent is waiting for an answer.
+func answerPending() bool {
+	return drawOfferedBy != 0 || takebackPending != nil
+}
+
+// availableCommands lists the commands usable right now.
+func availableCommands(myTurn bool) []promptCommand {
+	var cmds []promptCommand
+	for _, c := range promptCommands {
+		if (myTurn || c.AnyTime) && (c.Enabled == nil || c.Enabled()) {
+			cmds = append(cmds, c)
+		}
+	}
+	return cmds
+}
+
+// hintBar is the one-line summary printed above the prompt.
+func hintBar(myTurn bool) string {
+	var parts []string
+	for _, c := range availableCommands(myTurn) {
+		if c.Usage == c.Hint {
+			parts = append(parts, c.Usage)
+		} else {
+			parts = append(parts, fmt.Sprintf("%s: %s", c.Hint, c.Usage))
+		}
+	}
+	prefix := "💡 "
+	if !myTurn {
+		prefix = "💡 (premove) "
+	}
+	return prefix + strings.Join(parts, " · ")
+}
+
+// handlePromptCommand runs a non-move command typed at the prompt and
+// reports whether line was one.
+func handlePromptCommand(line string, myTurn bool) bool {
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "help", "?", "h":
+		printHelp(myTurn)
+	case "flip":
+		flipBoard()
+		printBoard()
+	case "moves":
+		printHistory()
+	case "hint":
+		switch {
+		case !hintsAllowed():
+			fmt.Println("❌ Hints are off in this game.")
+		case !myTurn:
+			fmt.Println("❌ Hints are only given on your turn.")
+		default:
+			printHint()
+		}
+	case "pane":
+		nextTab()
+	case "snapshot":
+		saveSnapshot("requested by the player")
+	case "position":
+		if memoryHidden() {
+			fmt.Println("\n❌ Position strings show hidden pieces, so they are off under the memory rule.")
+			break
+		}
+		fmt.Println("\n📋", engine.EncodePosition(position(board, playerTurn)))
+	case "undo":
+		requestTakeback()
+	case "accept", "decline":
+		accept := strings.EqualFold(strings.TrimSpace(line), "accept")
+		if drawOfferedBy != 0 {
+			answerDraw(accept)
+		} else {
+			answerTakeback(accept)
+		}
+	case "redo":
+		m, ok := undoneMove()
+		if !myTurn || !ok {
+			fmt.Println("❌ Nothing to redo.")
+		} else if err := playMove(m); err != nil {
+			fmt.Printf("❌ Cannot redo: %s.\n", moveErrorText(err))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// printHelp prints the full command reference for the current situation.
+func printHelp(myTurn bool) {
+	fmt.Println("\n📖 Commands:")
+	for _, c := range availableCommands(myTurn) {
+		fmt.Printf("  %-16s %s\n", c.Usage, c.Description)
+	}
+	fmt.Printf("  Rows and columns are numbered 0-%d from the top left of your view (%s).\n", board.Size()-1, orientation)
+	fmt.Printf("  In algebraic notation columns are a-%c from the left, rows 1-%d from the top and sizes S, M, L", 'a'+board.Size()-1, board.Size())
+	if variant.Rules().Sizes > 3 {
+		fmt.Print(", X")
+	}
+	fmt.Println(".")
+	if !myTurn {
+		fmt.Println("  Moves typed while waiting are queued as premoves.")
+	}
+}
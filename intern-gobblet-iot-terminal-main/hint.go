@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"goblets/ai"
+	"goblets/notation"
+)
+
+// hintsAllowed reports whether the local player may ask for hints. Rated
+// games need a preset that allows them, and the memory rule rules them out
+// because the search sees the hidden pieces.
+func hintsAllowed() bool {
+	return seated() && !gameRules().Memory && (!gameMeta.Rated || gameMeta.Rules.Assists.Hints)
+}
+
+// printHint prints the opening book's top move, or else searches the current
+// position at medium strength, without blunders. The turn does not end.
+func printHint() {
+	mu.Lock()
+	s := position(board.Clone(), playerTurn)
+	mu.Unlock()
+
+	if m, ok := openingBook().Best(s); ok {
+		fmt.Println("💡 Hint:", notation.Format(m), "(from the opening book)")
+		return
+	}
+	limits := ai.Levels["medium"]
+	limits.Blunder = 0
+	fmt.Println("🤔 Thinking...")
+	m, ok := ai.Choose(s, limits)
+	if !ok {
+		fmt.Println("❌ You have no legal move.")
+		return
+	}
+	fmt.Println("💡 Hint:", notation.Format(m))
+}
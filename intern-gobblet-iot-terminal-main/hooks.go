@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"os"
+	"os/exec"
+	"time"
+)
+
+const (
+	hookGameStart = "game_start"
+	hookMove      = "move"
+	hookGameEnd   = "game_end"
+)
+
+// HookEvent is the JSON payload written to a hook's stdin.
+type HookEvent struct {
+	Event  string
+	GameID string
+	Player int
+	Time   time.Time
+	State  GameState
+}
+
+var hookedMoves int // last move count a move hook fired for
+
+func hookCommands(event string) []string {
+	switch event {
+	case hookGameStart:
+		return config.Conf.Hooks.GameStart
+	case hookMove:
+		return config.Conf.Hooks.Move
+	case hookGameEnd:
+		return config.Conf.Hooks.GameEnd
+	}
+	return nil
+}
+
+// fireHook runs the commands configured for event with the given state.
+// With wait set it blocks until they finish, for events right before exit.
+func fireHook(event string, state GameState, wait bool) {
+	commands := hookCommands(event)
+	if len(commands) == 0 {
+		return
+	}
+	data, _ := json.Marshal(HookEvent{Event: event, GameID: gameID, Player: playerID, Time: time.Now().UTC(), State: state})
+
+	run := func() {
+		for _, command := range commands {
+			runHook(event, command, data)
+		}
+	}
+	if wait {
+		run()
+	} else {
+		go run()
+	}
+}
+
+func runHook(event, command string, data []byte) {
+	timeout := time.Duration(config.Conf.Hooks.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(), "GOBBLET_EVENT="+event, "GOBBLET_GAME_ID="+gameID)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		fmt.Printf("⚠ %s hook %q failed: %v %s\n", event, command, err, bytes.TrimSpace(out))
+	}
+}
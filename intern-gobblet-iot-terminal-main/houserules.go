@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"goblets/config"
+)
+
+// memoryHidden reports whether the memory rule hides the pieces under the
+// top of each stack from the local player. Game states and the move list
+// would give them away.
+func memoryHidden() bool {
+	return gameRules().Memory && seated()
+}
+
+// logText is payloadText for the 📤/📥 logs, which leave payloads out while
+// the memory rule hides pieces.
+func logText(payload []byte) string {
+	if memoryHidden() {
+		return fmt.Sprintf("(%d bytes, hidden under the memory rule)", len(payload))
+	}
+	return payloadText(payload)
+}
+
+// printHouseRules lists the house rules of the current game, and warns when
+// they differ from the local config, which only applies to new games.
+func printHouseRules() {
+	r := gameRules()
+	fmt.Println("📜 House rules:")
+	if r.Memory {
+		fmt.Println("   • memory: only the top piece of each stack is shown")
+	}
+	if r.NoReserveGobble {
+		fmt.Println("   • new pieces may not cover an opponent's piece")
+	}
+	if r.GobbleOnThreat {
+		fmt.Println("   • pieces can only gobble in a line the opponent is one short of")
+	}
+	c := config.Conf.Variants
+	if c.Memory != r.Memory || c.NoReserveGobble != r.NoReserveGobble || c.GobbleOnlyWhenThreatened != r.GobbleOnThreat {
+		fmt.Println("⚠ These differ from the variants in your config; this game's rules apply.")
+	}
+}
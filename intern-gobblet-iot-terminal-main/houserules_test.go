@@ -0,0 +1,31 @@
+package main
+
+import (
+	"goblets/engine"
+	"strings"
+	"testing"
+)
+
+func TestMemoryRuleHidesPieces(t *testing.T) {
+	oldRules, oldPlayer, oldHistory := houseRules, playerID, history
+	t.Cleanup(func() { houseRules, playerID, history = oldRules, oldPlayer, oldHistory })
+	history = sampleState().History
+	payload := encodeState(sampleState())
+
+	houseRules, playerID = &engine.Rules{Size: 3, Sizes: 3, Memory: true}, 1
+	if got := logText(payload); strings.Contains(got, "Board") {
+		t.Errorf("a seated player's log shows the state: %s", got)
+	}
+	if got := strings.Join(movesTab(), "\n"); strings.Contains(got, "P b2 L") {
+		t.Errorf("a seated player's side pane lists the moves: %s", got)
+	}
+
+	playerID = spectatorID
+	if got := logText(payload); got != payloadText(payload) {
+		t.Errorf("a spectator's log hides the state: %s", got)
+	}
+	houseRules, playerID = nil, 1
+	if got := strings.Join(movesTab(), "\n"); !strings.Contains(got, "P b2 L") {
+		t.Errorf("the side pane doesn't list the moves without the memory rule: %s", got)
+	}
+}
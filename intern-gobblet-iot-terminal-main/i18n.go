@@ -0,0 +1,73 @@
+package main
+
+import (
+	"goblets/config"
+	"strings"
+)
+
+// catalogs holds the translated texts by language and message key. A key
+// may have several variants to pick from. English is the fallback for a
+// missing language or key.
+var catalogs = map[string]map[string][]string{
+	"en": {
+		"banter.friendly.start":   {"Hi {player}! Have a good game.", "Good luck, {player}!"},
+		"banter.friendly.gobble":  {"Sorry, I had to gobble that one.", "Gulp! Nothing personal."},
+		"banter.friendly.blunder": {"Hmm, are you sure about that move?", "Careful, I think I see something..."},
+		"banter.friendly.win":     {"Good game, {player}! Rematch?", "That was close. Thanks for playing!"},
+		"banter.friendly.lose":    {"Well played, {player}!", "You got me. Nicely done!"},
+		"banter.friendly.draw":    {"A draw, well fought!"},
+		"banter.taunting.start":   {"Ready to lose, {player}?", "I hope you brought big pieces."},
+		"banter.taunting.gobble":  {"Yum. Got any more of those?", "Gobbled! Tasty."},
+		"banter.taunting.blunder": {"Oh, you shouldn't have done that.", "Thank you for that gift!"},
+		"banter.taunting.win":     {"Too easy.", "Better luck next time, {player}."},
+		"banter.taunting.lose":    {"Beginner's luck.", "Fine. I'll get you next time."},
+		"banter.taunting.draw":    {"A draw? You were lucky."},
+	},
+	"de": {
+		"banter.friendly.start":   {"Hallo {player}! Viel Spaß beim Spiel.", "Viel Glück, {player}!"},
+		"banter.friendly.gobble":  {"Tut mir leid, den musste ich schlucken.", "Schluck! Nichts Persönliches."},
+		"banter.friendly.blunder": {"Hmm, bist du sicher mit diesem Zug?", "Vorsicht, ich glaube, ich sehe da etwas..."},
+		"banter.friendly.win":     {"Gutes Spiel, {player}! Revanche?", "Das war knapp. Danke fürs Spielen!"},
+		"banter.friendly.lose":    {"Gut gespielt, {player}!", "Du hast mich erwischt. Schön gemacht!"},
+		"banter.friendly.draw":    {"Unentschieden, gut gekämpft!"},
+		"banter.taunting.start":   {"Bereit zu verlieren, {player}?", "Ich hoffe, du hast große Figuren dabei."},
+		"banter.taunting.gobble":  {"Lecker. Hast du noch mehr davon?", "Geschluckt! Köstlich."},
+		"banter.taunting.blunder": {"Oh, das hättest du nicht tun sollen.", "Danke für das Geschenk!"},
+		"banter.taunting.win":     {"Zu einfach.", "Mehr Glück beim nächsten Mal, {player}."},
+		"banter.taunting.lose":    {"Anfängerglück.", "Na gut. Nächstes Mal kriege ich dich."},
+		"banter.taunting.draw":    {"Unentschieden? Da hattest du Glück."},
+	},
+	"fr": {
+		"banter.friendly.start":   {"Salut {player} ! Bonne partie.", "Bonne chance, {player} !"},
+		"banter.friendly.gobble":  {"Désolé, je devais gober celle-là.", "Gloups ! Rien de personnel."},
+		"banter.friendly.blunder": {"Hmm, tu es sûr de ce coup ?", "Attention, je crois voir quelque chose..."},
+		"banter.friendly.win":     {"Bien joué, {player} ! Une revanche ?", "C'était serré. Merci pour la partie !"},
+		"banter.friendly.lose":    {"Bien joué, {player} !", "Tu m'as eu. Joli !"},
+		"banter.friendly.draw":    {"Match nul, belle bataille !"},
+		"banter.taunting.start":   {"Prêt à perdre, {player} ?", "J'espère que tu as apporté de grandes pièces."},
+		"banter.taunting.gobble":  {"Miam. Tu en as d'autres ?", "Gobé ! Délicieux."},
+		"banter.taunting.blunder": {"Oh, tu n'aurais pas dû faire ça.", "Merci pour ce cadeau !"},
+		"banter.taunting.win":     {"Trop facile.", "Plus de chance la prochaine fois, {player}."},
+		"banter.taunting.lose":    {"La chance du débutant.", "Bon. Je t'aurai la prochaine fois."},
+		"banter.taunting.draw":    {"Match nul ? Tu as eu de la chance."},
+	},
+}
+
+// translations returns the variants of key in the configured language,
+// with {name} placeholders filled from vars.
+func translations(key string, vars map[string]string) []string {
+	texts, ok := catalogs[config.Conf.Language][key]
+	if !ok {
+		texts = catalogs["en"][key]
+	}
+	pairs := make([]string, 0, 2*len(vars))
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	r := strings.NewReplacer(pairs...)
+	out := make([]string, len(texts))
+	for i, t := range texts {
+		out[i] = r.Replace(t)
+	}
+	return out
+}
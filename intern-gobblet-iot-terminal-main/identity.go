@@ -0,0 +1,161 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goblets/config"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// SeatClaim is a player's signed statement that it holds a seat in a game.
+type SeatClaim struct {
+	GameID    string
+	Player    int
+	Device    string
+	Session   string // client ID of the connection holding the seat
+	Time      time.Time
+	PublicKey []byte
+	Signature []byte `json:",omitempty"`
+}
+
+func claimTopic(player int) string {
+	return fmt.Sprintf("gobblet/game/%s/claims/%d", gameID, player)
+}
+
+func (c SeatClaim) signedBytes() []byte {
+	c.Signature = nil
+	data, _ := json.Marshal(c)
+	return data
+}
+
+// Verify checks the claim signature against the embedded public key.
+func (c SeatClaim) Verify() bool {
+	if len(c.PublicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(c.PublicKey, c.signedBytes(), c.Signature)
+}
+
+// publishSeatClaim signs and publishes a retained claim for the local seat.
+func publishSeatClaim() error {
+	key, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+	claim := SeatClaim{
+		GameID:    gameID,
+		Player:    playerID,
+		Device:    deviceID,
+		Session:   clientID,
+		Time:      time.Now().UTC(),
+		PublicKey: key.Public().(ed25519.PublicKey),
+	}
+	claim.Signature = ed25519.Sign(key, claim.signedBytes())
+
+	data, _ := json.Marshal(claim)
+	token := mqttClient.Publish(claimTopic(playerID), 1, true, data)
+	token.Wait()
+	return token.Error()
+}
+
+// waitForSeatClaim waits for the given player's retained seat claim.
+func waitForSeatClaim(player int, timeout time.Duration) (*SeatClaim, error) {
+	claims := make(chan SeatClaim, 1)
+	topic := claimTopic(player)
+	token := mqttClient.Subscribe(topic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		var c SeatClaim
+		if err := json.Unmarshal(msg.Payload(), &c); err != nil {
+			return
+		}
+		select {
+		case claims <- c:
+		default:
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	defer mqttClient.Unsubscribe(topic)
+
+	select {
+	case c := <-claims:
+		return &c, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("player %d has not claimed a seat", player)
+	}
+}
+
+// trustedKeysFile returns the local known-keys registry, one
+// "<base64 public key> <name>" entry per line.
+func trustedKeysFile() string {
+	if config.Conf.StrictIdentity.KnownKeysFile != "" {
+		return config.Conf.StrictIdentity.KnownKeysFile
+	}
+	return filepath.Join(keyDir(), "trusted")
+}
+
+// lookupKnownKey returns the registered name for a public key, checking the
+// local registry file first and then the registry API if one is configured.
+func lookupKnownKey(pub []byte) (string, error) {
+	encoded := base64.StdEncoding.EncodeToString(pub)
+
+	keys, err := readTrustedKeys()
+	if err != nil {
+		return "", err
+	}
+	for _, k := range keys {
+		if k.PublicKey == encoded {
+			return k.Name, nil
+		}
+	}
+
+	if config.Conf.StrictIdentity.RegistryURL != "" {
+		client := http.Client{Timeout: 3 * time.Second}
+		resp, err := client.Get(strings.TrimRight(config.Conf.StrictIdentity.RegistryURL, "/") + "/" + url.PathEscape(encoded))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			var entry struct {
+				Name string `json:"name"`
+			}
+			json.NewDecoder(resp.Body).Decode(&entry)
+			return entry.Name, nil
+		}
+	}
+	return "", errors.New("key is not in the known-keys registry")
+}
+
+// verifyOpponentIdentity enforces strict mode for rated games: the opponent
+// must have published a valid signed seat claim from a known key.
+func verifyOpponentIdentity() error {
+	if !gameMeta.Rated || !config.Conf.StrictIdentity.Enabled {
+		return nil
+	}
+
+	opponent := 3 - playerID
+	fmt.Printf("🔐 Strict mode: waiting for Player %d's signed seat claim...\n", opponent)
+	claim, err := waitForSeatClaim(opponent, 60*time.Second)
+	if err != nil {
+		return err
+	}
+	if claim.GameID != gameID || claim.Player != opponent || !claim.Verify() {
+		return fmt.Errorf("player %d's seat claim has an invalid signature", opponent)
+	}
+	name, err := lookupKnownKey(claim.PublicKey)
+	if err != nil {
+		return fmt.Errorf("player %d: %w", opponent, err)
+	}
+	fmt.Printf("✅ Verified Player %d as %s\n", opponent, name)
+	return nil
+}
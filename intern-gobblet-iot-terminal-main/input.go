@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"goblets/engine"
+	"goblets/notation"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	actionPlace = 1
+	actionMove  = 2
+)
+
+// Action is one parsed line of player input.
+type Action struct {
+	Kind int
+	Args []int
+}
+
+// String formats the action the way it is typed.
+func (a Action) String() string {
+	s := fmt.Sprint(a.Kind)
+	for _, n := range a.Args {
+		s += fmt.Sprint(" ", n)
+	}
+	return s
+}
+
+// parseAction reads "1 x y size" (place), "2 x1 y1 x2 y2" (move), "3"
+// (resign) or "4" (draw), or moves in algebraic notation: "P b2 L" or
+// "M a1 c3".
+func parseAction(line string) (Action, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Action{}, errors.New("empty input")
+	}
+	if notation.IsAlgebraic(line) {
+		m, err := notation.Parse(line)
+		if err != nil {
+			return Action{}, err
+		}
+		return actionOf(m), nil
+	}
+	nums := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return Action{}, errors.New("invalid input, please try again")
+		}
+		nums[i] = n
+	}
+
+	switch nums[0] {
+	case actionPlace:
+		if len(nums) != 4 {
+			return Action{}, errors.New("invalid input for place action, use '1 x y size'")
+		}
+	case actionMove:
+		if len(nums) != 5 {
+			return Action{}, errors.New("invalid input for move action, use '2 x1 y1 x2 y2'")
+		}
+	case actionResign, actionDraw:
+		if len(nums) != 1 {
+			return Action{}, errors.New("resign with '3' and offer a draw with '4'")
+		}
+	default:
+		return Action{}, errors.New("invalid action! Use 1 or P to place, 2 or M to move, 3 to resign, 4 for a draw, or type help")
+	}
+	return Action{Kind: nums[0], Args: nums[1:]}, nil
+}
+
+// applyAction plays a parsed action for the local player.
+func applyAction(a Action) error {
+	switch a.Kind {
+	case actionPlace:
+		return placePiece(a.Args[0], a.Args[1], a.Args[2])
+	case actionResign:
+		resign()
+		return nil
+	case actionDraw:
+		offerDraw()
+		return nil
+	}
+	return movePiece(a.Args[0], a.Args[1], a.Args[2], a.Args[3])
+}
+
+// moveErrorText explains why the engine rejected a move.
+func moveErrorText(err error) string {
+	switch {
+	case errors.Is(err, engine.ErrOutOfBounds):
+		return fmt.Sprintf("that cell is off the board (rows and columns go from 0 to %d)", board.Size()-1)
+	case errors.Is(err, engine.ErrSmallerOnLarger):
+		return "a piece can only gobble a smaller one"
+	case errors.Is(err, engine.ErrNotYourPiece):
+		return "that piece belongs to your opponent"
+	case errors.Is(err, engine.ErrNoPiece):
+		return "there is no piece on that cell"
+	case errors.Is(err, engine.ErrReserveEmpty):
+		return "you have no piece of that size left to bring in"
+	case errors.Is(err, engine.ErrEntryNotEmpty):
+		return "new pieces enter on an empty cell, unless they gobble a piece in your opponent's almost-complete line"
+	case errors.Is(err, engine.ErrReserveGobble):
+		return "house rules: new pieces may not cover your opponent's pieces"
+	case errors.Is(err, engine.ErrNotThreatening):
+		return "house rules: you can only gobble a piece in a line your opponent is one short of"
+	}
+	return err.Error()
+}
+
+// readTurnInput waits for the local player's move, beeping each second
+// while their clock is low.
+func readTurnInput(input <-chan string) (string, bool) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-input:
+			return line, ok
+		case <-ticker.C:
+			if clocks != nil {
+				beepIfLow()
+				checkFlag()
+			}
+		}
+	}
+}
+
+// startInputReader reads stdin lines in the background so the game loop can
+// react to input and network events at the same time.
+func startInputReader() <-chan string {
+	lines := make(chan string)
+	go func() {
+		for {
+			line, ok := readPrompt("» ")
+			if !ok {
+				break
+			}
+			if text, ok := chatInput(line); ok {
+				sendChat(text)
+				continue
+			}
+			if line != "" {
+				lines <- line
+			}
+		}
+		close(lines)
+	}()
+	return lines
+}
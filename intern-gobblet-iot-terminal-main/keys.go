@@ -0,0 +1,57 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// keyDir is where the local signing key pair is kept.
+func keyDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".gobblet", "keys")
+}
+
+// loadSigningKey reads the local ed25519 signing key, generating and saving
+// a new one on first use.
+func loadSigningKey() (ed25519.PrivateKey, error) {
+	path := filepath.Join(keyDir(), "signing.key")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return generateSigningKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("corrupt signing key in " + path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func generateSigningKey() (ed25519.PrivateKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(keyDir(), 0o700); err != nil {
+		return nil, err
+	}
+	seed := base64.StdEncoding.EncodeToString(priv.Seed())
+	if err := os.WriteFile(filepath.Join(keyDir(), "signing.key"), []byte(seed+"\n"), 0o600); err != nil {
+		return nil, err
+	}
+	encodedPub := base64.StdEncoding.EncodeToString(pub)
+	if err := os.WriteFile(filepath.Join(keyDir(), "signing.pub"), []byte(encodedPub+"\n"), 0o644); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"goblets/config"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// trustedKey is one entry of the known-keys registry file.
+type trustedKey struct {
+	PublicKey string
+	Name      string
+}
+
+func readTrustedKeys() ([]trustedKey, error) {
+	f, err := os.Open(trustedKeysFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []trustedKey
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		keys = append(keys, trustedKey{PublicKey: fields[0], Name: strings.Join(fields[1:], " ")})
+	}
+	return keys, scanner.Err()
+}
+
+// trustedKeyName returns the name a public key is trusted under, or the
+// start of the key and false when it isn't ours or in the registry file.
+func trustedKeyName(pub []byte) (string, bool) {
+	encoded := base64.StdEncoding.EncodeToString(pub)
+	if local, err := localPublicKey(); err == nil && local == encoded {
+		return "you", true
+	}
+	keys, _ := readTrustedKeys()
+	for _, k := range keys {
+		if k.PublicKey == encoded {
+			return k.Name, true
+		}
+	}
+	return encoded[:8], false
+}
+
+func writeTrustedKeys(keys []trustedKey) error {
+	if err := os.MkdirAll(filepath.Dir(trustedKeysFile()), 0o700); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s %s\n", k.PublicKey, k.Name)
+	}
+	return os.WriteFile(trustedKeysFile(), []byte(b.String()), 0o600)
+}
+
+func localPublicKey() (string, error) {
+	key, err := loadSigningKey()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)), nil
+}
+
+// runKeys manages the local signing key pair and trusted peer keys.
+func runKeys(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: keys generate|export|import|list|revoke")
+		os.Exit(1)
+	}
+
+	var err error
+	switch args[0] {
+	case "generate":
+		err = keysGenerate(args[1:])
+	case "export":
+		var pub string
+		if pub, err = localPublicKey(); err == nil {
+			fmt.Println(pub, config.Conf.PlayerName)
+		}
+	case "import":
+		err = keysImport(args[1:])
+	case "list":
+		err = keysList()
+	case "revoke":
+		err = keysRevoke(args[1:])
+	default:
+		err = fmt.Errorf("unknown keys command %q", args[0])
+	}
+	if err != nil {
+		fmt.Println("❌", err)
+		os.Exit(1)
+	}
+}
+
+func keysGenerate(args []string) error {
+	fs := flag.NewFlagSet("keys generate", flag.ExitOnError)
+	force := fs.Bool("force", false, "replace an existing signing key")
+	fs.Parse(args)
+
+	if _, err := os.Stat(filepath.Join(keyDir(), "signing.key")); err == nil && !*force {
+		return errors.New("a signing key already exists, use --force to replace it")
+	}
+	if _, err := generateSigningKey(); err != nil {
+		return err
+	}
+	pub, err := localPublicKey()
+	if err != nil {
+		return err
+	}
+	fmt.Println("✅ Generated new signing key:", pub)
+	return nil
+}
+
+func keysImport(args []string) error {
+	if len(args) < 2 {
+		return errors.New("usage: keys import <public key> <name>")
+	}
+	pub, err := base64.StdEncoding.DecodeString(args[0])
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return errors.New("not a valid ed25519 public key")
+	}
+	keys, err := readTrustedKeys()
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.PublicKey == args[0] {
+			return fmt.Errorf("key is already trusted as %q", k.Name)
+		}
+	}
+	name := strings.Join(args[1:], " ")
+	if err := writeTrustedKeys(append(keys, trustedKey{PublicKey: args[0], Name: name})); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Trusted key for %s\n", name)
+	return nil
+}
+
+func keysList() error {
+	pub, err := localPublicKey()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Local signing key:")
+	fmt.Println(" ", pub)
+
+	keys, err := readTrustedKeys()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Trusted keys (%s):\n", trustedKeysFile())
+	if len(keys) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, k := range keys {
+		fmt.Printf("  %s  %s\n", k.PublicKey, k.Name)
+	}
+	return nil
+}
+
+func keysRevoke(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: keys revoke <public key or name>")
+	}
+	keys, err := readTrustedKeys()
+	if err != nil {
+		return err
+	}
+	kept := keys[:0]
+	revoked := 0
+	for _, k := range keys {
+		if k.PublicKey == args[0] || k.Name == args[0] {
+			revoked++
+			continue
+		}
+		kept = append(kept, k)
+	}
+	if revoked == 0 {
+		return fmt.Errorf("no trusted key matches %q", args[0])
+	}
+	if err := writeTrustedKeys(kept); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Revoked %d key(s)\n", revoked)
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"sync/atomic"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Ping measures the round trip to the opponent's terminal. The opponent
+// echoes it back with Reply set, so Sent is always read on the clock that
+// wrote it.
+type Ping struct {
+	Player int
+	Sent   int64 // unix nanoseconds
+	Reply  bool  `json:",omitempty"`
+}
+
+const pingInterval = 10 * time.Second
+
+// measuredRTT is the smoothed round-trip time to the opponent, in
+// nanoseconds; 0 until the first reply.
+var measuredRTT atomic.Int64
+
+func pingTopic() string {
+	return "gobblet/game/" + gameID + "/ping"
+}
+
+// latencyCredit is the time refunded on each move for the opponent's move
+// reaching us late: the measured round trip, up to clock.latency_cap.
+func latencyCredit() time.Duration {
+	limit := time.Duration(config.Conf.Clock.LatencyCap) * time.Millisecond
+	return min(time.Duration(measuredRTT.Load()), limit)
+}
+
+func onPing(client mqtt.Client, msg mqtt.Message) {
+	var p Ping
+	if json.Unmarshal(msg.Payload(), &p) != nil {
+		return
+	}
+	switch {
+	case p.Player == playerID && p.Reply:
+		rtt := time.Now().UnixNano() - p.Sent
+		// Smooth like TCP does so one slow packet doesn't swing the clock
+		if old := measuredRTT.Load(); old != 0 {
+			rtt = (7*old + rtt) / 8
+		}
+		measuredRTT.Store(rtt)
+	case p.Player != playerID && !p.Reply:
+		p.Reply = true
+		data, _ := json.Marshal(p)
+		client.Publish(replyTopic(msg, pingTopic()), 0, false, data)
+	}
+}
+
+// watchLatency answers the opponent's pings and measures our own round
+// trip while the game has a clock. It is skipped in bandwidth budget mode.
+func watchLatency() {
+	if clocks == nil || config.Conf.Clock.LatencyCap <= 0 || budgetMode() {
+		return
+	}
+	// With MQTT 5 replies come back on our own topic instead of to everyone
+	replies := pingTopic() + "/" + clientID
+	filters := map[string]byte{pingTopic(): 0, replies: 0}
+	if token := mqttClient.SubscribeMultiple(filters, onPing); token.Wait() && token.Error() != nil {
+		fmt.Println("⚠ Could not subscribe to latency pings:", token.Error())
+		return
+	}
+	go func() {
+		for {
+			data, _ := json.Marshal(Ping{Player: playerID, Sent: time.Now().UnixNano()})
+			publishRequest(pingTopic(), 0, data, replies)
+			time.Sleep(pingInterval)
+		}
+	}()
+}
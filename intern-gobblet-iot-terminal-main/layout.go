@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chzyer/readline"
+)
+
+const (
+	paneGap     = 4 // columns between the board and the side pane
+	recentMoves = 8 // moves listed in the side pane
+	recentChat  = 8 // chat lines listed in the side pane
+)
+
+// The side pane shows one tab at a time; Ctrl+O or the pane command
+// switches to the next.
+const (
+	tabMoves = iota
+	tabChat
+	tabStatus
+)
+
+var tabNames = []string{"Moves", "Chat", "Status"}
+
+// keyNextTab is Ctrl+O, which the line editor leaves unbound.
+const keyNextTab = 15
+
+var (
+	sideTab   = tabMoves
+	chatLines []string // chat received during the game, oldest first
+)
+
+// nextTab switches the side pane to its next tab and redraws.
+func nextTab() {
+	sideTab = (sideTab + 1) % len(tabNames)
+	redrawScreen()
+}
+
+// tabBar names the tabs, with the one in focus in brackets.
+func tabBar() string {
+	var parts []string
+	for i, name := range tabNames {
+		if i == sideTab {
+			name = "[" + name + "]"
+		}
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, " ") + "  (Ctrl+O)"
+}
+
+// terminalWidth returns the terminal's width in columns, or 80 when it
+// can't be read.
+func terminalWidth() int {
+	if w := readline.GetScreenWidth(); w > 0 {
+		return w
+	}
+	return 80
+}
+
+// visibleWidth counts the columns s takes on screen, skipping color codes.
+func visibleWidth(s string) int {
+	n, escape := 0, false
+	for _, r := range s {
+		switch {
+		case r == '\033':
+			escape = true
+		case escape:
+			escape = r != 'm'
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// sidePane holds the clocks and the tab in focus, shown beside the board.
+func sidePane() []string {
+	lines := []string{"", tabBar()}
+	if clocks != nil {
+		lines = append(lines, clockLine())
+	}
+	lines = append(lines, "")
+	switch sideTab {
+	case tabMoves:
+		lines = append(lines, movesTab()...)
+	case tabChat:
+		lines = append(lines, chatTab()...)
+	case tabStatus:
+		lines = append(lines, statusTab()...)
+	}
+	return lines
+}
+
+func movesTab() []string {
+	if memoryHidden() {
+		return []string{"🙈 Hidden under the memory rule."}
+	}
+	moves := history
+	if len(moves) > recentMoves {
+		moves = moves[len(moves)-recentMoves:]
+	}
+	if len(moves) == 0 {
+		return []string{"📜 No moves yet."}
+	}
+	var lines []string
+	for i, e := range moves {
+		lines = append(lines, fmt.Sprintf("%3d. %s", len(history)-len(moves)+i+1, e))
+	}
+	return lines
+}
+
+func chatTab() []string {
+	if len(chatLines) == 0 {
+		return []string{"💬 No messages yet."}
+	}
+	return chatLines[max(len(chatLines)-recentChat, 0):]
+}
+
+func statusTab() []string {
+	connection := "❌ disconnected"
+	if mqttClient != nil && mqttClient.IsConnected() {
+		connection = "✅ connected"
+	}
+	seat := fmt.Sprintf("Player %d", playerID)
+	switch playerID {
+	case spectatorID:
+		seat = "spectator"
+	case refereeID:
+		seat = "referee"
+	}
+	lines := []string{
+		"Game    " + gameID + " (" + variant.String() + ")",
+		"Seat    " + seat,
+		"Broker  " + connection,
+	}
+	if rtt := time.Duration(measuredRTT.Load()); rtt > 0 {
+		lines = append(lines, "Ping    "+rtt.Round(time.Millisecond).String())
+	}
+	if line := presenceLine(); line != "" {
+		lines = append(lines, "Online  "+line)
+	}
+	if seated() {
+		lines = append(lines, "Link    "+linkStatus())
+		if seq := unconfirmedMove(); seq != 0 {
+			lines = append(lines, fmt.Sprintf("Moves   ⚠ move %d not confirmed", seq))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("Moves   %d, Player %d to move", moveCount, playerTurn))
+	if paused {
+		lines = append(lines, "⏸ Paused by the referee")
+	}
+	return lines
+}
+
+// boardPanes lays out the board and the side pane as they are drawn now.
+func boardPanes() []string {
+	var b strings.Builder
+	if gameMeta.Rules.Assists.LargeGlyphs {
+		printLargeBoard(&b)
+	} else {
+		printCompactBoard(&b)
+	}
+	return panes(strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n"), sidePane())
+}
+
+// panes puts left and right side by side when the terminal is wide enough
+// for both, and stacks them on narrow terminals.
+func panes(left, right []string) []string {
+	leftWidth, rightWidth := 0, 0
+	for _, l := range left {
+		leftWidth = max(leftWidth, visibleWidth(l))
+	}
+	for _, r := range right {
+		rightWidth = max(rightWidth, visibleWidth(r))
+	}
+
+	if len(right) == 0 || leftWidth+paneGap+rightWidth > terminalWidth() {
+		return append(left, right...)
+	}
+	var lines []string
+	for i := 0; i < max(len(left), len(right)); i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		line := l + strings.Repeat(" ", leftWidth-visibleWidth(l)+paneGap) + r
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+// redrawScreen draws the board and side pane again, e.g. after the
+// terminal is resized or the side pane switches tabs.
+func redrawScreen() {
+	if board == nil {
+		return // no game on screen yet
+	}
+	if playerID == spectatorID {
+		requestRedraw()
+		return
+	}
+	mu.Lock()
+	printBoard()
+	mu.Unlock()
+	if lineEditor != nil {
+		lineEditor.Refresh()
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// leagueEnabled reports whether finished games are sent to a league.
+func leagueEnabled() bool {
+	return config.Conf.League.URL != ""
+}
+
+// outboxDir holds league reports that could not be delivered yet.
+func outboxDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "outbox"
+	}
+	return filepath.Join(home, ".gobblet", "outbox")
+}
+
+// leagueBody renders the report for a finished game with the configured
+// template, or as the archive record's JSON when there is none.
+func leagueBody(record *ArchiveRecord) ([]byte, error) {
+	if config.Conf.League.Template == "" {
+		return json.Marshal(record)
+	}
+	tmpl, err := template.New("league").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(config.Conf.League.Template)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, record); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// postLeague sends one report to the league API.
+func postLeague(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, config.Conf.League.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if name, value, ok := strings.Cut(config.Conf.League.AuthHeader, ":"); ok {
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("league returned %s", resp.Status)
+	}
+	return nil
+}
+
+// reportLeague submits a finished game, retrying with backoff. Reports
+// that still fail wait in the outbox for the next game or `league-flush`.
+func reportLeague(record *ArchiveRecord) {
+	if !leagueEnabled() || record.Player == 0 {
+		return
+	}
+	flushOutbox()
+	body, err := leagueBody(record)
+	if err != nil {
+		fmt.Println("❌ Could not build league report:", err)
+		return
+	}
+	backoff := time.Second
+	for attempt := 1; attempt <= max(config.Conf.League.Retries, 1); attempt++ {
+		if err = postLeague(body); err == nil {
+			fmt.Printf("📨 Result for game %s reported to the league\n", record.GameID)
+			return
+		}
+		fmt.Printf("⚠ League report for game %s failed (attempt %d): %v\n", record.GameID, attempt, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	name := fmt.Sprintf("%s-%d.json", record.GameID, record.Player)
+	if err := os.MkdirAll(outboxDir(), 0o700); err == nil {
+		err = os.WriteFile(filepath.Join(outboxDir(), name), body, 0o600)
+	}
+	if err != nil {
+		fmt.Println("❌ Could not save league report to the outbox:", err)
+		return
+	}
+	fmt.Println("📮 League report saved to the outbox, it will be sent later")
+}
+
+// flushOutbox retries every report waiting in the outbox and returns how
+// many are still undelivered.
+func flushOutbox() int {
+	files, _ := filepath.Glob(filepath.Join(outboxDir(), "*.json"))
+	left := 0
+	for _, file := range files {
+		body, err := os.ReadFile(file)
+		if err == nil {
+			err = postLeague(body)
+		}
+		if err != nil {
+			left++
+			continue
+		}
+		os.Remove(file)
+		fmt.Println("📨 Delivered queued league report", strings.TrimSuffix(filepath.Base(file), ".json"))
+	}
+	return left
+}
+
+// runLeagueFlush sends queued league reports, e.g. from cron once the
+// device is back online.
+func runLeagueFlush(args []string) {
+	if !leagueEnabled() {
+		fmt.Println("❌ Set league.url in config.yaml first")
+		os.Exit(1)
+	}
+	if left := flushOutbox(); left > 0 {
+		fmt.Printf("⚠ %d league report(s) still waiting in %s\n", left, outboxDir())
+		os.Exit(1)
+	}
+	fmt.Println("✅ League outbox is empty")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// linkPolicy is how hard a game works to get its states through. On a lossy
+// link states go out with QoS 2 and the player on turn republishes the
+// current state as a checkpoint, so a lost update is repaired even when no
+// further move is made.
+type linkPolicy struct {
+	Name       string
+	QoS        byte
+	Checkpoint time.Duration // 0 for no checkpoints
+}
+
+var linkPolicies = []linkPolicy{
+	{Name: "steady", QoS: 1},
+	{Name: "lossy", QoS: 2, Checkpoint: 30 * time.Second},
+	{Name: "very lossy", QoS: 2, Checkpoint: 10 * time.Second},
+}
+
+const (
+	linkLossThreshold = 3                // losses that step the policy up
+	linkStableAfter   = 2 * time.Minute  // time without losses before stepping down
+	ackTimeout        = 10 * time.Second // a state publish not acknowledged by then counts as lost
+)
+
+var (
+	linkMu         sync.Mutex
+	linkLevel      int // index into linkPolicies
+	linkLosses     int // ack failures and resync requests since the last change
+	linkLastLoss   time.Time
+	linkCheckpoint time.Time // last checkpoint published
+)
+
+// currentLinkPolicy returns the policy in use.
+func currentLinkPolicy() linkPolicy {
+	linkMu.Lock()
+	defer linkMu.Unlock()
+	return linkPolicies[linkLevel]
+}
+
+// stateQoS is the QoS game states are published with.
+func stateQoS() byte {
+	return currentLinkPolicy().QoS
+}
+
+// noteLinkLoss counts an ack failure or resync request, stepping the policy
+// up when they pile up.
+func noteLinkLoss(reason string) {
+	linkMu.Lock()
+	defer linkMu.Unlock()
+	linkLosses++
+	linkLastLoss = time.Now()
+	if linkLosses >= linkLossThreshold && linkLevel < len(linkPolicies)-1 {
+		linkLevel++
+		linkLosses = 0
+		p := linkPolicies[linkLevel]
+		fmt.Printf("\n⚠ The link looks %s (%s): sending with QoS %d and a checkpoint every %s\n", p.Name, reason, p.QoS, p.Checkpoint)
+	}
+}
+
+// watchAck counts a state publish that isn't acknowledged in time as lost.
+func watchAck(token mqtt.Token) {
+	if !token.WaitTimeout(ackTimeout) || token.Error() != nil {
+		noteLinkLoss("unacknowledged publish")
+	}
+}
+
+// watchLink backs the policy off once the link has been quiet for a while
+// and publishes checkpoints while the policy asks for them.
+func watchLink() {
+	go func() {
+		for range time.Tick(time.Second) {
+			linkMu.Lock()
+			if time.Since(linkLastLoss) > linkStableAfter {
+				linkLosses = 0
+				if linkLevel > 0 {
+					linkLevel--
+					linkLastLoss = time.Now() // stay here a full period before the next step
+					fmt.Printf("\n✅ The link is %s again\n", linkPolicies[linkLevel].Name)
+				}
+			}
+			p := linkPolicies[linkLevel]
+			due := p.Checkpoint > 0 && time.Since(linkCheckpoint) >= p.Checkpoint
+			if due {
+				linkCheckpoint = time.Now()
+			}
+			linkMu.Unlock()
+			if due {
+				publishCheckpoint()
+			}
+		}
+	}()
+}
+
+// publishCheckpoint republishes the current state. Only the player on turn
+// does this: the other player may not have their latest move yet.
+func publishCheckpoint() {
+	mu.Lock()
+	if playerTurn != playerID || gameResult != nil {
+		mu.Unlock()
+		return
+	}
+	state := nextState()
+	state.Control = nil // a resignation or offer is only sent once
+	mu.Unlock()
+
+	publishGameState(encodeState(state))
+}
+
+// linkStatus describes the policy for the status pane.
+func linkStatus() string {
+	p := currentLinkPolicy()
+	if p.Checkpoint == 0 {
+		return fmt.Sprintf("%s, QoS %d", p.Name, p.QoS)
+	}
+	return fmt.Sprintf("%s, QoS %d, checkpoint every %s", p.Name, p.QoS, p.Checkpoint)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"goblets/engine"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const loadtestMaxMoves = 60 // simulated games longer than this are abandoned
+
+// loadtestMessage wraps a game state with the send time so the receiver can
+// measure delivery latency.
+type loadtestMessage struct {
+	State  GameState
+	SentAt int64
+}
+
+// loadtestStats collects results from all simulated pairs.
+type loadtestStats struct {
+	mu        sync.Mutex
+	sent      int
+	received  int
+	errors    int
+	games     int
+	latencies []time.Duration
+}
+
+func (s *loadtestStats) record(f func(s *loadtestStats)) {
+	s.mu.Lock()
+	f(s)
+	s.mu.Unlock()
+}
+
+// randomMove applies a random legal placement or move for player to b.
+// It returns false when the player has nothing legal to play.
+func randomMove(b *Board, player int, rng *rand.Rand) bool {
+	options := legalMoves(b, player)
+	if len(options) == 0 {
+		return false
+	}
+	applyToBoard(b, options[rng.Intn(len(options))], player)
+	return true
+}
+
+// runLoadtestPair plays games between two simulated players on their own topic.
+func runLoadtestPair(runID string, pair, games int, pace, moveTimeout time.Duration, stats *loadtestStats) {
+	topic := fmt.Sprintf("gobblet/loadtest/%s/%d", runID, pair)
+	rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(pair)))
+
+	var clients [2]mqtt.Client
+	moves := [2]chan loadtestMessage{make(chan loadtestMessage, 4), make(chan loadtestMessage, 4)}
+	for i := range clients {
+		player := i + 1
+		clients[i] = newMQTTClient(fmt.Sprintf("GobbletLoad-%s-%d-%d", runID, pair, player))
+		if token := clients[i].Connect(); token.Wait() && token.Error() != nil {
+			stats.record(func(s *loadtestStats) { s.errors++ })
+			return
+		}
+		defer clients[i].Disconnect(250)
+
+		inbox := moves[i]
+		token := clients[i].Subscribe(topic, 1, unbatched(func(client mqtt.Client, msg mqtt.Message) {
+			var m loadtestMessage
+			if err := json.Unmarshal(msg.Payload(), &m); err != nil {
+				stats.record(func(s *loadtestStats) { s.errors++ })
+				return
+			}
+			// Only react to the opponent's moves, not our own echo
+			if m.State.PlayerTurn != player {
+				return
+			}
+			latency := time.Since(time.Unix(0, m.SentAt))
+			stats.record(func(s *loadtestStats) {
+				s.received++
+				s.latencies = append(s.latencies, latency)
+			})
+			inbox <- m
+		}))
+		if token.Wait() && token.Error() != nil {
+			stats.record(func(s *loadtestStats) { s.errors++ })
+			return
+		}
+	}
+
+	publish := func(player int, b Board, turn int) bool {
+		data, _ := json.Marshal(loadtestMessage{
+			State:  GameState{Board: b, PlayerTurn: turn},
+			SentAt: time.Now().UnixNano(),
+		})
+		token := clients[player-1].Publish(topic, 1, false, data)
+		token.Wait()
+		stats.record(func(s *loadtestStats) {
+			s.sent++
+			if token.Error() != nil {
+				s.errors++
+			}
+		})
+		return token.Error() == nil
+	}
+
+	for g := 0; g < games; g++ {
+		b := engine.NewBoard(engine.Junior.Rules().Size)
+		player := 1
+		for move := 0; move < loadtestMaxMoves; move++ {
+			time.Sleep(pace)
+			if !randomMove(&b, player, rng) || engine.CheckWin(b) != 0 {
+				break
+			}
+			if !publish(player, b, 3-player) {
+				break
+			}
+			select {
+			case m := <-moves[2-player]:
+				b = m.State.Board
+			case <-time.After(moveTimeout):
+				stats.record(func(s *loadtestStats) { s.errors++ })
+				move = loadtestMaxMoves
+			}
+			player = 3 - player
+		}
+		stats.record(func(s *loadtestStats) { s.games++ })
+	}
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+// runLoadtest spins up simulated player pairs against the configured broker
+// and reports throughput, error rate and latency percentiles.
+func runLoadtest(args []string) {
+	fs := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	pairs := fs.Int("pairs", 10, "number of simulated player pairs")
+	games := fs.Int("games", 3, "games played by each pair")
+	pace := fs.Duration("pace", 500*time.Millisecond, "think time before each move")
+	moveTimeout := fs.Duration("move-timeout", 10*time.Second, "how long to wait for an opponent move before counting an error")
+	fs.DurationVar(&batchWindow, "batch", 0, "collect publishes for this long and send them as one message (e.g. 50ms)")
+	fs.Parse(args)
+
+	runID := fmt.Sprintf("%d", time.Now().Unix())
+	stats := &loadtestStats{}
+	fmt.Printf("🚀 Starting load test %s: %d pairs × %d games\n", runID, *pairs, *games)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for p := 0; p < *pairs; p++ {
+		wg.Add(1)
+		go func(pair int) {
+			defer wg.Done()
+			runLoadtestPair(runID, pair, *games, *pace, *moveTimeout, stats)
+		}(p)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	sort.Slice(stats.latencies, func(i, j int) bool { return stats.latencies[i] < stats.latencies[j] })
+	errorRate := 0.0
+	if stats.sent > 0 {
+		errorRate = float64(stats.errors) / float64(stats.sent) * 100
+	}
+	fmt.Println("📊 Load test results")
+	fmt.Printf("  Duration:    %s\n", elapsed.Round(time.Millisecond))
+	fmt.Printf("  Games:       %d\n", stats.games)
+	fmt.Printf("  Messages:    %d sent, %d received\n", stats.sent, stats.received)
+	fmt.Printf("  Throughput:  %.1f msg/s\n", float64(stats.received)/elapsed.Seconds())
+	fmt.Printf("  Errors:      %d (%.2f%%)\n", stats.errors, errorRate)
+	fmt.Printf("  Latency:     p50 %s, p90 %s, p99 %s\n",
+		percentile(stats.latencies, 0.50).Round(time.Millisecond),
+		percentile(stats.latencies, 0.90).Round(time.Millisecond),
+		percentile(stats.latencies, 0.99).Round(time.Millisecond))
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"goblets/config"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	lobbyPrefix = "gobblet/lobby/games/"
+	lobbyMaxAge = time.Hour // older adverts are left out of the list
+)
+
+// LobbyEntry is a game's retained advert on the lobby while it has open
+// seats.
+type LobbyEntry struct {
+	GameID    string
+	Creator   string `json:",omitempty"`
+	Preset    string
+	Variant   string
+	Title     string `json:",omitempty"`
+	Rated     bool   `json:",omitempty"`
+	OpenSeats []int
+	Time      time.Time
+}
+
+// lobbyMu keeps this terminal's updates of the game's advert in order.
+var lobbyMu sync.Mutex
+
+func lobbyTopic(id string) string {
+	return lobbyPrefix + id
+}
+
+// advertiseGame puts the new game on the lobby with both seats open.
+func advertiseGame() {
+	e := LobbyEntry{
+		GameID:    gameID,
+		Creator:   publicName(config.Conf.PlayerName),
+		Preset:    gameMeta.Preset,
+		Variant:   variant.String(),
+		Title:     gameMeta.Title,
+		Rated:     gameMeta.Rated,
+		OpenSeats: []int{1, 2},
+		Time:      time.Now().UTC(),
+	}
+	data, _ := json.Marshal(e)
+	if token := mqttClient.Publish(lobbyTopic(gameID), 1, true, data); token.Wait() && token.Error() != nil {
+		fmt.Println("⚠ Could not advertise the game on the lobby:", token.Error())
+	}
+}
+
+// openSeats lists the player seats nobody holds in the seating.
+func openSeats(doc Seats) []int {
+	var open []int
+	for _, seat := range []int{1, 2} {
+		if _, taken := doc[seat]; !taken {
+			open = append(open, seat)
+		}
+	}
+	return open
+}
+
+// syncLobbySeats rebuilds the open seats of the game's advert from the
+// seating document, rather than from the advert itself, and takes the
+// advert down once no seat is open. Seated terminals run it whenever the
+// seating changes, so the advert ends up matching the seating whichever
+// update the broker keeps last.
+func syncLobbySeats() {
+	lobbyMu.Lock()
+	defer lobbyMu.Unlock()
+	entries := fetchLobby(lobbyTopic(gameID), time.Second)
+	if len(entries) == 0 {
+		return // not advertised, or already taken down
+	}
+	e := entries[0]
+	e.OpenSeats = openSeats(currentSeats())
+	data, _ := json.Marshal(e)
+	if len(e.OpenSeats) == 0 {
+		data = []byte{}
+	}
+	mqttClient.Publish(lobbyTopic(gameID), 1, true, data).Wait()
+}
+
+// clearLobby takes the game's advert down.
+func clearLobby() {
+	if mqttClient == nil { // replaying a trace
+		return
+	}
+	lobbyMu.Lock()
+	defer lobbyMu.Unlock()
+	mqttClient.Publish(lobbyTopic(gameID), 1, true, []byte{}).Wait()
+}
+
+// fetchLobby collects the retained adverts on filter, waiting up to wait
+// for them to arrive.
+func fetchLobby(filter string, wait time.Duration) []LobbyEntry {
+	var entriesMu sync.Mutex
+	var entries []LobbyEntry
+	token := mqttClient.Subscribe(filter, 1, func(client mqtt.Client, msg mqtt.Message) {
+		var e LobbyEntry
+		if json.Unmarshal(msg.Payload(), &e) != nil || e.GameID == "" {
+			return
+		}
+		entriesMu.Lock()
+		entries = append(entries, e)
+		entriesMu.Unlock()
+	})
+	if token.Wait() && token.Error() != nil {
+		fmt.Println("⚠ Could not read the lobby:", token.Error())
+		return nil
+	}
+	time.Sleep(wait)
+	mqttClient.Unsubscribe(filter).Wait()
+	entriesMu.Lock()
+	defer entriesMu.Unlock()
+	return entries
+}
+
+// runList prints the games on the lobby that have an open seat.
+func runList(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	wait := fs.Duration("wait", 2*time.Second, "how long to collect adverts")
+	fs.Parse(args)
+	connectMQTT()
+
+	var open []LobbyEntry
+	for _, e := range fetchLobby(lobbyPrefix+"+", *wait) {
+		if len(e.OpenSeats) > 0 && time.Since(e.Time) < lobbyMaxAge {
+			open = append(open, e)
+		}
+	}
+	if len(open) == 0 {
+		fmt.Println("📭 No open games. Start one by pressing Enter at the Game ID prompt.")
+		os.Exit(0)
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].Time.After(open[j].Time) })
+	fmt.Printf("🎲 %d open game(s):\n", len(open))
+	fmt.Printf("  %-8s %-14s %-10s %-8s %-6s %-8s %s\n", "Game", "Creator", "Preset", "Variant", "Seats", "Waiting", "Title")
+	for _, e := range open {
+		seats := make([]string, len(e.OpenSeats))
+		for i, s := range e.OpenSeats {
+			seats[i] = fmt.Sprint(s)
+		}
+		label := e.Title
+		if e.Rated {
+			label = strings.TrimSpace(label + " (rated)")
+		}
+		waiting := fmt.Sprintf("%d min", int(time.Since(e.Time).Minutes()))
+		fmt.Printf("  %-8s %-14s %-10s %-8s %-6s %-8s %s\n", e.GameID, cmp.Or(e.Creator, "-"), e.Preset, e.Variant,
+			strings.Join(seats, ","), waiting, label)
+	}
+	fmt.Println("Join one with --game-id <ID>, or enter the ID at the prompt.")
+}
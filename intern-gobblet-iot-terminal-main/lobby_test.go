@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSyncLobbySeatsFollowsSeating(t *testing.T) {
+	b := startTestBroker(t)
+	c := connectV5(t, b)
+	saved, savedID := mqttClient, gameID
+	t.Cleanup(func() {
+		mqttClient, gameID = saved, savedID
+		seatsMu.Lock()
+		seatsDoc = Seats{}
+		seatsMu.Unlock()
+	})
+	mqttClient, gameID = c, "lobby-game"
+
+	// An advert a racing terminal left stale, with seat 1 open again
+	data, _ := json.Marshal(LobbyEntry{GameID: gameID, Creator: "alice", Preset: "casual", OpenSeats: []int{1}, Time: time.Now().UTC()})
+	c.Publish(lobbyTopic(gameID), 1, true, data).Wait()
+
+	setSeats := func(doc Seats) {
+		seatsMu.Lock()
+		seatsDoc = doc
+		seatsMu.Unlock()
+	}
+	advert := func() *LobbyEntry {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		p := b.retained[lobbyTopic(gameID)]
+		if p == nil {
+			return nil
+		}
+		var e LobbyEntry
+		json.Unmarshal(p.Payload, &e)
+		return &e
+	}
+
+	setSeats(Seats{1: {Device: "dev-a"}})
+	syncLobbySeats()
+	if e := advert(); e == nil || !slices.Equal(e.OpenSeats, []int{2}) || e.Creator != "alice" {
+		t.Fatalf("advert %+v, want alice's game with seat 2 open", e)
+	}
+
+	setSeats(Seats{1: {Device: "dev-a"}, 2: {Device: "dev-b"}})
+	syncLobbySeats()
+	if e := advert(); e != nil {
+		t.Errorf("advert %+v still up with both seats taken", e)
+	}
+
+	setSeats(Seats{})
+	syncLobbySeats()
+	if e := advert(); e != nil {
+		t.Errorf("a taken-down advert came back: %+v", e)
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"goblets/config"
+	"os"
+	"testing"
+)
+
+// TestMain runs the tests on the fixture in testdata instead of the
+// player's own config.yaml.
+func TestMain(m *testing.M) {
+	config.Load("testdata")
+	os.Exit(m.Run())
+}
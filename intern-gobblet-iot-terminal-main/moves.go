@@ -0,0 +1,38 @@
+package main
+
+import "goblets/engine"
+
+// move converts a typed action to an engine move.
+func (a Action) move() engine.Move {
+	if a.Kind == actionPlace {
+		return engine.Place(a.Args[0], a.Args[1], a.Args[2])
+	}
+	return engine.Shift(a.Args[0], a.Args[1], a.Args[2], a.Args[3])
+}
+
+// actionOf converts an engine move to the typed-input form.
+func actionOf(m engine.Move) Action {
+	if m.IsPlacement() {
+		return Action{Kind: actionPlace, Args: []int{m.Row, m.Col, m.Size}}
+	}
+	return Action{Kind: actionMove, Args: []int{m.FromRow, m.FromCol, m.Row, m.Col}}
+}
+
+// legalMoves lists every placement and move player can make on b, in the
+// same shape as typed input: "1 x y size" and "2 x1 y1 x2 y2".
+func legalMoves(b *Board, player int) []Action {
+	moves := engine.LegalMoves(position(*b, player), player)
+	actions := make([]Action, len(moves))
+	for i, m := range moves {
+		actions[i] = actionOf(m)
+	}
+	return actions
+}
+
+// applyToBoard plays a legal action for player on b without any I/O.
+func applyToBoard(b *Board, a Action, player int) {
+	next, err := position(*b, player).Apply(a.move())
+	if err == nil {
+		*b = next.Board
+	}
+}
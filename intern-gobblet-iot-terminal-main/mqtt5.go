@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"goblets/config"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// v5Client speaks MQTT 5 to the broker behind the same mqtt.Client interface
+// the rest of the terminal uses. Every publish carries the protocol and
+// client versions as user properties, retained game messages expire after
+// mqtt.state_expiry hours so abandoned games don't come back weeks later,
+// and requests can name a response topic for the reply.
+type v5Client struct {
+	cfg       autopaho.ClientConfig
+	cm        *autopaho.ConnectionManager
+	connected atomic.Bool
+
+	mu     sync.Mutex
+	routes map[string]v5Route // by topic filter
+}
+
+type v5Route struct {
+	qos       byte
+	handler   mqtt.MessageHandler
+	subscribe bool // false for AddRoute handlers
+}
+
+// User properties on every MQTT 5 publish.
+const (
+	propProtocol = "gobblet-protocol"
+	propClient   = "gobblet-client"
+)
+
+const v5Timeout = 30 * time.Second
+
+func newV5Client(brokerURL, clientID string, tlsConfig *tls.Config, willTopic string, will []byte) (*v5Client, error) {
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, err
+	}
+	c := &v5Client{routes: map[string]v5Route{}}
+	c.cfg = autopaho.ClientConfig{
+		ServerUrls:                    []*url.URL{u},
+		TlsCfg:                        tlsConfig,
+		KeepAlive:                     30,
+		CleanStartOnInitialConnection: true,
+		ConnectRetryDelay:             5 * time.Second,
+		OnConnectionUp: func(cm *autopaho.ConnectionManager, _ *paho.Connack) {
+			c.connected.Store(true)
+			go c.resubscribe()
+		},
+		OnConnectionDown: func() bool {
+			c.connected.Store(false)
+			return true // keep reconnecting
+		},
+		ClientConfig: paho.ClientConfig{
+			ClientID:          clientID,
+			OnPublishReceived: []func(paho.PublishReceived) (bool, error){c.dispatch},
+		},
+	}
+	if will != nil {
+		c.cfg.WillMessage = &paho.WillMessage{Topic: willTopic, Payload: will, QoS: 1}
+	}
+	return c, nil
+}
+
+// v5Token completes when its operation does.
+type v5Token struct {
+	done chan struct{}
+	err  error
+}
+
+func newV5Token(f func() error) *v5Token {
+	t := &v5Token{done: make(chan struct{})}
+	go func() {
+		t.err = f()
+		close(t.done)
+	}()
+	return t
+}
+
+func (t *v5Token) Wait() bool { <-t.done; return true }
+
+func (t *v5Token) WaitTimeout(d time.Duration) bool {
+	select {
+	case <-t.done:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func (t *v5Token) Done() <-chan struct{} { return t.done }
+
+func (t *v5Token) Error() error {
+	select {
+	case <-t.done:
+		return t.err
+	default:
+		return nil
+	}
+}
+
+// v5Message presents an MQTT 5 publish as an mqtt.Message.
+type v5Message struct {
+	p *paho.Publish
+}
+
+func (m v5Message) Duplicate() bool   { return m.p.Duplicate() }
+func (m v5Message) Qos() byte         { return m.p.QoS }
+func (m v5Message) Retained() bool    { return m.p.Retain }
+func (m v5Message) Topic() string     { return m.p.Topic }
+func (m v5Message) MessageID() uint16 { return m.p.PacketID }
+func (m v5Message) Payload() []byte   { return m.p.Payload }
+func (m v5Message) Ack()              {}
+
+// ResponseTopic is where the sender wants the reply, if anywhere.
+func (m v5Message) ResponseTopic() string {
+	if m.p.Properties == nil {
+		return ""
+	}
+	return m.p.Properties.ResponseTopic
+}
+
+// withTimeout runs f with a context that gives up after v5Timeout, once
+// the connection is up.
+func (c *v5Client) withTimeout(f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), v5Timeout)
+	defer cancel()
+	if c.cm == nil {
+		return errors.New("not connected")
+	}
+	if err := c.cm.AwaitConnection(ctx); err != nil {
+		return err
+	}
+	return f(ctx)
+}
+
+func (c *v5Client) IsConnected() bool      { return c.connected.Load() }
+func (c *v5Client) IsConnectionOpen() bool { return c.connected.Load() }
+
+func (c *v5Client) Connect() mqtt.Token {
+	return newV5Token(func() error {
+		cm, err := autopaho.NewConnection(context.Background(), c.cfg)
+		if err != nil {
+			return err
+		}
+		c.cm = cm
+		ctx, cancel := context.WithTimeout(context.Background(), v5Timeout)
+		defer cancel()
+		return cm.AwaitConnection(ctx)
+	})
+}
+
+func (c *v5Client) Disconnect(quiesce uint) {
+	if c.cm == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(quiesce)*time.Millisecond+time.Second)
+	defer cancel()
+	c.cm.Disconnect(ctx)
+}
+
+func (c *v5Client) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	return c.publish(&paho.Publish{Topic: topic, QoS: qos, Retain: retained, Payload: payloadBytes(payload), Properties: &paho.PublishProperties{}})
+}
+
+// PublishTyped publishes payload marked with its MIME content type.
+func (c *v5Client) PublishTyped(topic string, qos byte, retained bool, payload []byte, contentType string) mqtt.Token {
+	return c.publish(&paho.Publish{Topic: topic, QoS: qos, Retain: retained, Payload: payload, Properties: &paho.PublishProperties{ContentType: contentType}})
+}
+
+// Request publishes payload asking for the reply on responseTopic.
+func (c *v5Client) Request(topic string, qos byte, payload []byte, responseTopic string) mqtt.Token {
+	return c.publish(&paho.Publish{Topic: topic, QoS: qos, Payload: payload, Properties: &paho.PublishProperties{ResponseTopic: responseTopic}})
+}
+
+func (c *v5Client) publish(p *paho.Publish) mqtt.Token {
+	p.Properties.User.Add(propProtocol, strconv.Itoa(protocolVersion)).Add(propClient, clientVersion)
+	if hours := config.Conf.MQTT.StateExpiry; hours > 0 && p.Retain && len(p.Payload) > 0 && strings.HasPrefix(p.Topic, "gobblet/game/") {
+		expiry := uint32(hours * 3600)
+		p.Properties.MessageExpiry = &expiry
+	}
+	return newV5Token(func() error {
+		return c.withTimeout(func(ctx context.Context) error {
+			_, err := c.cm.Publish(ctx, p)
+			return err
+		})
+	})
+}
+
+func payloadBytes(payload interface{}) []byte {
+	switch p := payload.(type) {
+	case []byte:
+		return p
+	case string:
+		return []byte(p)
+	case bytes.Buffer:
+		return p.Bytes()
+	}
+	return nil
+}
+
+func (c *v5Client) Subscribe(topic string, qos byte, callback mqtt.MessageHandler) mqtt.Token {
+	return c.SubscribeMultiple(map[string]byte{topic: qos}, callback)
+}
+
+func (c *v5Client) SubscribeMultiple(filters map[string]byte, callback mqtt.MessageHandler) mqtt.Token {
+	sub := &paho.Subscribe{}
+	c.mu.Lock()
+	for topic, qos := range filters {
+		c.routes[topic] = v5Route{qos: qos, handler: callback, subscribe: true}
+		sub.Subscriptions = append(sub.Subscriptions, paho.SubscribeOptions{Topic: topic, QoS: qos})
+	}
+	c.mu.Unlock()
+	return newV5Token(func() error {
+		return c.withTimeout(func(ctx context.Context) error {
+			_, err := c.cm.Subscribe(ctx, sub)
+			return err
+		})
+	})
+}
+
+func (c *v5Client) Unsubscribe(topics ...string) mqtt.Token {
+	c.mu.Lock()
+	for _, topic := range topics {
+		delete(c.routes, topic)
+	}
+	c.mu.Unlock()
+	return newV5Token(func() error {
+		return c.withTimeout(func(ctx context.Context) error {
+			_, err := c.cm.Unsubscribe(ctx, &paho.Unsubscribe{Topics: topics})
+			return err
+		})
+	})
+}
+
+func (c *v5Client) AddRoute(topic string, callback mqtt.MessageHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := c.routes[topic] // keeps the subscription, if any
+	r.handler = callback
+	c.routes[topic] = r
+}
+
+func (c *v5Client) OptionsReader() mqtt.ClientOptionsReader {
+	return mqtt.NewOptionsReader(mqtt.NewClientOptions().AddBroker(c.cfg.ServerUrls[0].String()).SetClientID(c.cfg.ClientID))
+}
+
+// resubscribe restores the subscriptions after a reconnection, since the
+// session starts clean.
+func (c *v5Client) resubscribe() {
+	sub := &paho.Subscribe{}
+	c.mu.Lock()
+	for topic, r := range c.routes {
+		if r.subscribe {
+			sub.Subscriptions = append(sub.Subscriptions, paho.SubscribeOptions{Topic: topic, QoS: r.qos})
+		}
+	}
+	c.mu.Unlock()
+	if len(sub.Subscriptions) == 0 {
+		return
+	}
+	err := c.withTimeout(func(ctx context.Context) error {
+		_, err := c.cm.Subscribe(ctx, sub)
+		return err
+	})
+	if err != nil {
+		fmt.Println("⚠ Could not restore subscriptions after reconnecting:", err)
+	}
+}
+
+// dispatch hands a received publish to every route whose filter matches.
+func (c *v5Client) dispatch(pr paho.PublishReceived) (bool, error) {
+	if pr.Packet.Properties != nil {
+		checkPeerProtocol(pr.Packet.Properties.User.Get(propProtocol))
+	}
+	c.mu.Lock()
+	var handlers []mqtt.MessageHandler
+	for filter, r := range c.routes {
+		if topicMatches(filter, pr.Packet.Topic) {
+			handlers = append(handlers, r.handler)
+		}
+	}
+	c.mu.Unlock()
+	for _, h := range handlers {
+		h(c, v5Message{pr.Packet})
+	}
+	return len(handlers) > 0, nil
+}
+
+// topicMatches reports whether topic matches an MQTT topic filter with
+// + and # wildcards.
+func topicMatches(filter, topic string) bool {
+	f, t := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, level := range f {
+		switch {
+		case level == "#":
+			return true
+		case i >= len(t):
+			return false
+		case level != "+" && level != t[i]:
+			return false
+		}
+	}
+	return len(f) == len(t)
+}
+
+var (
+	peerProtocolWarned sync.Once
+	legacyProtocolSeen atomic.Bool // a terminal speaks a protocol before seqProtocol
+)
+
+// checkPeerProtocol warns once when another terminal speaks a newer
+// protocol than this one, and notes terminals that don't number states.
+func checkPeerProtocol(version string) {
+	v, err := strconv.Atoi(version)
+	if err == nil && v < seqProtocol {
+		legacyProtocolSeen.Store(true)
+	}
+	if err != nil || v <= protocolVersion {
+		return
+	}
+	peerProtocolWarned.Do(func() {
+		fmt.Printf("\n⚠ Another terminal speaks protocol %d, this one %d. Update this terminal to play reliably with it.\n", v, protocolVersion)
+	})
+}
+
+// publishRequest publishes payload asking for the reply on responseTopic
+// when the transport is MQTT 5; otherwise it's a plain publish.
+func publishRequest(topic string, qos byte, payload []byte, responseTopic string) mqtt.Token {
+	if c, ok := mqttClient.(*v5Client); ok {
+		return c.Request(topic, qos, payload, responseTopic)
+	}
+	return mqttClient.Publish(topic, qos, false, payload)
+}
+
+// replyTopic is where a request asked for its reply, or fallback.
+func replyTopic(msg mqtt.Message, fallback string) string {
+	if m, ok := msg.(v5Message); ok && m.ResponseTopic() != "" {
+		return m.ResponseTopic()
+	}
+	return fallback
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"goblets/config"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.golang/packets"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// testBroker is just enough of an MQTT 5 broker for the adapter: it
+// acknowledges what a client sends, keeps every publish and the retained
+// ones, and delivers each back to the client when it matches a
+// subscription.
+type testBroker struct {
+	addr string
+
+	mu        sync.Mutex
+	published []*packets.Publish
+	retained  map[string]*packets.Publish
+}
+
+func startTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no local listener:", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	b := &testBroker{addr: ln.Addr().String(), retained: map[string]*packets.Publish{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *testBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	var filters []string
+	for {
+		cp, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		switch p := cp.Content.(type) {
+		case *packets.Connect:
+			(&packets.Connack{Properties: &packets.Properties{}}).WriteTo(conn)
+		case *packets.Subscribe:
+			reasons := make([]byte, len(p.Subscriptions))
+			for i, s := range p.Subscriptions {
+				filters = append(filters, s.Topic)
+				reasons[i] = s.QoS
+			}
+			(&packets.Suback{PacketID: p.PacketID, Reasons: reasons, Properties: &packets.Properties{}}).WriteTo(conn)
+			b.mu.Lock()
+			for _, r := range b.retained {
+				for _, s := range p.Subscriptions {
+					if topicMatches(s.Topic, r.Topic) {
+						out := *r
+						out.QoS, out.PacketID = 0, 0
+						out.WriteTo(conn)
+						break
+					}
+				}
+			}
+			b.mu.Unlock()
+		case *packets.Unsubscribe:
+			(&packets.Unsuback{PacketID: p.PacketID, Reasons: make([]byte, len(p.Topics)), Properties: &packets.Properties{}}).WriteTo(conn)
+		case *packets.Publish:
+			b.mu.Lock()
+			b.published = append(b.published, p)
+			if p.Retain && len(p.Payload) == 0 {
+				delete(b.retained, p.Topic)
+			} else if p.Retain {
+				b.retained[p.Topic] = p
+			}
+			b.mu.Unlock()
+			if p.QoS > 0 {
+				(&packets.Puback{PacketID: p.PacketID, Properties: &packets.Properties{}}).WriteTo(conn)
+			}
+			for _, f := range filters {
+				if topicMatches(f, p.Topic) {
+					out := *p
+					out.QoS, out.PacketID, out.Retain = 0, 0, false
+					out.WriteTo(conn)
+					break
+				}
+			}
+		case *packets.Pingreq:
+			(&packets.Pingresp{}).WriteTo(conn)
+		case *packets.Disconnect:
+			return
+		}
+	}
+}
+
+// lastPublish returns what the broker received on topic last.
+func (b *testBroker) lastPublish(topic string) *packets.Publish {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := len(b.published) - 1; i >= 0; i-- {
+		if b.published[i].Topic == topic {
+			return b.published[i]
+		}
+	}
+	return nil
+}
+
+func connectV5(t *testing.T, b *testBroker) *v5Client {
+	t.Helper()
+	c, err := newV5Client("mqtt://"+b.addr, "test-client", nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := c.Connect(); !token.WaitTimeout(5*time.Second) || token.Error() != nil {
+		t.Fatalf("connect: %v", token.Error())
+	}
+	t.Cleanup(func() { c.Disconnect(0) })
+	return c
+}
+
+func receive(t *testing.T, messages <-chan mqtt.Message) mqtt.Message {
+	t.Helper()
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message delivered")
+		return nil
+	}
+}
+
+func TestV5ClientPublishSubscribe(t *testing.T) {
+	saved := config.Conf.MQTT.StateExpiry
+	t.Cleanup(func() { config.Conf.MQTT.StateExpiry = saved })
+	config.Conf.MQTT.StateExpiry = 2
+
+	b := startTestBroker(t)
+	c := connectV5(t, b)
+	messages := make(chan mqtt.Message, 4)
+	if token := c.Subscribe("gobblet/game/+/state", 1, func(_ mqtt.Client, msg mqtt.Message) { messages <- msg }); !token.WaitTimeout(5*time.Second) || token.Error() != nil {
+		t.Fatalf("subscribe: %v", token.Error())
+	}
+
+	if token := c.Publish("gobblet/game/g1/state", 1, true, []byte(`{"Moves":3}`)); !token.WaitTimeout(5*time.Second) || token.Error() != nil {
+		t.Fatalf("publish: %v", token.Error())
+	}
+	msg := receive(t, messages)
+	if msg.Topic() != "gobblet/game/g1/state" || string(msg.Payload()) != `{"Moves":3}` {
+		t.Errorf("delivered %s %q", msg.Topic(), msg.Payload())
+	}
+
+	p := b.lastPublish("gobblet/game/g1/state")
+	switch {
+	case p == nil:
+		t.Fatal("the broker got no publish")
+	case !p.Retain || p.QoS != 1:
+		t.Errorf("published with retain %v, QoS %d", p.Retain, p.QoS)
+	case p.Properties.MessageExpiry == nil || *p.Properties.MessageExpiry != 2*3600:
+		t.Errorf("retained game state expires after %v, want 7200 seconds", p.Properties.MessageExpiry)
+	}
+	props := map[string]string{}
+	for _, u := range p.Properties.User {
+		props[u.Key] = u.Value
+	}
+	if props[propProtocol] != strconv.Itoa(protocolVersion) || props[propClient] != clientVersion {
+		t.Errorf("user properties %v", props)
+	}
+
+	c.Publish("gobblet/lobby/games", 1, true, []byte("x")).Wait()
+	if p := b.lastPublish("gobblet/lobby/games"); p == nil || p.Properties.MessageExpiry != nil {
+		t.Error("a message outside gobblet/game/ got an expiry")
+	}
+}
+
+func TestV5ClientResponseTopic(t *testing.T) {
+	b := startTestBroker(t)
+	c := connectV5(t, b)
+	messages := make(chan mqtt.Message, 1)
+	c.Subscribe("gobblet/game/g1/ping", 1, func(_ mqtt.Client, msg mqtt.Message) { messages <- msg }).Wait()
+
+	c.Request("gobblet/game/g1/ping", 1, []byte("ping"), "gobblet/game/g1/ping/me").Wait()
+	if got := replyTopic(receive(t, messages), "fallback"); got != "gobblet/game/g1/ping/me" {
+		t.Errorf("reply topic %q", got)
+	}
+}
+
+func TestV5ClientAddRouteKeepsSubscription(t *testing.T) {
+	b := startTestBroker(t)
+	c := connectV5(t, b)
+	first, second := make(chan mqtt.Message, 1), make(chan mqtt.Message, 1)
+	c.Subscribe("gobblet/game/g1/moves", 1, func(_ mqtt.Client, msg mqtt.Message) { first <- msg }).Wait()
+	c.AddRoute("gobblet/game/g1/moves", func(_ mqtt.Client, msg mqtt.Message) { second <- msg })
+	if !c.routes["gobblet/game/g1/moves"].subscribe {
+		t.Error("AddRoute dropped the subscription")
+	}
+
+	c.Publish("gobblet/game/g1/moves", 1, false, []byte("m")).Wait()
+	receive(t, second)
+	select {
+	case <-first:
+		t.Error("the replaced handler still got the message")
+	default:
+	}
+}
+
+func TestV5ClientNotConnected(t *testing.T) {
+	c, err := newV5Client("mqtt://127.0.0.1:1", "test-client", nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := c.Publish("gobblet/game/g1/state", 1, true, []byte("x")); !token.WaitTimeout(time.Second) || token.Error() == nil {
+		t.Error("publishing before connecting didn't fail")
+	}
+}
+
+func TestMigrateLegacyState(t *testing.T) {
+	b := startTestBroker(t)
+	c := connectV5(t, b)
+	saved, savedID := mqttClient, gameID
+	t.Cleanup(func() { mqttClient, gameID = saved, savedID })
+	mqttClient, gameID = c, "old-game"
+
+	data := encodeState(sampleState())
+	c.Publish(legacyGameTopic(gameID), 1, true, data).Wait()
+	if !migrateLegacyState() {
+		t.Fatal("the game on the bare topic wasn't found")
+	}
+	b.mu.Lock()
+	moved, left := b.retained[stateTopic()], b.retained[legacyGameTopic(gameID)]
+	b.mu.Unlock()
+	if moved == nil || string(moved.Payload) != string(data) {
+		t.Error("the game wasn't republished on /state")
+	}
+	if left != nil {
+		t.Error("the bare topic still holds the game")
+	}
+
+	gameID = "new-game"
+	if migrateLegacyState() {
+		t.Error("migrated a game that was never saved")
+	}
+}
+
+func TestTopicMatches(t *testing.T) {
+	for _, tc := range []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"gobblet/game/+/state", "gobblet/game/g1/state", true},
+		{"gobblet/game/+/state", "gobblet/game/g1/moves", false},
+		{"gobblet/game/+/state", "gobblet/game/g1/state/cbor", false},
+		{"gobblet/game/#", "gobblet/game/g1/claims/2", true},
+		{"gobblet/game/g1/claims/+", "gobblet/game/g1/claims", false},
+		{"gobblet/lobby/games", "gobblet/lobby/games", true},
+	} {
+		if got := topicMatches(tc.filter, tc.topic); got != tc.want {
+			t.Errorf("topicMatches(%q, %q) = %v", tc.filter, tc.topic, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package notation reads and writes moves in a short algebraic form:
+// "P b2 L" places a large piece on b2 and "M a1 c3" moves the visible piece
+// on a1 to c3. Columns are letters from a on the left, rows are numbers
+// from 1 at the top, and sizes are S, M, L and, in the 4x4 variant, X.
+package notation
+
+import (
+	"fmt"
+	"goblets/engine"
+	"strings"
+)
+
+const sizes = "SMLX"
+
+// Square names the cell at row, col, e.g. "b2" for row 1, column 1.
+func Square(row, col int) string {
+	return fmt.Sprintf("%c%d", 'a'+col, row+1)
+}
+
+// ParseSquare reads a cell name such as "b2". Whether the square is on the
+// board is left to the engine.
+func ParseSquare(s string) (row, col int, err error) {
+	s = strings.ToLower(s)
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'z' || s[1] < '1' || s[1] > '9' {
+		return 0, 0, fmt.Errorf("invalid square %q, use a letter and a number like b2", s)
+	}
+	return int(s[1] - '1'), int(s[0] - 'a'), nil
+}
+
+// SizeName is the letter for a piece size, or "?" for an unknown size.
+func SizeName(size int) string {
+	if size < 1 || size > len(sizes) {
+		return "?"
+	}
+	return sizes[size-1 : size]
+}
+
+// Format writes m in algebraic notation.
+func Format(m engine.Move) string {
+	if m.IsPlacement() {
+		return "P " + Square(m.Row, m.Col) + " " + SizeName(m.Size)
+	}
+	return "M " + Square(m.FromRow, m.FromCol) + " " + Square(m.Row, m.Col)
+}
+
+// Parse reads a move written by Format. Letters may be in either case.
+func Parse(s string) (engine.Move, error) {
+	fields := strings.Fields(strings.ToUpper(s))
+	if len(fields) == 0 {
+		return engine.Move{}, fmt.Errorf("empty move")
+	}
+	switch fields[0] {
+	case "P":
+		if len(fields) != 3 {
+			return engine.Move{}, fmt.Errorf("invalid placement %q, use 'P b2 L'", s)
+		}
+		row, col, err := ParseSquare(fields[1])
+		if err != nil {
+			return engine.Move{}, err
+		}
+		size := strings.Index(sizes, fields[2]) + 1
+		if len(fields[2]) != 1 || size == 0 {
+			return engine.Move{}, fmt.Errorf("invalid size %q, use S, M, L or X", fields[2])
+		}
+		return engine.Place(row, col, size), nil
+	case "M":
+		if len(fields) != 3 {
+			return engine.Move{}, fmt.Errorf("invalid move %q, use 'M a1 c3'", s)
+		}
+		fromRow, fromCol, err := ParseSquare(fields[1])
+		if err != nil {
+			return engine.Move{}, err
+		}
+		row, col, err := ParseSquare(fields[2])
+		if err != nil {
+			return engine.Move{}, err
+		}
+		return engine.Shift(fromRow, fromCol, row, col), nil
+	}
+	return engine.Move{}, fmt.Errorf("invalid move %q, start with P to place or M to move", s)
+}
+
+// Delta decodes the move of a move delta: a "place" of a piece of size on
+// to, or a "move" of the visible piece on from to to.
+func Delta(kind, from, to string, size int) (engine.Move, error) {
+	row, col, err := ParseSquare(to)
+	if err != nil {
+		return engine.Move{}, err
+	}
+	switch kind {
+	case "place":
+		return engine.Place(row, col, size), nil
+	case "move":
+		fromRow, fromCol, err := ParseSquare(from)
+		if err != nil {
+			return engine.Move{}, err
+		}
+		return engine.Shift(fromRow, fromCol, row, col), nil
+	}
+	return engine.Move{}, fmt.Errorf("unknown move type %q", kind)
+}
+
+// IsAlgebraic reports whether s looks like algebraic notation rather than
+// the numeric "1 x y size" form.
+func IsAlgebraic(s string) bool {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	return strings.HasPrefix(s, "P") || strings.HasPrefix(s, "M")
+}
@@ -0,0 +1,117 @@
+package notation
+
+import (
+	"goblets/engine"
+	"strings"
+	"testing"
+)
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		move engine.Move
+		text string
+	}{
+		{engine.Place(1, 1, 3), "P b2 L"},
+		{engine.Place(0, 0, 1), "P a1 S"},
+		{engine.Place(3, 2, 4), "P c4 X"},
+		{engine.Shift(0, 0, 2, 2), "M a1 c3"},
+		{engine.Shift(8, 8, 0, 8), "M i9 i1"},
+	} {
+		if got := Format(tc.move); got != tc.text {
+			t.Errorf("Format(%+v) = %q, want %q", tc.move, got, tc.text)
+		}
+		got, err := Parse(tc.text)
+		if err != nil || got != tc.move {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", tc.text, got, err, tc.move)
+		}
+	}
+}
+
+func TestParseIgnoresCaseAndSpacing(t *testing.T) {
+	for _, text := range []string{"p B2 l", "  P   b2\tL  "} {
+		if got, err := Parse(text); err != nil || got != engine.Place(1, 1, 3) {
+			t.Errorf("Parse(%q) = %+v, %v", text, got, err)
+		}
+	}
+}
+
+func TestParseRejectsInvalidMoves(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"Q b2 L",
+		"P b2",
+		"P b2 L extra",
+		"P b2 Z",
+		"P b2 SM",
+		"P 2b L",
+		"P b0 L",
+		"M a1",
+		"M a1 c",
+		"M a1 c3 d4",
+	} {
+		if m, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", text, m)
+		}
+	}
+}
+
+func TestParseSquare(t *testing.T) {
+	for _, tc := range []struct {
+		text     string
+		row, col int
+	}{
+		{"a1", 0, 0},
+		{"b2", 1, 1},
+		{"C3", 2, 2},
+		{"i9", 8, 8},
+	} {
+		row, col, err := ParseSquare(tc.text)
+		if err != nil || row != tc.row || col != tc.col {
+			t.Errorf("ParseSquare(%q) = %d, %d, %v", tc.text, row, col, err)
+		}
+		if got := Square(tc.row, tc.col); got != strings.ToLower(tc.text) {
+			t.Errorf("Square(%d, %d) = %q", tc.row, tc.col, got)
+		}
+	}
+	for _, text := range []string{"", "a", "a10", "1a", "a0", "?1", "ab"} {
+		if _, _, err := ParseSquare(text); err == nil {
+			t.Errorf("ParseSquare(%q) succeeded", text)
+		}
+	}
+}
+
+func TestSizeName(t *testing.T) {
+	for size, want := range map[int]string{0: "?", 1: "S", 2: "M", 3: "L", 4: "X", 5: "?"} {
+		if got := SizeName(size); got != want {
+			t.Errorf("SizeName(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestIsAlgebraic(t *testing.T) {
+	for text, want := range map[string]bool{"P b2 L": true, " m a1 c3": true, "1 0 0 3": false, "2 0 0 1 1": false, "": false} {
+		if got := IsAlgebraic(text); got != want {
+			t.Errorf("IsAlgebraic(%q) = %v", text, got)
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	for _, tc := range []struct {
+		kind, from, to string
+		size           int
+		want           engine.Move
+		ok             bool
+	}{
+		{"place", "", "b2", 3, engine.Place(1, 1, 3), true},
+		{"move", "a1", "c3", 0, engine.Shift(0, 0, 2, 2), true},
+		{"move", "", "c3", 0, engine.Move{}, false},
+		{"place", "", "zz", 1, engine.Move{}, false},
+		{"ack", "a1", "c3", 0, engine.Move{}, false},
+	} {
+		got, err := Delta(tc.kind, tc.from, tc.to, tc.size)
+		if (err == nil) != tc.ok || (tc.ok && got != tc.want) {
+			t.Errorf("Delta(%q, %q, %q, %d) = %+v, %v", tc.kind, tc.from, tc.to, tc.size, got, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import "fmt"
+
+// Board orientations. They only change how this terminal draws the board
+// and reads typed coordinates; game states always use board coordinates.
+const (
+	orientNormal  = "normal"
+	orientFlipped = "flipped" // rotated 180°, for the player across the table
+	orientLeft    = "left"    // rotated 90° counter-clockwise
+	orientRight   = "right"   // rotated 90° clockwise
+)
+
+var orientation = orientNormal
+
+func validOrientation(o string) bool {
+	return o == orientNormal || o == orientFlipped || o == orientLeft || o == orientRight
+}
+
+// toBoard maps view coordinates to board coordinates.
+func toBoard(r, c int) (int, int) {
+	last := board.Size() - 1
+	switch orientation {
+	case orientFlipped:
+		return last - r, last - c
+	case orientRight:
+		return last - c, r
+	case orientLeft:
+		return c, last - r
+	}
+	return r, c
+}
+
+// toView maps board coordinates to view coordinates.
+func toView(r, c int) (int, int) {
+	last := board.Size() - 1
+	switch orientation {
+	case orientFlipped:
+		return last - r, last - c
+	case orientRight:
+		return c, last - r
+	case orientLeft:
+		return last - c, r
+	}
+	return r, c
+}
+
+// viewStack returns the stack drawn at view position r, c.
+func viewStack(r, c int) Stack {
+	br, bc := toBoard(r, c)
+	return board[br][bc]
+}
+
+// fromView translates the coordinates of a typed action to the board.
+// Out-of-range cells are left alone so the usual bounds errors apply.
+func (a Action) fromView() Action {
+	if a.Kind != actionPlace && a.Kind != actionMove {
+		return a
+	}
+	args := append([]int(nil), a.Args...)
+	translate := func(i int) {
+		n := board.Size()
+		if args[i] >= 0 && args[i] < n && args[i+1] >= 0 && args[i+1] < n {
+			args[i], args[i+1] = toBoard(args[i], args[i+1])
+		}
+	}
+	translate(0)
+	if a.Kind == actionMove {
+		translate(2)
+	}
+	return Action{Kind: a.Kind, Args: args}
+}
+
+// flipBoard turns the view around for the player across the table.
+func flipBoard() {
+	switch orientation {
+	case orientNormal:
+		orientation = orientFlipped
+	case orientFlipped:
+		orientation = orientNormal
+	case orientLeft:
+		orientation = orientRight
+	case orientRight:
+		orientation = orientLeft
+	}
+	fmt.Printf("🔄 Board view is now %s\n", orientation)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"goblets/engine"
+	"math/rand/v2"
+	"time"
+)
+
+// Personalities of the computer opponent. A silent computer never chats.
+const (
+	personalitySilent   = "silent"
+	personalityFriendly = "friendly"
+	personalityTaunting = "taunting"
+)
+
+const (
+	banterInterval = 20 * time.Second // least time between two remarks
+	banterChance   = 0.5              // chance of a remark on a gobble or blunder
+)
+
+// ChatMessage is one line on a game's chat topic.
+type ChatMessage struct {
+	Player  int
+	Name    string
+	Role    string `json:",omitempty"` // "player", "spectator" or "referee"
+	Session string `json:",omitempty"` // sender's client ID, to skip our own echo
+	Text    string
+	Time    time.Time
+}
+
+func chatTopic() string {
+	return gameTopic(gameID, channelChat)
+}
+
+var lastBanter time.Time
+
+// banter has the computer remark on event ("start", "gobble", "blunder",
+// "win", "lose" or "draw") in its personality. Remarks during the game are
+// occasional and rate-limited; those at the end always go out.
+func banter(event string) {
+	personality := config.Conf.AI.Personality
+	if computerSeat == 0 || personality == personalitySilent || personality == "" || !chatAllowed() {
+		return
+	}
+	final := event == "win" || event == "lose" || event == "draw"
+	if !final && (time.Since(lastBanter) < banterInterval || (event != "start" && rand.Float64() >= banterChance)) {
+		return
+	}
+	name := config.Conf.PlayerName
+	if name == "" {
+		name = fmt.Sprintf("Player %d", 3-computerSeat)
+	}
+	texts := translations("banter."+personality+"."+event, map[string]string{"player": name})
+	if len(texts) == 0 {
+		return
+	}
+	lastBanter = time.Now()
+
+	msg := ChatMessage{Player: computerSeat, Name: "Computer", Session: clientID, Text: texts[rand.IntN(len(texts))], Time: time.Now().UTC()}
+	chatLines = append(chatLines, "🖥 "+msg.Text)
+	fmt.Println("\n💬 Computer:", msg.Text)
+	data, _ := json.Marshal(msg)
+	mqttClient.Publish(chatTopic(), 0, false, data) // for spectators; not waited on
+}
+
+// banterBefore remarks on the computer's move m in s: on a blunder that
+// left it a winning move, or on gobbling one of the player's pieces.
+func banterBefore(s engine.State, m engine.Move) {
+	if next, err := s.Apply(m); err == nil && next.Winner == s.Turn {
+		banter("blunder")
+		return
+	}
+	if top, ok := s.Board[m.Row][m.Col].Top(); ok && top.Owner != s.Turn {
+		banter("gobble")
+	}
+}
+
+// banterResult has the computer remark on how the game ended.
+func banterResult(r *Result) {
+	switch {
+	case r.IsDraw():
+		banter("draw")
+	case r.Winner() == computerSeat:
+		banter("win")
+	default:
+		banter("lose")
+	}
+}
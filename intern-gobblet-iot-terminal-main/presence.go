@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Presence is a session's heartbeat on the game's presence topic. The
+// offline message is also registered with the broker as the session's Last
+// Will, so the broker sends it when the process dies or loses its
+// connection.
+type Presence struct {
+	Session string
+	Player  int    `json:",omitempty"` // 0 in the will, which is set before the seat is known
+	Role    string `json:",omitempty"` // "player", "spectator" or "referee"
+	Online  bool
+	Time    time.Time `json:",omitempty"`
+	// Codecs are the payload formats the session reads; none means JSON
+	// only.
+	Codecs []string `json:",omitempty"`
+	// Every is the seconds between the session's heartbeats, when not the
+	// usual heartbeatInterval.
+	Every int `json:",omitempty"`
+	// Protocol is the protocol version the session speaks, 0 from
+	// terminals that don't say.
+	Protocol int `json:",omitempty"`
+}
+
+const (
+	heartbeatInterval       = 5 * time.Second
+	budgetHeartbeatInterval = 60 * time.Second // on a bandwidth budget
+	presenceTimeouts        = 3                // heartbeats missed before a session counts as gone
+)
+
+// peer is what the heartbeats tell about another session.
+type peer struct {
+	Player   int
+	Role     string
+	Seen     time.Time
+	Offline  bool
+	Codecs   []string
+	Every    time.Duration // between its heartbeats
+	Protocol int
+}
+
+var (
+	presenceMu    sync.Mutex
+	presencePeers = map[string]*peer{} // by session
+	presenceOn    bool                 // this session is sending heartbeats
+)
+
+func presenceTopic() string {
+	return gameTopic(gameID, channelPresence)
+}
+
+// seatRole names the role of a seat in heartbeats.
+func seatRole(seat int) string {
+	switch seat {
+	case spectatorID:
+		return "spectator"
+	case refereeID:
+		return "referee"
+	}
+	return "player"
+}
+
+// lastWill is the offline presence to register when connecting, nil
+// outside a game.
+func lastWill() (topic string, payload []byte) {
+	if gameID == "" {
+		return "", nil
+	}
+	payload, _ = json.Marshal(Presence{Session: clientID, Online: false})
+	return presenceTopic(), payload
+}
+
+// heartbeatEvery is how often this session sends a heartbeat: rarely on a
+// bandwidth budget, where they would be most of the traffic of a game.
+func heartbeatEvery() time.Duration {
+	if budgetMode() {
+		return budgetHeartbeatInterval
+	}
+	return heartbeatInterval
+}
+
+// announcePresence publishes a heartbeat, or the goodbye when online is
+// false.
+func announcePresence(online bool) {
+	p := Presence{Session: clientID, Player: playerID, Role: seatRole(playerID), Online: online, Time: time.Now().UTC(), Codecs: readCodecs, Protocol: protocolVersion}
+	if every := heartbeatEvery(); every != heartbeatInterval {
+		p.Every = int(every / time.Second)
+	}
+	data, _ := json.Marshal(p)
+	mqttClient.Publish(presenceTopic(), 0, false, data).Wait()
+}
+
+func onPresence(client mqtt.Client, msg mqtt.Message) {
+	var p Presence
+	if err := decodeDoc(msg.Payload(), &p); err != nil || p.Session == clientID {
+		return
+	}
+	presenceMu.Lock()
+	pr := presencePeers[p.Session]
+	if pr == nil {
+		pr = &peer{}
+		presencePeers[p.Session] = pr
+	}
+	back := p.Online && pr.Offline
+	if p.Online {
+		pr.Player, pr.Role, pr.Seen, pr.Codecs, pr.Protocol = p.Player, p.Role, time.Now(), p.Codecs, p.Protocol
+		pr.Every = heartbeatInterval
+		if p.Every > 0 {
+			pr.Every = time.Duration(p.Every) * time.Second
+		}
+	}
+	gone := !p.Online && !pr.Offline
+	pr.Offline = !p.Online
+	seat := pr.Player
+	presenceMu.Unlock()
+
+	switch {
+	case gone:
+		noteOffline(seat)
+	case back && opponentSeat(seat):
+		fmt.Printf("\n✅ Player %d is back online.\n", seat)
+	}
+}
+
+// opponentSeat reports whether seat is the other player's.
+func opponentSeat(seat int) bool {
+	return (seat == 1 || seat == 2) && seat != playerID
+}
+
+// noteOffline tells the player their opponent has gone.
+func noteOffline(seat int) {
+	if opponentSeat(seat) {
+		fmt.Printf("\n⚠ Player %d disconnected. Their move will come when they reconnect; you can leave with Ctrl+C.\n", seat)
+	}
+}
+
+// expirePresence marks sessions silent for too long as offline, for when
+// the broker is slow to send a will.
+func expirePresence() {
+	var gone []int
+	presenceMu.Lock()
+	for _, pr := range presencePeers {
+		if !pr.Offline && time.Since(pr.Seen) > presenceTimeouts*pr.Every {
+			pr.Offline = true
+			gone = append(gone, pr.Player)
+		}
+	}
+	presenceMu.Unlock()
+	for _, seat := range gone {
+		noteOffline(seat)
+	}
+}
+
+// peerCodecs lists the payload formats each other online session reads,
+// and those of every other seat holder, online or not. A seat holder that
+// never announced its formats, such as gobbot or an older terminal, and a
+// seat no one holds yet read JSON only.
+func peerCodecs() [][]string {
+	seats := currentSeats()
+	presenceMu.Lock()
+	defer presenceMu.Unlock()
+	var codecs [][]string
+	for _, pr := range presencePeers {
+		switch {
+		case pr.Offline:
+		case len(pr.Codecs) == 0:
+			codecs = append(codecs, []string{wireJSON})
+		default:
+			codecs = append(codecs, pr.Codecs)
+		}
+	}
+	for _, seat := range []int{1, 2} {
+		h, taken := seats[seat]
+		if taken && h.Session == clientID {
+			continue
+		}
+		if pr := presencePeers[h.Session]; !taken || pr == nil || len(pr.Codecs) == 0 {
+			codecs = append(codecs, []string{wireJSON})
+		} else if pr.Offline {
+			codecs = append(codecs, pr.Codecs)
+		}
+	}
+	return codecs
+}
+
+// legacyPeer reports whether another online session announced a protocol
+// from before states were numbered, in its heartbeats or over MQTT 5.
+func legacyPeer() bool {
+	if legacyProtocolSeen.Load() {
+		return true
+	}
+	presenceMu.Lock()
+	defer presenceMu.Unlock()
+	for _, pr := range presencePeers {
+		if !pr.Offline && pr.Protocol > 0 && pr.Protocol < seqProtocol {
+			return true
+		}
+	}
+	return false
+}
+
+// presenceLine summarizes who else is online, for the board.
+func presenceLine() string {
+	presenceMu.Lock()
+	defer presenceMu.Unlock()
+	online := map[int]bool{}
+	spectators := 0
+	for _, pr := range presencePeers {
+		switch {
+		case pr.Offline:
+		case pr.Role == "spectator":
+			spectators++
+		default:
+			online[pr.Player] = true
+		}
+	}
+	var parts []string
+	for _, seat := range []int{1, 2} {
+		switch {
+		case !opponentSeat(seat):
+		case online[seat]:
+			parts = append(parts, fmt.Sprintf("🟢 Player %d online", seat))
+		default:
+			parts = append(parts, fmt.Sprintf("⚪ Player %d offline", seat))
+		}
+	}
+	if spectators > 0 {
+		parts = append(parts, fmt.Sprintf("👀 %d watching", spectators))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// watchPresence follows the other sessions of the game and starts this
+// one's heartbeat.
+func watchPresence() {
+	if presenceOn {
+		return
+	}
+	route(channelPresence, onPresence)
+	presenceOn = true
+	announcePresence(true)
+	go func() {
+		for range time.Tick(heartbeatEvery()) {
+			announcePresence(true)
+			expirePresence()
+		}
+	}()
+}
+
+// gameLive reports whether anyone sent a heartbeat on game id within one
+// heartbeat interval.
+func gameLive(id string) bool {
+	beats := make(chan struct{}, 1)
+	topic := gameTopic(id, channelPresence)
+	token := mqttClient.Subscribe(topic, 0, func(client mqtt.Client, msg mqtt.Message) {
+		var p Presence
+		if json.Unmarshal(msg.Payload(), &p) == nil && p.Online {
+			select {
+			case beats <- struct{}{}:
+			default:
+			}
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		return true // can't tell, so don't pass the game over
+	}
+	defer mqttClient.Unsubscribe(topic)
+	select {
+	case <-beats:
+		return true
+	case <-time.After(heartbeatInterval + time.Second):
+		return false
+	}
+}
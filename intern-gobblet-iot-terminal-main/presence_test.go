@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"goblets/config"
+	"testing"
+	"time"
+)
+
+func TestBudgetHeartbeats(t *testing.T) {
+	saved := config.Conf.Bandwidth.BudgetKB
+	t.Cleanup(func() { config.Conf.Bandwidth.BudgetKB = saved })
+	c := useRecordingClient(t)
+
+	config.Conf.Bandwidth.BudgetKB = 0
+	if heartbeatEvery() != heartbeatInterval {
+		t.Errorf("heartbeat every %v without a budget", heartbeatEvery())
+	}
+	config.Conf.Bandwidth.BudgetKB = 500
+	announcePresence(true)
+	var p Presence
+	if err := json.Unmarshal(c.published[0].Payload(), &p); err != nil || p.Every != 60 {
+		t.Errorf("budget heartbeat %s", c.published[0].Payload())
+	}
+}
+
+func TestExpirePresenceFollowsPeerInterval(t *testing.T) {
+	t.Cleanup(func() { presencePeers = map[string]*peer{} })
+	useRecordingClient(t)
+	for session, every := range map[string]int{"usual": 0, "budget": 60} {
+		data, _ := json.Marshal(Presence{Session: session, Player: 1, Online: true, Every: every})
+		onPresence(nil, traceMessage{TraceEntry{Topic: presenceTopic(), Binary: data}})
+		presencePeers[session].Seen = time.Now().Add(-20 * time.Second)
+	}
+	expirePresence()
+	if !presencePeers["usual"].Offline {
+		t.Error("a session silent for four heartbeats is still online")
+	}
+	if presencePeers["budget"].Offline {
+		t.Error("a session on a budget went offline before missing a heartbeat")
+	}
+}
+
+// useSeats sets the seating document for the rest of the test.
+func useSeats(t *testing.T, doc Seats) {
+	t.Helper()
+	seatsMu.Lock()
+	old := seatsDoc
+	seatsDoc = doc
+	seatsMu.Unlock()
+	t.Cleanup(func() {
+		seatsMu.Lock()
+		seatsDoc = old
+		seatsMu.Unlock()
+	})
+}
+
+func TestWireCodecFollowsSeatHolders(t *testing.T) {
+	saved := config.Conf.WireFormat
+	t.Cleanup(func() {
+		config.Conf.WireFormat = saved
+		presencePeers = map[string]*peer{}
+	})
+	useRecordingClient(t)
+	config.Conf.WireFormat = wireCBOR
+	announce := func(p Presence) {
+		data, _ := json.Marshal(p)
+		onPresence(nil, traceMessage{TraceEntry{Topic: presenceTopic(), Binary: data}})
+	}
+
+	useSeats(t, Seats{1: {Session: clientID}})
+	if c := wireCodec(GameMeta{}); c != wireJSON {
+		t.Errorf("published %s with a seat no one holds", c)
+	}
+	useSeats(t, Seats{1: {Session: clientID}, 2: {Session: "bot"}})
+	if c := wireCodec(GameMeta{}); c != wireJSON {
+		t.Errorf("published %s to a seat holder that never announced its formats", c)
+	}
+	announce(Presence{Session: "bot", Player: 2, Online: true, Codecs: []string{wireJSON}})
+	if c := wireCodec(GameMeta{}); c != wireJSON {
+		t.Errorf("published %s to a seat holder reading JSON only", c)
+	}
+	announce(Presence{Session: "bot", Player: 2, Online: true, Codecs: readCodecs})
+	announce(Presence{Session: "bot", Online: false})
+	if c := wireCodec(GameMeta{}); c != wireCBOR {
+		t.Errorf("published %s to an offline seat holder reading CBOR", c)
+	}
+}
+
+func TestStaleSeqZero(t *testing.T) {
+	oldSeq, oldSender := stateSeq, seqSender
+	t.Cleanup(func() {
+		stateSeq, seqSender = oldSeq, oldSender
+		presencePeers = map[string]*peer{}
+	})
+	useRecordingClient(t)
+	stateSeq, seqSender = 5, "peer"
+
+	unnumbered := GameState{Sender: "peer"}
+	if !staleSeq(unnumbered) {
+		t.Error("a state without a Seq was applied among protocol 3 terminals")
+	}
+	if staleSeq(GameState{Seq: 6, Sender: "peer"}) {
+		t.Error("a newer state was stale")
+	}
+
+	data, _ := json.Marshal(Presence{Session: "old", Player: 2, Online: true, Protocol: 2})
+	onPresence(nil, traceMessage{TraceEntry{Topic: presenceTopic(), Binary: data}})
+	if staleSeq(unnumbered) {
+		t.Error("a state without a Seq was refused while a protocol 2 terminal plays")
+	}
+}
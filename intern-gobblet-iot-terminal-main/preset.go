@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"goblets/config"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Assists are optional helpers that make the game friendlier to learn.
+type Assists struct {
+	Takebacks      bool `mapstructure:"takebacks"`       // unlimited takebacks
+	ThreatWarnings bool `mapstructure:"threat_warnings"` // warn about lines one move from completion
+	SimpleMessages bool `mapstructure:"simple_messages"` // short, friendly result messages
+	LargeGlyphs    bool `mapstructure:"large_glyphs"`    // big colored pieces instead of digits
+	Hints          bool `mapstructure:"hints"`           // the hint command, even in rated games
+}
+
+// Rules is the bundle of game options selected by a preset.
+type Rules struct {
+	BoardSize   int     `mapstructure:"board_size"`
+	TimeControl int     `mapstructure:"time_control"` // seconds per player, 0 = no clock
+	Assists     Assists `mapstructure:"assists"`
+}
+
+// Preset is a named rule bundle that can be picked when creating a game.
+type Preset struct {
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	Rules       Rules  `mapstructure:"rules"`
+}
+
+// GameMeta is the game metadata fixed at creation and carried with every state.
+type GameMeta struct {
+	Preset string
+	Rules  Rules
+	Rated  bool
+	Title  string   `json:",omitempty"`
+	Tags   []string `json:",omitempty"`
+	// Players are identities injected by an external platform, if any
+	Players []PlayerIdentity `json:",omitempty"`
+	// FirstPlayer moved first, chosen as FirstChoice says ("creator" or
+	// "coin flip"). Zero in older games, where Player 1 always started.
+	FirstPlayer int    `json:",omitempty"`
+	FirstChoice string `json:",omitempty"`
+	// Themes are the piece themes the players show, by seat
+	Themes map[int]string `json:",omitempty"`
+	// Refereed games have their moves checked by the goblet-referee
+	// service, the only publisher of their state
+	Refereed bool `json:",omitempty"`
+	// Tournament is the ID of the bracket the game is part of
+	Tournament string `json:",omitempty"`
+}
+
+// label returns the title and tags for display, or "" for untitled games.
+func (m GameMeta) label() string {
+	label := m.Title
+	if len(m.Tags) > 0 {
+		label = strings.TrimSpace(label + " [" + strings.Join(m.Tags, ", ") + "]")
+	}
+	return label
+}
+
+const defaultPreset = "classic-gobblers"
+
+var builtinPresets = []Preset{
+	{
+		Name:        "classic-gobblers",
+		Description: "3x3 Gobblet Gobblers, no clock",
+		Rules:       Rules{BoardSize: 3},
+	},
+	{
+		Name:        "full-gobblet-4x4",
+		Description: "4x4 Gobblet with four piece sizes",
+		Rules:       Rules{BoardSize: 4},
+	},
+	{
+		Name:        "blitz",
+		Description: "3x3 Gobblet Gobblers, 3 minutes per player",
+		Rules:       Rules{BoardSize: 3, TimeControl: 180},
+	},
+	{
+		Name:        "kids-mode",
+		Description: "Forgiving 3x3 game with takebacks, threat warnings and big pieces",
+		Rules: Rules{
+			BoardSize:   3,
+			TimeControl: 0, // no clocks for kids
+			Assists: Assists{
+				Takebacks:      true,
+				ThreatWarnings: true,
+				SimpleMessages: true,
+				LargeGlyphs:    true,
+				Hints:          true,
+			},
+		},
+	},
+}
+
+// loadPresets returns the built-in presets merged with any user-defined
+// preset files found in config/presets. User presets override built-ins
+// with the same name.
+func loadPresets() (map[string]Preset, error) {
+	presets := make(map[string]Preset)
+	for _, p := range builtinPresets {
+		presets[p.Name] = p
+	}
+
+	files, err := filepath.Glob(filepath.Join(config.Dir, "presets", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		v := viper.New()
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("preset %s: %w", file, err)
+		}
+		var p Preset
+		if err := v.Unmarshal(&p); err != nil {
+			return nil, fmt.Errorf("preset %s: %w", file, err)
+		}
+		if p.Name == "" {
+			p.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		if p.Rules.BoardSize == 0 {
+			p.Rules.BoardSize = 3
+		}
+		presets[p.Name] = p
+	}
+	return presets, nil
+}
+
+// resolvePreset looks up a preset by name and checks that this client can
+// actually play it on a new game's board of the given size.
+func resolvePreset(name string, size int) (Preset, error) {
+	presets, err := loadPresets()
+	if err != nil {
+		return Preset{}, err
+	}
+	p, ok := presets[name]
+	if !ok {
+		names := make([]string, 0, len(presets))
+		for n := range presets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Preset{}, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	if !presetAllowed(p.Name) {
+		return Preset{}, fmt.Errorf("preset %q is not allowed by %s", name, config.Conf.Organization.Name)
+	}
+	if p.Rules.BoardSize != size {
+		return Preset{}, fmt.Errorf("preset %q is played on a %dx%d board, not %dx%d", name, p.Rules.BoardSize, p.Rules.BoardSize, size, size)
+	}
+	return p, nil
+}
+
+// splitTags parses a comma-separated tag list, dropping empty entries.
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
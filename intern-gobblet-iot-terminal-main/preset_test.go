@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestResolvePresetBoardSize(t *testing.T) {
+	board = nil // no game loaded yet, as when a new game is created
+	tests := []struct {
+		name string
+		size int
+		ok   bool
+	}{
+		{"classic-gobblers", 3, true},
+		{"full-gobblet-4x4", 4, true},
+		{"classic-gobblers", 4, false},
+		{"full-gobblet-4x4", 3, false},
+		{"no-such-preset", 3, false},
+	}
+	for _, tt := range tests {
+		_, err := resolvePreset(tt.name, tt.size)
+		if (err == nil) != tt.ok {
+			t.Errorf("resolvePreset(%q, %d) = %v, want ok %v", tt.name, tt.size, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"goblets/config"
+)
+
+// publicName returns the identifier to show outside the player's own
+// records. With privacy mode on, names are replaced by a stable pseudonym
+// so feeds and shared archives never carry a real identity.
+func publicName(name string) string {
+	if !config.Conf.Privacy.Redact || name == "" {
+		return name
+	}
+	sum := sha256.Sum256([]byte(config.Conf.Privacy.Salt + name))
+	return "anon-" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goblets/config"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Achievements a player can earn across games.
+const (
+	achievementFirstWin   = "first-win"
+	achievementStreak     = "streak-10"  // ten wins in a row
+	achievementTournament = "tournament" // won the final of a tournament bracket
+)
+
+const streakLength = 10
+
+var achievementNames = map[string]string{
+	achievementFirstWin:   "First win",
+	achievementStreak:     "10-game winning streak",
+	achievementTournament: "Tournament victory",
+}
+
+// Profile is the local player's record across games.
+type Profile struct {
+	Wins         int
+	Streak       int // wins in a row
+	Achievements map[string]time.Time
+}
+
+func profilePath() string {
+	return filepath.Join(gobbletDir(), "profile.json")
+}
+
+func loadProfile() (Profile, error) {
+	p := Profile{Achievements: map[string]time.Time{}}
+	data, err := os.ReadFile(profilePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(data, &p)
+	if p.Achievements == nil {
+		p.Achievements = map[string]time.Time{}
+	}
+	return p, err
+}
+
+func saveProfile(p Profile) error {
+	data, _ := json.MarshalIndent(p, "", "  ")
+	if err := os.MkdirAll(gobbletDir(), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(profilePath(), data, 0o600)
+}
+
+// recordProfileResult counts a finished game for the local player and
+// announces any achievements and themes it unlocks.
+func recordProfileResult(result *Result) {
+	if !seated() || computerSeat != 0 {
+		return // games against the built-in computer don't count
+	}
+	p, err := loadProfile()
+	if err != nil {
+		fmt.Println("⚠ Could not read your profile:", err)
+		return
+	}
+	won := result.Winner() == playerID
+	if won {
+		p.Wins++
+		p.Streak++
+	} else {
+		p.Streak = 0
+	}
+
+	var earned []string
+	unlock := func(id string, ok bool) {
+		if _, had := p.Achievements[id]; ok && !had {
+			p.Achievements[id] = time.Now().UTC()
+			earned = append(earned, id)
+		}
+	}
+	unlock(achievementFirstWin, won)
+	unlock(achievementStreak, p.Streak >= streakLength)
+	unlock(achievementTournament, won && wonTournamentFinal())
+	if err := saveProfile(p); err != nil {
+		fmt.Println("⚠ Could not save your profile:", err)
+		return
+	}
+	for _, id := range earned {
+		fmt.Printf("🏅 Achievement unlocked: %s\n", achievementNames[id])
+		for themeID, t := range themes {
+			if t.Unlock == id {
+				fmt.Printf("🎨 The %s theme is yours: set theme: %s in config.yaml\n", t.Name, themeID)
+			}
+		}
+	}
+}
+
+// wonTournamentFinal reports whether the game just won is the final of the
+// tournament in its metadata, as the organizer's bracket has it. A tag or
+// title would do for anyone; the bracket is the organizer's.
+func wonTournamentFinal() bool {
+	if gameMeta.Tournament == "" || mqttClient == nil {
+		return false
+	}
+	t, err := fetchTournament(gameMeta.Tournament)
+	if err != nil {
+		fmt.Println("⚠ Could not check the tournament bracket:", err)
+		return false
+	}
+	final, ok := t.finalMatch()
+	return ok && final.GameID == gameID && (final.Winner == 0 || final.Winner == playerID)
+}
+
+// Theme is a cosmetic look for a player's pieces: a color and a symbol
+// per size, shown on every display in the game.
+type Theme struct {
+	Name   string
+	Color  string // ANSI color, empty for the seat's default
+	Sizes  []string
+	Unlock string // achievement that unlocks it, empty for everyone
+}
+
+var themes = map[string]Theme{
+	"classic": {Name: "Classic"},
+	"ember":   {Name: "Ember", Color: "\033[91m", Sizes: []string{"·", "•", "●", "◉"}, Unlock: achievementFirstWin},
+	"frost":   {Name: "Frost", Color: "\033[96m", Sizes: []string{"▫", "▪", "■", "█"}, Unlock: achievementStreak},
+	"gold":    {Name: "Gold", Color: "\033[93m", Sizes: []string{"☆", "✧", "★", "✪"}, Unlock: achievementTournament},
+}
+
+// myTheme is the theme the local player shows in this game.
+var myTheme string
+
+// chooseTheme picks the configured theme if the profile has unlocked it.
+func chooseTheme() string {
+	id := config.Conf.Theme
+	t, ok := themes[id]
+	if id == "" || id == "classic" {
+		return ""
+	}
+	if !ok {
+		fmt.Printf("⚠ Unknown theme %q\n", id)
+		return ""
+	}
+	p, err := loadProfile()
+	if _, unlocked := p.Achievements[t.Unlock]; err != nil || !unlocked {
+		fmt.Printf("⚠ The %s theme unlocks with the achievement %q\n", t.Name, achievementNames[t.Unlock])
+		return ""
+	}
+	return id
+}
+
+// claimTheme puts the local player's theme into the game metadata, which
+// carries it to every display.
+func claimTheme() {
+	if myTheme == "" || !seated() {
+		return
+	}
+	if gameMeta.Themes == nil {
+		gameMeta.Themes = map[int]string{}
+	}
+	gameMeta.Themes[playerID] = myTheme
+}
+
+// playerTheme returns the theme player shows in this game.
+func playerTheme(player int) Theme {
+	return themes[gameMeta.Themes[player]]
+}
+
+// playerColor is the color of player's pieces on the large board.
+func playerColor(player int) string {
+	if c := playerTheme(player).Color; c != "" {
+		return c
+	}
+	if player == 2 {
+		return colorPlayer2
+	}
+	return colorPlayer1
+}
+
+// pieceText is a piece on the compact board: owner and size, or the
+// owner's theme symbol in its color.
+func pieceText(g Gobblet) string {
+	t := playerTheme(g.Owner)
+	if len(t.Sizes) < g.Size || g.Size < 1 {
+		return fmt.Sprintf(" %d%d  ", g.Owner, g.Size)
+	}
+	return fmt.Sprintf(" %s%d%s%s  ", t.Color, g.Owner, t.Sizes[g.Size-1], colorReset)
+}
+
+// runProfile prints the local player's record and themes.
+func runProfile(args []string) {
+	p, err := loadProfile()
+	if err != nil {
+		fmt.Println("❌ Could not read your profile:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("👤 %d wins, current streak %d\n", p.Wins, p.Streak)
+	for _, id := range []string{achievementFirstWin, achievementStreak, achievementTournament} {
+		if at, ok := p.Achievements[id]; ok {
+			fmt.Printf("  🏅 %-24s %s\n", achievementNames[id], at.Local().Format("2006-01-02"))
+		} else {
+			fmt.Printf("  🔒 %s\n", achievementNames[id])
+		}
+	}
+	fmt.Println("🎨 Themes")
+	for _, id := range []string{"classic", "ember", "frost", "gold"} {
+		t := themes[id]
+		_, unlocked := p.Achievements[t.Unlock]
+		status := "unlocked"
+		if t.Unlock != "" && !unlocked {
+			status = "needs " + achievementNames[t.Unlock]
+		}
+		fmt.Printf("  %-8s %s\n", id, status)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"goblets/notation"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chzyer/readline"
+)
+
+// lineEditor gives the game prompt arrow-key history, editing and tab
+// completion. It is nil when stdin can't be used for line editing, in which
+// case plain line reads are used.
+var (
+	lineEditor *readline.Instance
+	plainInput = bufio.NewScanner(os.Stdin)
+)
+
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gobblet", "history")
+}
+
+// openPrompt switches interactive input to the line editor.
+func openPrompt() {
+	path := historyPath()
+	if path != "" {
+		os.MkdirAll(filepath.Dir(path), 0o700)
+	}
+	rl, err := readline.NewEx(&readline.Config{
+		HistoryFile:       path,
+		AutoComplete:      promptCompleter{},
+		InterruptPrompt:   "^C",
+		HistorySearchFold: true,
+		FuncOnWidthChanged: func(f func()) {
+			readline.DefaultOnWidthChanged(func() {
+				f()
+				redrawScreen()
+			})
+		},
+		// Ctrl+O switches the side pane's tab without touching the line
+		FuncFilterInputRune: func(r rune) (rune, bool) {
+			if r == keyNextTab {
+				go nextTab()
+				return r, false
+			}
+			return r, true
+		},
+	})
+	if err != nil {
+		fmt.Println("⚠ Line editing unavailable, using plain input:", err)
+		readline.DefaultOnWidthChanged(redrawScreen)
+		return
+	}
+	lineEditor = rl
+}
+
+// readPrompt shows prompt and reads one trimmed line. Ctrl+C shuts the
+// client down; ok is false at the end of input.
+func readPrompt(prompt string) (string, bool) {
+	if lineEditor == nil {
+		fmt.Print(prompt)
+		if !plainInput.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(plainInput.Text()), true
+	}
+	lineEditor.SetPrompt(prompt)
+	line, err := lineEditor.Readline()
+	if errors.Is(err, readline.ErrInterrupt) {
+		shutdown()
+	}
+	if err != nil {
+		if err != io.EOF {
+			fmt.Println("❌ Input error:", err)
+		}
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// shutdown leaves the game cleanly: the line editor restores the terminal
+// and saves its history, and the broker sees a proper disconnect.
+func shutdown() {
+	fmt.Println("\n👋 Leaving the game.")
+	closePrompt()
+	if mqttClient != nil && mqttClient.IsConnected() {
+		if presenceOn {
+			announcePresence(false) // a clean disconnect drops the will
+		}
+		mqttClient.Disconnect(250)
+	}
+	os.Exit(0)
+}
+
+// closePrompt hands the terminal back from the line editor; call it
+// before exiting while input is being read.
+func closePrompt() {
+	if lineEditor != nil {
+		lineEditor.Close()
+	}
+}
+
+// promptCompleter completes command names and board coordinates.
+type promptCompleter struct{}
+
+func (promptCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	typed := string(line[:pos])
+	fields := strings.Fields(typed)
+	partial := ""
+	if len(fields) > 0 && !strings.HasSuffix(typed, " ") {
+		partial, fields = fields[len(fields)-1], fields[:len(fields)-1]
+	}
+	var matches [][]rune
+	for _, c := range completions(fields) {
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(partial)) {
+			matches = append(matches, []rune(c[len(partial):]+" "))
+		}
+	}
+	return matches, len([]rune(partial))
+}
+
+// completions lists the words that can follow the words already typed.
+// Moves only complete to ones that are legal right now.
+func completions(fields []string) []string {
+	if len(fields) == 0 {
+		var words []string
+		for _, c := range availableCommands(playerTurn == playerID) {
+			words = append(words, strings.Fields(c.Usage)[0])
+		}
+		return append(words, "P", "M")
+	}
+	var words []string
+	seen := map[string]bool{}
+next:
+	for _, input := range legalInputs() {
+		if len(input) <= len(fields) {
+			continue
+		}
+		for i, f := range fields {
+			if !strings.EqualFold(f, input[i]) {
+				continue next
+			}
+		}
+		if w := input[len(fields)]; !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// legalInputs lists the local player's legal moves as they would be typed
+// in this terminal's view, in both the numeric and algebraic forms.
+func legalInputs() [][]string {
+	if board == nil || playerID < 1 || playerID > 2 {
+		return nil
+	}
+	var inputs [][]string
+	for _, a := range legalMoves(&board, playerID) {
+		m := a.move()
+		m.Row, m.Col = toView(m.Row, m.Col)
+		if !m.IsPlacement() {
+			m.FromRow, m.FromCol = toView(m.FromRow, m.FromCol)
+		}
+		inputs = append(inputs, strings.Fields(actionOf(m).String()), strings.Fields(notation.Format(m)))
+	}
+	return inputs
+}
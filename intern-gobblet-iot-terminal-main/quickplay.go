@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"log"
+	"math/rand"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	quickplayTopic  = "gobblet/lobby/quickplay"
+	quickplayMaxAge = 5 * time.Minute // older waiting entries are ignored
+	quickplaySettle = time.Second     // for a player who arrived at the same time to show up
+)
+
+// quickplayEntry is the retained "someone is waiting" message on the lobby.
+type quickplayEntry struct {
+	GameID   string
+	ClientID string
+	Time     time.Time
+}
+
+// runQuickplay is the zero-setup demo path: it pairs with the next
+// quickplay user on the lobby and starts a default game without prompts.
+func runQuickplay(args []string) {
+	config.Conf.PlayerName = fmt.Sprintf("guest-%04d", rand.Intn(10000))
+	connectMQTT()
+
+	entries := make(chan []byte, 4)
+	token := mqttClient.Subscribe(quickplayTopic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		entries <- msg.Payload()
+	})
+	if token.Wait() && token.Error() != nil {
+		log.Fatal("❌ Subscription Error:", token.Error())
+	}
+
+	var waiting *quickplayEntry
+	select {
+	case payload := <-entries:
+		var e quickplayEntry
+		if json.Unmarshal(payload, &e) == nil && e.GameID != "" && time.Since(e.Time) < quickplayMaxAge {
+			waiting = &e
+		}
+		if waiting != nil && !gameLive(waiting.GameID) {
+			fmt.Println("⚠ The waiting player has gone, starting a new game instead.")
+			waiting = nil
+		}
+	case <-time.After(2 * time.Second):
+	}
+
+	if waiting != nil {
+		joinQuickplay(*waiting)
+		return
+	}
+
+	id, err := freshGameID()
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	preset, err := resolvePreset(defaultPreset, newGameSize(variant))
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	// The game is created and live before it is posted, so whoever finds
+	// the entry can join it straight away
+	gameID = id
+	playerID = 1
+	subscribeGame()
+	createGame(preset, GameMeta{Title: "quickplay"})
+	watchPresence()
+	data, _ := json.Marshal(quickplayEntry{GameID: id, ClientID: clientID, Time: time.Now().UTC()})
+	mqttClient.Publish(quickplayTopic, 1, true, data).Wait()
+
+	// Someone who found the lobby empty at the same moment has posted a game
+	// too: the lower client ID drops its own and joins the other's
+	if rival := quickplayRival(entries); rival != nil && clientID < rival.ClientID {
+		if gameLive(rival.GameID) {
+			dropQuickplayGame()
+			joinQuickplay(*rival)
+			return
+		}
+		mqttClient.Publish(quickplayTopic, 1, true, data).Wait() // the rival's entry replaced ours
+	}
+
+	fmt.Printf("⏳ Waiting for another quickplay player (you are %s)...\n", config.Conf.PlayerName)
+	for payload := range entries {
+		if len(payload) == 0 {
+			break // our entry was taken
+		}
+	}
+	mqttClient.Unsubscribe(quickplayTopic)
+	fmt.Println("🎮 Opponent found! You are Player 1.")
+	playGame()
+}
+
+// joinQuickplay takes the waiting player's game and clears the lobby entry.
+func joinQuickplay(waiting quickplayEntry) {
+	mqttClient.Publish(quickplayTopic, 1, true, []byte{}).Wait()
+	mqttClient.Unsubscribe(quickplayTopic)
+	gameID = waiting.GameID
+	playerID = 2
+	fmt.Printf("🎮 Matched! Joining game %s as Player 2 (%s)\n", gameID, config.Conf.PlayerName)
+	subscribeGame()
+	loadGameState()
+	playGame()
+}
+
+// dropQuickplayGame abandons the game created for a quickplay entry nobody
+// joined: its retained state and advert are cleared and its channels left.
+func dropQuickplayGame() {
+	clearLobby()
+	mqttClient.Publish(stateTopic(), 1, true, []byte{}).Wait()
+	topics := []string{movesTopic() + "/" + wireCBOR, auditTopic()}
+	for _, channel := range gameChannels {
+		topics = append(topics, gameTopic(gameID, channel))
+	}
+	mqttClient.Unsubscribe(topics...).Wait()
+}
+
+// quickplayRival waits briefly for another player's lobby entry.
+func quickplayRival(entries <-chan []byte) *quickplayEntry {
+	deadline := time.After(quickplaySettle)
+	for {
+		select {
+		case payload := <-entries:
+			var e quickplayEntry
+			if json.Unmarshal(payload, &e) == nil && e.GameID != "" && e.ClientID != clientID {
+				return &e
+			}
+		case <-deadline:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQuickplayRival(t *testing.T) {
+	old := clientID
+	t.Cleanup(func() { clientID = old })
+	clientID = "GobbletPlayer-a"
+
+	entries := make(chan []byte, 3)
+	own, _ := json.Marshal(quickplayEntry{GameID: "g1", ClientID: clientID})
+	other, _ := json.Marshal(quickplayEntry{GameID: "g2", ClientID: "GobbletPlayer-b"})
+	entries <- own
+	entries <- []byte{}
+	entries <- other
+	if rival := quickplayRival(entries); rival == nil || rival.GameID != "g2" {
+		t.Errorf("rival %+v, want the entry for g2", rival)
+	}
+
+	entries <- own
+	if rival := quickplayRival(entries); rival != nil {
+		t.Errorf("our own entry counted as a rival: %+v", rival)
+	}
+}
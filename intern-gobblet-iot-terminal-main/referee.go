@@ -0,0 +1,207 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goblets/config"
+	"strings"
+)
+
+const (
+	spectatorID = 3
+	refereeID   = 4
+)
+
+// refereeSender is the Sender of states from the goblet-referee service.
+// Refereed games only apply states from it.
+const refereeSender = "goblet-referee"
+
+// verifyRefereeState checks the signature goblet-referee puts on its states
+// against referee_key.
+func verifyRefereeState(payload []byte) error {
+	pub, err := base64.StdEncoding.DecodeString(config.Conf.RefereeKey)
+	switch {
+	case config.Conf.RefereeKey == "":
+		return errors.New("no referee_key in config.yaml to check the referee's signature with")
+	case err != nil || len(pub) != ed25519.PublicKeySize:
+		return errors.New("referee_key in config.yaml is not an ed25519 public key")
+	}
+	doc, sig, err := refereeSigned(payload)
+	switch {
+	case err != nil:
+		return err
+	case len(sig) == 0:
+		return errors.New("the state isn't signed by the referee")
+	case !ed25519.Verify(pub, doc, sig):
+		return errors.New("the referee's signature doesn't match referee_key")
+	}
+	return nil
+}
+
+// refereeSigned returns what a signature in a refereed game covers, the
+// JSON document without its Signature field with keys sorted as
+// encoding/json writes a map, and the signature.
+func refereeSigned(payload []byte) (doc, sig []byte, err error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, nil, err
+	}
+	if s, ok := fields["Signature"]; ok {
+		json.Unmarshal(s, &sig)
+	}
+	delete(fields, "Signature")
+	doc, err = json.Marshal(fields)
+	return doc, sig, err
+}
+
+// signMove signs a move for the referee with the key of our seat claim,
+// which proves we hold the seat it is made for.
+func signMove(mm MoveMessage) (MoveMessage, error) {
+	key, err := loadSigningKey()
+	if err != nil {
+		return mm, err
+	}
+	data, err := json.Marshal(mm)
+	if err != nil {
+		return mm, err
+	}
+	doc, _, err := refereeSigned(data)
+	if err != nil {
+		return mm, err
+	}
+	mm.Signature = ed25519.Sign(key, doc)
+	return mm, nil
+}
+
+// signState signs a state for the referee with the key of our seat claim,
+// like signMove. The signature goes in a Signature field next to the
+// state's own.
+func signState(data []byte) ([]byte, error) {
+	key, err := loadSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := refereeSigned(data)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["Signature"], _ = json.Marshal(ed25519.Sign(key, doc))
+	return json.Marshal(fields)
+}
+
+// printStacks prints every piece in every cell, not just the visible tops.
+func printStacks() {
+	fmt.Println("Full Stacks:")
+
+	const cellWidth = 25 // Fixed width for each cell to ensure alignment
+
+	for i := range board {
+		for j := range board[i] {
+			cellContent := fmt.Sprintf("[%d,%d]: ", i, j)
+			if len(board[i][j]) == 0 {
+				cellContent += "(empty)"
+			} else {
+				for _, gobblet := range board[i][j] {
+					cellContent += fmt.Sprintf("(%d,%d) ", gobblet.Owner, gobblet.Size)
+				}
+			}
+			fmt.Printf("%-*s", cellWidth, cellContent)
+			if j < len(board[i])-1 {
+				fmt.Print("| ")
+			}
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+}
+
+// runReferee is the input loop for a human arbiter. Every action is signed
+// and published to the game's audit topic.
+func runReferee() {
+	fmt.Println("⚖ Referee mode. Commands: pause | resume | rule <text> | adjudicate <1|2|draw> <reason>")
+
+	for {
+		line, ok := readPrompt("referee> ")
+		if !ok {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		text := strings.Join(fields[1:], " ")
+
+		switch fields[0] {
+		case "pause", "resume":
+			mu.Lock()
+			entry, err := signAudit(fields[0], text, moveCount)
+			if err != nil {
+				mu.Unlock()
+				fmt.Println("❌ Could not sign the ruling:", err)
+				continue
+			}
+			if paused != (fields[0] == "pause") && clocks != nil {
+				clocks.setPaused(playerTurn, fields[0] == "pause")
+			}
+			paused = fields[0] == "pause"
+			ruling = &entry
+			mu.Unlock()
+			saveGameState()
+			publishEntryOrWarn(entry)
+		case "rule":
+			if text == "" {
+				fmt.Println("❌ A ruling needs some text.")
+				continue
+			}
+			publishAuditOrWarn("ruling", text)
+		case "adjudicate":
+			if len(fields) < 2 {
+				fmt.Println("❌ Usage: adjudicate <1|2|draw> <reason>")
+				continue
+			}
+			var result *Result
+			switch fields[1] {
+			case "1", "2":
+				result = newWinResult(int(fields[1][0]-'0'), TerminationAdjudication)
+			case "draw":
+				result = newDrawResult(TerminationAdjudication)
+			default:
+				fmt.Println("❌ Usage: adjudicate <1|2|draw> <reason>")
+				continue
+			}
+			mu.Lock()
+			entry, err := signAudit("adjudication", fmt.Sprintf("%s: %s", result, strings.Join(fields[2:], " ")), moveCount)
+			if err != nil {
+				mu.Unlock()
+				fmt.Println("❌ Could not sign the ruling:", err)
+				continue
+			}
+			gameResult = result
+			ruling = &entry
+			mu.Unlock()
+			publishEntryOrWarn(entry)
+			saveGameState()
+		default:
+			fmt.Println("❌ Unknown referee command:", fields[0])
+		}
+	}
+}
+
+func publishAuditOrWarn(action, text string) {
+	if err := publishAudit(action, text); err != nil {
+		fmt.Println("❌ Could not publish signed audit entry:", err)
+	}
+}
+
+func publishEntryOrWarn(entry AuditEntry) {
+	if err := publishAuditEntry(entry); err != nil {
+		fmt.Println("❌ Could not publish signed audit entry:", err)
+	}
+}
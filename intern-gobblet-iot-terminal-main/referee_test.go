@@ -0,0 +1,59 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"goblets/config"
+	"testing"
+)
+
+// signAsReferee signs a state the way goblet-referee's stamp does.
+func signAsReferee(t *testing.T, key ed25519.PrivateKey, fields map[string]any) []byte {
+	t.Helper()
+	signed, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields["Signature"] = ed25519.Sign(key, signed)
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestVerifyRefereeState(t *testing.T) {
+	pub, key, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+	saved := config.Conf.RefereeKey
+	t.Cleanup(func() { config.Conf.RefereeKey = saved })
+	config.Conf.RefereeKey = base64.StdEncoding.EncodeToString(pub)
+
+	state := func() map[string]any {
+		return map[string]any{"Moves": 3, "PlayerTurn": 2, "Sender": refereeSender, "Seq": 7}
+	}
+	if err := verifyRefereeState(signAsReferee(t, key, state())); err != nil {
+		t.Errorf("signed by the referee: %v", err)
+	}
+	if err := verifyRefereeState(signAsReferee(t, other, state())); err == nil {
+		t.Error("accepted a state signed with another key")
+	}
+	unsigned, _ := json.Marshal(state())
+	if err := verifyRefereeState(unsigned); err == nil {
+		t.Error("accepted an unsigned state")
+	}
+
+	var tampered map[string]json.RawMessage
+	json.Unmarshal(signAsReferee(t, key, state()), &tampered)
+	tampered["PlayerTurn"] = json.RawMessage("1")
+	data, _ := json.Marshal(tampered)
+	if err := verifyRefereeState(data); err == nil {
+		t.Error("accepted a state changed after signing")
+	}
+
+	config.Conf.RefereeKey = ""
+	if err := verifyRefereeState(signAsReferee(t, key, state())); err == nil {
+		t.Error("accepted a state without a referee_key to check it against")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Rejection tells the other terminals that a published state was refused,
+// so its sender knows the opponent isn't following it.
+type Rejection struct {
+	Player int // seat that refused the state
+	Moves  int // move count of the refused state
+	Reason string
+}
+
+func rejectionTopic() string {
+	return gameTopic(gameID, channelControl)
+}
+
+// rejectState reports why a received state was refused. Seated players also
+// publish the refusal for the sender; spectators only log it.
+func rejectState(state GameState, reason string) {
+	fmt.Println("❌", reason)
+	if mqttClient != nil { // not when replaying a trace
+		go saveSnapshot(reason) // after the handler lets go of the delivery locks
+	}
+	if !seated() {
+		return
+	}
+	data, _ := json.Marshal(Rejection{Player: playerID, Moves: state.Moves, Reason: reason})
+	// Not waited on: this runs in the message handler
+	mqttClient.Publish(rejectionTopic(), 1, false, data)
+}
+
+// onRejection tells the local player that the opponent refused our state.
+func onRejection(client mqtt.Client, msg mqtt.Message) {
+	var r Rejection
+	if json.Unmarshal(msg.Payload(), &r) != nil || r.Reason == "" || r.Player == playerID {
+		return
+	}
+	if r.Player == refereeID {
+		noteRefused(r.Moves)
+		fmt.Printf("\n⚠ The referee refused move %d: %s\n", r.Moves, r.Reason)
+		return
+	}
+	fmt.Printf("\n⚠ Player %d refused the state for move %d: %s\n", r.Player, r.Moves, r.Reason)
+}
+
+// watchRejections follows refusals of the states we publish.
+func watchRejections() {
+	route(channelControl, onRejection)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"goblets/config"
+	"os"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Invite asks another device to join a new game. It is published without
+// retain, so only devices online at the time see it.
+type Invite struct {
+	From    string `json:",omitempty"` // player name of the sender
+	GameID  string
+	Seat    int    // seat offered to the invitee
+	Rematch string `json:",omitempty"` // archived game this is a rematch of
+	Time    time.Time
+}
+
+func inviteTopic(device string) string {
+	return "gobblet/invite/" + device
+}
+
+// watchInvites prints invitations sent to this device.
+func watchInvites() {
+	token := mqttClient.Subscribe(inviteTopic(deviceID), 1, func(client mqtt.Client, msg mqtt.Message) {
+		var inv Invite
+		if json.Unmarshal(msg.Payload(), &inv) != nil || inv.GameID == "" {
+			return
+		}
+		from := inv.From
+		if from == "" {
+			from = "Your opponent"
+		}
+		fmt.Printf("\n📨 %s invites you to a rematch of game %s: join game %s as Player %d.\n", from, inv.Rematch, inv.GameID, inv.Seat)
+	})
+	if token.Wait() && token.Error() != nil {
+		fmt.Println("⚠ Could not watch for invitations:", token.Error())
+	}
+}
+
+// findArchived returns the archived game with the given ID.
+func findArchived(id string) (*ArchiveRecord, error) {
+	records, err := loadArchive()
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		if records[i].GameID == id {
+			return &records[i], nil
+		}
+	}
+	return nil, fmt.Errorf("game %s is not in the archive", id)
+}
+
+// runRematchFrom creates a new game with the rules, time control and seats
+// of an archived one, invites the same opponent if their device is online,
+// and plays it. --swap plays the other seat and hands the first move over.
+func runRematchFrom(args []string) {
+	fs := flag.NewFlagSet("rematch-from", flag.ExitOnError)
+	swap := fs.Bool("swap", false, "swap seats with your opponent")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: rematch-from [--swap] <archivedGameID>")
+		os.Exit(1)
+	}
+	old, err := findArchived(fs.Arg(0))
+	if err != nil {
+		fmt.Println("❌", err)
+		os.Exit(1)
+	}
+	if old.Player == 0 {
+		fmt.Printf("❌ You only watched game %s, so there is no opponent to rematch.\n", old.GameID)
+		os.Exit(1)
+	}
+
+	meta := old.Meta
+	seat := old.Player
+	if *swap {
+		seat = 3 - seat
+		if meta.FirstPlayer != 0 {
+			meta.FirstPlayer = 3 - meta.FirstPlayer
+		}
+		for i := range meta.Players {
+			meta.Players[i].Seat = 3 - meta.Players[i].Seat
+		}
+	}
+
+	openPrompt()
+	connectMQTT()
+
+	// The opponent's seat claim in the old game says which device to invite
+	gameID = old.GameID
+	opponent, claimErr := waitForSeatClaim(3-old.Player, 2*time.Second)
+
+	if gameID, err = freshGameID(); err != nil {
+		fmt.Println("❌", err)
+		os.Exit(1)
+	}
+	playerID = seat
+	variant = old.Variant
+	houseRules = old.HouseRules
+	boardSize = old.Board.Size()
+	subscribeGame()
+	watchInvites()
+	preset := Preset{Name: meta.Preset, Description: "rules of game " + old.GameID, Rules: meta.Rules}
+	createGame(preset, meta)
+	fmt.Printf("🔁 Rematch of game %s created as game %s. You are Player %d.\n", old.GameID, gameID, playerID)
+
+	if claimErr != nil || !opponent.Verify() {
+		fmt.Printf("⚠ Could not find your opponent's device; share the game ID %s with them.\n", gameID)
+	} else {
+		data, _ := json.Marshal(Invite{
+			From:    config.Conf.PlayerName,
+			GameID:  gameID,
+			Seat:    3 - playerID,
+			Rematch: old.GameID,
+			Time:    time.Now().UTC(),
+		})
+		if token := mqttClient.Publish(inviteTopic(opponent.Device), 1, false, data); token.Wait() && token.Error() != nil {
+			fmt.Println("⚠ Could not send the invitation:", token.Error())
+		} else {
+			fmt.Println("📤 Invited your opponent, if they're online.")
+		}
+	}
+
+	playGame()
+}
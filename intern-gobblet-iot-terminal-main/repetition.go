@@ -0,0 +1,7 @@
+package main
+
+// positionCounts counts how often each position (board plus side to move)
+// has occurred, keyed by engine.RepetitionKey. It travels in GameState so
+// both terminals agree; engine.Game.Play draws the game on threefold
+// repetition.
+var positionCounts = map[string]int{}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"goblets/engine"
+	"goblets/notation"
+	"strings"
+)
+
+var sizeWords = []string{"small", "medium", "large", "extra large"}
+
+const colorDim = "\033[2m" // reserve pieces that can't be brought in yet
+
+// reserveLine lists player's reserve pieces with their counts. Pieces the
+// engine would let the local player bring in now are shown as [S] items to
+// pick; the rest, e.g. under a bigger piece in a classic stack, are dimmed.
+func reserveLine(player int) string {
+	rules := gameRules()
+	left := rules.Reserve(board, player)
+	var items []string
+	for size := 1; size <= rules.Sizes; size++ {
+		item := fmt.Sprintf("%s ×%d", sizeWords[size-1], left[size-1])
+		switch {
+		case player != playerID:
+			items = append(items, item)
+		case rules.InReserve(board, player, size):
+			items = append(items, fmt.Sprintf("[%s] %s", notation.SizeName(size), item))
+		default:
+			items = append(items, colorDim+item+colorReset)
+		}
+	}
+	return strings.Join(items, "   ")
+}
+
+// printReserves shows both players' reserves below the board.
+func printReserves() {
+	for player := 1; player <= 2; player++ {
+		label := fmt.Sprintf("Player %d:", player)
+		if player == playerID {
+			label = "Your reserve:"
+		}
+		fmt.Printf("🧺 %-14s %s\n", label, reserveLine(player))
+	}
+}
+
+// reserveItem reads a line picking a reserve item by its size letter.
+func reserveItem(line string) (int, bool) {
+	line = strings.ToUpper(strings.TrimSpace(line))
+	if len(line) != 1 {
+		return 0, false
+	}
+	for size := 1; size <= gameRules().Sizes; size++ {
+		if notation.SizeName(size) == line {
+			return size, true
+		}
+	}
+	return 0, false
+}
+
+// pickPlacement asks where to place the picked reserve piece and returns the
+// placement as a move line, or false when the player cancels.
+func pickPlacement(size int, input <-chan string) (string, bool) {
+	if !gameRules().InReserve(board, playerID, size) {
+		fmt.Printf("❌ %s.\n", moveErrorText(engine.ErrReserveEmpty))
+		return "", false
+	}
+	fmt.Printf("📍 Place a %s piece where? Type a cell like b2, or anything else to cancel: ", sizeWords[size-1])
+	line, ok := readTurnInput(input)
+	if !ok {
+		shutdown()
+	}
+	if _, _, err := notation.ParseSquare(strings.TrimSpace(line)); err != nil {
+		fmt.Println("↩ Cancelled.")
+		return "", false
+	}
+	return fmt.Sprintf("P %s %s", strings.TrimSpace(line), notation.SizeName(size)), true
+}
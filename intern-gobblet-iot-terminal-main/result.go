@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"goblets/engine"
+)
+
+// Outcome is the three-valued result of a finished game, in the usual
+// score notation (1-0, 0-1, ½-½).
+type Outcome string
+
+const (
+	OutcomePlayer1 Outcome = "1-0"
+	OutcomePlayer2 Outcome = "0-1"
+	OutcomeDraw    Outcome = "½-½"
+)
+
+// Termination records why a game ended.
+type Termination string
+
+const (
+	TerminationLine         Termination = engine.TerminationLine // three in a row, the checkmate equivalent
+	TerminationTime         Termination = "time"
+	TerminationResignation  Termination = "resignation"
+	TerminationAgreement    Termination = "agreement"
+	TerminationAbandonment  Termination = "abandonment"
+	TerminationAdjudication Termination = "adjudication"               // decided by a referee
+	TerminationUncovered    Termination = engine.TerminationUncovered  // opponent lifted a piece off the winner's line
+	TerminationRepetition   Termination = engine.TerminationRepetition // same position three times
+	TerminationStalemate    Termination = engine.TerminationStalemate  // the player to move has no legal move
+	TerminationSessionTime  Termination = "session time"               // the organization's session time ran out
+)
+
+// Result is the structured end-of-game record carried in GameState and used
+// by anything that reports or stores finished games.
+type Result struct {
+	Outcome     Outcome
+	Termination Termination
+}
+
+// newWinResult builds the result for a game won by the given player.
+func newWinResult(winner int, termination Termination) *Result {
+	outcome := OutcomePlayer1
+	if winner == 2 {
+		outcome = OutcomePlayer2
+	}
+	return &Result{Outcome: outcome, Termination: termination}
+}
+
+// newDrawResult builds the result for a drawn game.
+func newDrawResult(termination Termination) *Result {
+	return &Result{Outcome: OutcomeDraw, Termination: termination}
+}
+
+// Winner returns the winning player number, or 0 for a draw.
+func (r Result) Winner() int {
+	switch r.Outcome {
+	case OutcomePlayer1:
+		return 1
+	case OutcomePlayer2:
+		return 2
+	}
+	return 0
+}
+
+// IsDraw reports whether the game ended without a winner.
+func (r Result) IsDraw() bool {
+	return r.Outcome == OutcomeDraw
+}
+
+func (r Result) String() string {
+	if r.IsDraw() {
+		return fmt.Sprintf("Draw by %s (%s)", r.Termination, r.Outcome)
+	}
+	return fmt.Sprintf("Player %d wins by %s (%s)", r.Winner(), r.Termination, r.Outcome)
+}
+
+// announceResult prints the final result of the game.
+func announceResult(r *Result) {
+	if gameMeta.Rules.Assists.SimpleMessages {
+		announceSimpleResult(r)
+		return
+	}
+	if r.IsDraw() {
+		fmt.Printf("🤝 %s\n", r)
+		return
+	}
+	fmt.Printf("🎉 %s\n", r)
+}
+
+// engineResult converts the result of a move the engine ended the game
+// with.
+func engineResult(r *engine.Result) *Result {
+	if r == nil {
+		return nil
+	}
+	if r.Winner == 0 {
+		return newDrawResult(Termination(r.Termination))
+	}
+	return newWinResult(r.Winner, Termination(r.Termination))
+}
+
+// lineResult returns the result for a three-in-a-row win, or nil while the
+// game is still in progress.
+func lineResult(winner int) *Result {
+	if winner == 0 {
+		return nil
+	}
+	return newWinResult(winner, TerminationLine)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goblets/config"
+	"maps"
+	"os"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// SeatHolder is the device sitting in a seat.
+type SeatHolder struct {
+	Device  string
+	Session string
+	Name    string `json:",omitempty"`
+	Time    time.Time
+}
+
+// Seats is a game's retained seating document, by seat number. A device
+// keeps its seat for the whole game, so a restarted terminal gets it back.
+type Seats map[int]SeatHolder
+
+const (
+	seatsProbe    = time.Second // wait for the retained document
+	seatsSettle   = time.Second // let racing claims land before reading back
+	seatsAttempts = 3
+)
+
+var (
+	seatsMu    sync.Mutex
+	seatsDoc   = Seats{}
+	seatsReady = make(chan struct{})
+	seatsOnce  sync.Once
+)
+
+// errSeatTaken is returned with the holder of a seat another device has.
+var errSeatTaken = errors.New("the seat is taken")
+
+func seatsTopic() string {
+	return gameTopic(gameID, channelSeats)
+}
+
+// watchSeats follows the seating document.
+func watchSeats() {
+	seatsOnce.Do(func() {
+		token := mqttClient.Subscribe(seatsTopic(), 1, func(client mqtt.Client, msg mqtt.Message) {
+			var doc Seats
+			if json.Unmarshal(msg.Payload(), &doc) != nil {
+				return
+			}
+			seatsMu.Lock()
+			seatsDoc = doc
+			seatsMu.Unlock()
+			if seated() {
+				go syncLobbySeats() // not in the handler: it waits on the broker
+			}
+			select {
+			case <-seatsReady:
+			default:
+				close(seatsReady)
+			}
+		})
+		if token.Wait() && token.Error() != nil {
+			fmt.Println("⚠ Could not read the seating:", token.Error())
+		}
+		select {
+		case <-seatsReady:
+		case <-time.After(seatsProbe): // no one has sat down yet
+		}
+	})
+}
+
+// currentSeats returns a copy of the latest seating.
+func currentSeats() Seats {
+	seatsMu.Lock()
+	defer seatsMu.Unlock()
+	return maps.Clone(seatsDoc)
+}
+
+// reserveSeats claims seats for this device in the seating document. MQTT
+// has no compare-and-swap, so the claim is written, given time for racing
+// claims to land, and read back: whichever claim the broker kept wins. A
+// claim lost to a write for another seat is made again. errSeatTaken comes
+// with the holder when another device has one of the seats; any other
+// error means the seats couldn't be confirmed.
+func reserveSeats(seats ...int) (SeatHolder, error) {
+	watchSeats()
+	for range seatsAttempts {
+		doc := currentSeats()
+		mine := true
+		for _, seat := range seats {
+			h, taken := doc[seat]
+			if taken && h.Device != deviceID {
+				return h, errSeatTaken
+			}
+			mine = mine && taken && h.Session == clientID
+		}
+		if mine {
+			return SeatHolder{}, nil
+		}
+
+		if doc == nil {
+			doc = Seats{}
+		}
+		for _, seat := range seats {
+			doc[seat] = SeatHolder{Device: deviceID, Session: clientID, Name: publicName(config.Conf.PlayerName), Time: time.Now().UTC()}
+		}
+		data, _ := json.Marshal(doc)
+		if token := mqttClient.Publish(seatsTopic(), 1, true, data); token.Wait() && token.Error() != nil {
+			return SeatHolder{}, token.Error()
+		}
+		time.Sleep(seatsSettle)
+	}
+	return SeatHolder{}, errors.New("the seating kept changing")
+}
+
+// takeSeat reserves the local seat, and the computer's, falling back to
+// spectating when someone else sits there. A game against the computer
+// can't be watched instead, so it ends.
+func takeSeat() {
+	seats := []int{playerID}
+	if computerSeat != 0 {
+		seats = append(seats, computerSeat)
+	}
+	holder, err := reserveSeats(seats...)
+	switch {
+	case err == nil:
+		return
+	case computerSeat != 0 && errors.Is(err, errSeatTaken):
+		fmt.Printf("❌ A seat of this game is taken by %s, so you can't play the computer in it.\n", holderName(holder))
+		os.Exit(1)
+	case computerSeat != 0:
+		fmt.Println("❌ Could not reserve the seats to play the computer:", err)
+		os.Exit(1)
+	case errors.Is(err, errSeatTaken):
+		fmt.Printf("❌ Seat %d is taken by %s. You are watching as a spectator.\n", playerID, holderName(holder))
+	default:
+		fmt.Printf("❌ Could not reserve seat %d (%v). You are watching as a spectator.\n", playerID, err)
+	}
+	playerID = spectatorID
+}
+
+// holderName names the holder of a seat for messages.
+func holderName(holder SeatHolder) string {
+	if holder.Name != "" {
+		return holder.Name
+	}
+	return "device " + holder.Device
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"goblets/ai"
+	"goblets/bridge"
+	"goblets/engine"
+	"os"
+	"strings"
+	"time"
+)
+
+// selfPlayer is one side of a self-play match, written "level[:engine]",
+// e.g. "hard" or "medium:mcts", or "ext:<command>" for an external engine.
+type selfPlayer struct {
+	Name     string
+	Limits   ai.Limits
+	External string        // external engine command line
+	Movetime time.Duration // per move for an external engine
+	engine   *bridge.Engine
+}
+
+func parseSelfPlayer(spec string) (selfPlayer, error) {
+	if command, ok := strings.CutPrefix(spec, "ext:"); ok {
+		return selfPlayer{Name: spec, External: command, Movetime: time.Second}, nil
+	}
+	level, engineName, _ := strings.Cut(spec, ":")
+	l, ok := ai.Levels[level]
+	if !ok {
+		return selfPlayer{}, fmt.Errorf("unknown level %q", level)
+	}
+	switch engineName {
+	case "", ai.Minimax, ai.MCTS:
+		l.Engine = engineName
+	default:
+		return selfPlayer{}, fmt.Errorf("unknown engine %q", engineName)
+	}
+	return selfPlayer{Name: spec, Limits: l}, nil
+}
+
+// start runs the player's external engine, if it has one.
+func (p *selfPlayer) start() error {
+	if p.External == "" {
+		return nil
+	}
+	e, err := bridge.Start(p.External)
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.Name, err)
+	}
+	p.engine = e
+	return nil
+}
+
+func (p *selfPlayer) stop() {
+	if p.engine != nil {
+		p.engine.Close()
+	}
+}
+
+// move picks the player's move in s. It returns bridge.ErrNoMove when there
+// is no legal move.
+func (p selfPlayer) move(s engine.State) (engine.Move, error) {
+	if p.engine != nil {
+		return p.engine.BestMove(s, p.Movetime)
+	}
+	m, ok := ai.Choose(s, p.Limits)
+	if !ok {
+		return m, bridge.ErrNoMove
+	}
+	return m, nil
+}
+
+// selfPlayGame plays one game between players (indexed by seat-1) and
+// returns its result and moves. publish, when set, is called with the
+// state after every move.
+func selfPlayGame(players [2]selfPlayer, v engine.Variant, maxPlies int, publish func(GameState)) (*Result, []engine.Move) {
+	g := engine.Game{State: engine.State{Board: engine.NewBoard(v.Rules().Size), Turn: 1, Variant: v}}
+	var moves []engine.Move
+	for _, p := range players {
+		if p.engine != nil {
+			if err := p.engine.NewGame(); err != nil {
+				fmt.Printf("⚠ %s: %v\n", p.Name, err)
+			}
+		}
+	}
+	for ply := 1; ply <= maxPlies; ply++ {
+		m, err := players[g.Turn-1].move(g.State)
+		if errors.Is(err, bridge.ErrNoMove) {
+			return newDrawResult(TerminationStalemate), moves
+		}
+		var next engine.Game
+		if err == nil {
+			next, err = g.Play(m)
+		}
+		if err != nil {
+			fmt.Printf("⚠ %s forfeits: %v\n", players[g.Turn-1].Name, err)
+			return newWinResult(3-g.Turn, TerminationAdjudication), moves
+		}
+		moves = append(moves, m)
+
+		g = next
+		result := engineResult(g.Result)
+		if publish != nil {
+			publish(GameState{Board: g.Board, PlayerTurn: g.Turn, Result: result, Moves: g.Moves, Variant: v, Meta: GameMeta{Title: "self-play"}})
+		}
+		if result != nil {
+			return result, moves
+		}
+	}
+	return newDrawResult(TerminationAdjudication), moves
+}
+
+// addToBook scores the first plies moves of a game for the side that played
+// them: 1 for a win, ½ for a draw and nothing for a loss.
+func addToBook(book *ai.Book, v engine.Variant, moves []engine.Move, result *Result, plies int) {
+	s := engine.State{Board: engine.NewBoard(v.Rules().Size), Turn: 1, Variant: v}
+	for _, m := range moves[:min(plies, len(moves))] {
+		weight := 0.5
+		if !result.IsDraw() {
+			weight = 0
+			if result.Winner() == s.Turn {
+				weight = 1
+			}
+		}
+		book.Add(s, m, weight)
+		next, err := s.Apply(m)
+		if err != nil {
+			return
+		}
+		s = next
+	}
+}
+
+// runSelfPlay has two engines play each other and reports the results, for
+// tuning the evaluation and soak-testing the protocol.
+func runSelfPlay(args []string) {
+	fs := flag.NewFlagSet("selfplay", flag.ExitOnError)
+	games := fs.Int("games", 20, "games to play; the players swap seats every game")
+	p1 := fs.String("p1", "medium", "first player, level[:engine] or ext:<command>, e.g. hard or medium:mcts")
+	p2 := fs.String("p2", "medium:mcts", "second player, level[:engine] or ext:<command>")
+	variantName := fs.String("variant", "junior", "junior or classic")
+	maxPlies := fs.Int("max-plies", 200, "adjudicate a game as a draw after this many moves")
+	publish := fs.Bool("publish", false, "publish every game to the broker as a spectatable game")
+	bookFile := fs.String("book", "", "write an opening book built from the games to this file")
+	bookPlies := fs.Int("book-plies", 6, "moves per game that go into the book")
+	fs.Parse(args)
+
+	v := engine.Variant(*variantName)
+	a, errA := parseSelfPlayer(*p1)
+	b, errB := parseSelfPlayer(*p2)
+	if !v.Valid() || errA != nil || errB != nil {
+		fmt.Println("❌ Usage: selfplay [--games N] [--p1 level[:engine]] [--p2 level[:engine]] [--variant junior|classic] [--publish] [--book <file>]")
+		os.Exit(1)
+	}
+	for _, p := range []*selfPlayer{&a, &b} {
+		if err := p.start(); err != nil {
+			fmt.Println("❌ Could not start engine", err)
+			os.Exit(1)
+		}
+		defer p.stop()
+	}
+	if *publish {
+		connectMQTT()
+	}
+
+	book := &ai.Book{Variant: v}
+	var wins [2]int // by player, --p1 then --p2
+	draws, plies := 0, 0
+	start := time.Now()
+	for g := 0; g < *games; g++ {
+		players, first := [2]selfPlayer{a, b}, 0
+		if g%2 == 1 {
+			players, first = [2]selfPlayer{b, a}, 1
+		}
+		var send func(GameState)
+		id := "local"
+		if *publish {
+			var err error
+			if id, err = freshGameID(); err != nil {
+				fmt.Println("❌", err)
+				os.Exit(1)
+			}
+			send = func(state GameState) {
+				publishPayload(gameTopic(id, channelState), 1, true, encodeState(state)).Wait()
+			}
+		}
+		result, moves := selfPlayGame(players, v, *maxPlies, send)
+		n := len(moves)
+		plies += n
+		addToBook(book, v, moves, result, *bookPlies)
+		if result.IsDraw() {
+			draws++
+		} else {
+			wins[(first+result.Winner()-1)%2]++
+		}
+		fmt.Printf("🎲 Game %d (%s): %s vs %s, %s after %d moves\n", g+1, id, players[0].Name, players[1].Name, result, n)
+	}
+
+	fmt.Println("📊 Self-play results")
+	fmt.Printf("  p1 %-13s %d wins\n", a.Name, wins[0])
+	fmt.Printf("  p2 %-13s %d wins\n", b.Name, wins[1])
+	fmt.Printf("  %-16s %d\n", "Draws", draws)
+	fmt.Printf("  Average length: %.1f moves, %s per game\n", float64(plies)/float64(max(*games, 1)), (time.Since(start) / time.Duration(max(*games, 1))).Round(time.Millisecond))
+
+	if *bookFile != "" {
+		book.Sort()
+		data, _ := json.MarshalIndent(book, "", "  ")
+		if err := os.WriteFile(*bookFile, data, 0644); err != nil {
+			fmt.Println("❌ Could not write the book:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📖 Wrote an opening book of %d positions to %s\n", len(book.Positions), *bookFile)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"os"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Policies for a player who connects to the same seat twice.
+const (
+	duplicateTakeover = "takeover" // the new session gets the seat, the old one spectates
+	duplicateReject   = "reject"   // the session holding the seat keeps it
+)
+
+// SeatConflict tells a newer session of the same player that the seat is
+// still held elsewhere.
+type SeatConflict struct {
+	Session string // the rejected session
+	Device  string // device still holding the seat
+}
+
+func conflictTopic(player int) string {
+	return claimTopic(player) + "/conflict"
+}
+
+// claimSeat publishes the local seat claim, gives a live session of the
+// same player a moment to reject it, then watches the seat for later
+// duplicates.
+func claimSeat() {
+	rejected := make(chan SeatConflict, 1)
+	token := mqttClient.Subscribe(conflictTopic(playerID), 1, func(client mqtt.Client, msg mqtt.Message) {
+		var c SeatConflict
+		if json.Unmarshal(msg.Payload(), &c) == nil && c.Session == clientID {
+			select {
+			case rejected <- c:
+			default:
+			}
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		fmt.Println("⚠ Could not watch for duplicate sessions:", token.Error())
+	}
+
+	if err := publishSeatClaim(); err != nil {
+		fmt.Println("⚠ Could not publish signed seat claim:", err)
+		return
+	}
+
+	select {
+	case c := <-rejected:
+		fmt.Printf("❌ You are already playing seat %d on device %s. Close that session or set duplicate_session: takeover.\n", playerID, c.Device)
+		os.Exit(1)
+	case <-time.After(2 * time.Second):
+	}
+
+	token = mqttClient.Subscribe(claimTopic(playerID), 1, onSeatClaim)
+	if token.Wait() && token.Error() != nil {
+		fmt.Println("⚠ Could not watch for duplicate sessions:", token.Error())
+	}
+}
+
+// onSeatClaim applies the duplicate-session policy when another session of
+// the same player claims our seat.
+func onSeatClaim(client mqtt.Client, msg mqtt.Message) {
+	var claim SeatClaim
+	if json.Unmarshal(msg.Payload(), &claim) != nil || claim.Session == clientID || !claim.Verify() {
+		return
+	}
+	key, err := loadSigningKey()
+	if err != nil || !bytes.Equal(claim.PublicKey, key.Public().(ed25519.PublicKey)) {
+		fmt.Printf("\n⚠ Seat %d was claimed by a different player key\n", claim.Player)
+		return
+	}
+
+	if config.Conf.DuplicateSession == duplicateReject {
+		data, _ := json.Marshal(SeatConflict{Session: claim.Session, Device: deviceID})
+		mqttClient.Publish(conflictTopic(claim.Player), 1, false, data)
+		go func() { // don't wait on a publish inside the message handler
+			if err := publishSeatClaim(); err != nil {
+				fmt.Println("⚠ Could not restore seat claim:", err)
+			}
+		}()
+		fmt.Printf("\n⚠ Rejected a second session for your seat from device %s\n", claim.Device)
+		return
+	}
+
+	mu.Lock()
+	playerID = spectatorID
+	mu.Unlock()
+	mqttClient.Unsubscribe(claimTopic(claim.Player))
+	fmt.Printf("\n⚠ Your seat was taken over by your session on device %s. You are now spectating.\n", claim.Device)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// snapshotMessages is how many received messages a snapshot keeps.
+const snapshotMessages = 50
+
+var recentMessages []TraceEntry // last received messages, oldest first; guarded by traceMu
+
+// Snapshot is the internal state of a live session, saved for bug reports.
+// Its messages can be replayed with playback --snapshot.
+type Snapshot struct {
+	Time     time.Time
+	Reason   string
+	GameID   string
+	Player   int
+	Session  string
+	State    GameState
+	Link     string
+	Seq      uint64                    // last ordered-delivery sequence number we sent
+	Outbox   []uint64                  // our envelopes kept for retransmission
+	Streams  map[string]SnapshotStream // ordered delivery from each sender
+	Messages []TraceEntry
+}
+
+// SnapshotStream is what has been delivered from one sender.
+type SnapshotStream struct {
+	Next    uint64   // next sequence number expected
+	Pending []uint64 // received out of order and waiting for a gap to fill
+	GapSeen time.Time
+}
+
+// takeSnapshot collects the session state. It takes every lock it reads
+// under, so it must not run inside a message handler.
+func takeSnapshot(reason string) Snapshot {
+	mu.Lock()
+	snap := Snapshot{Time: time.Now().UTC(), Reason: reason, GameID: gameID, Player: playerID, Session: clientID, State: currentState(), Link: linkStatus()}
+	mu.Unlock()
+
+	arqSendMu.Lock()
+	snap.Seq = arqSeq
+	for seq := range arqOutbox {
+		snap.Outbox = append(snap.Outbox, seq)
+	}
+	arqSendMu.Unlock()
+	slices.Sort(snap.Outbox)
+
+	arqRecvMu.Lock()
+	snap.Streams = map[string]SnapshotStream{}
+	for sender, s := range arqStreams {
+		stream := SnapshotStream{Next: s.next, GapSeen: s.gapSeen}
+		for seq := range s.pending {
+			stream.Pending = append(stream.Pending, seq)
+		}
+		slices.Sort(stream.Pending)
+		snap.Streams[sender] = stream
+	}
+	arqRecvMu.Unlock()
+
+	traceMu.Lock()
+	snap.Messages = slices.Clone(recentMessages)
+	traceMu.Unlock()
+	return snap
+}
+
+// saveSnapshot writes a snapshot to ~/.gobblet/snapshots.
+func saveSnapshot(reason string) {
+	snap := takeSnapshot(reason)
+	dir := filepath.Join(gobbletDir(), "snapshots")
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", gameID, snap.Time.Format("20060102-150405.000")))
+	data, _ := json.MarshalIndent(snap, "", "  ")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		fmt.Println("⚠ Could not save snapshot:", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		fmt.Println("⚠ Could not save snapshot:", err)
+		return
+	}
+	fmt.Println("\n📸 Saved session snapshot to", path)
+}
+
+// replaySnapshot feeds a snapshot's messages to the normal handlers, then
+// prints the state the session had when it was taken for comparison.
+func replaySnapshot(path string, speed float64) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal("❌ Could not open snapshot:", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		log.Fatal("❌ Not a session snapshot:", err)
+	}
+	fmt.Printf("📸 Snapshot of game %s, Player %d, %s: %s\n", snap.GameID, snap.Player, snap.Time.Format(time.RFC3339), snap.Reason)
+	var last time.Time
+	for _, entry := range snap.Messages {
+		replayEntry(entry, last, speed)
+		last = entry.Time
+	}
+	fmt.Printf("\n📸 At the snapshot: move %d, Player %d to move, link %s\n", snap.State.Moves, snap.State.PlayerTurn, snap.Link)
+	board = snap.State.Board
+	printBoard()
+}
@@ -0,0 +1,48 @@
+package main
+
+import "time"
+
+// spectatorFrameInterval caps how often a spectator's board is redrawn.
+// Bot games can publish many states a second; drawing each one would back
+// up the MQTT handler, so bursts collapse into the newest state.
+const spectatorFrameInterval = 100 * time.Millisecond
+
+// spectatorRedraw holds at most one pending redraw.
+var spectatorRedraw = make(chan struct{}, 1)
+
+// requestRedraw asks the spectator view to draw the latest state. It never
+// blocks, so the message handler keeps up however slow the terminal is.
+func requestRedraw() {
+	select {
+	case spectatorRedraw <- struct{}{}:
+	default:
+	}
+}
+
+// staleState reports whether a received state is older than the one we
+// show. The move history only grows, even across takebacks, so a shorter
+// one is an out-of-order message from another sender.
+func staleState(state GameState) bool {
+	return len(state.History) < len(history)
+}
+
+// catchUp asks the players for the latest state. The retained state a
+// spectator joins with may be a snapshot several move deltas old, and
+// without a new move nothing would tell them.
+func catchUp() {
+	mu.Lock()
+	defer mu.Unlock()
+	requestResync()
+}
+
+// runSpectatorView redraws the board whenever new states arrive. It draws
+// under the game lock, so a frame never mixes two states.
+func runSpectatorView() {
+	catchUp()
+	for range spectatorRedraw {
+		mu.Lock()
+		printBoard()
+		mu.Unlock()
+		time.Sleep(spectatorFrameInterval)
+	}
+}
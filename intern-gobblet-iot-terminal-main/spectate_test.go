@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestCatchUpRequestsResync(t *testing.T) {
+	c := useRecordingClient(t)
+	old := moveCount
+	t.Cleanup(func() { moveCount = old })
+	moveCount = 7
+
+	catchUp()
+	if len(c.published) != 1 || c.published[0].Topic() != movesTopic() {
+		t.Fatalf("published %d messages", len(c.published))
+	}
+	var mm MoveMessage
+	if err := decodeMove(c.published[0].Payload(), &mm); err != nil || mm.Type != deltaResync || mm.Seq != 7 {
+		t.Errorf("sent %+v, %v; want a resync from move 7", mm, err)
+	}
+}
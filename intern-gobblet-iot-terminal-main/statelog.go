@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goblets/engine"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StateLogEntry is one received game state in the local audit log. Each
+// entry's hash covers the previous hash, so the log forms a chain that
+// cannot be edited without changing its head hash.
+type StateLogEntry struct {
+	Time  time.Time
+	State json.RawMessage `json:",omitempty"`
+	Data  []byte          `json:",omitempty"` // a protobuf or CBOR state, as received
+	Hash  string
+}
+
+// payload is the logged state as it was received.
+func (e StateLogEntry) payload() []byte {
+	if len(e.State) > 0 {
+		return e.State
+	}
+	return e.Data
+}
+
+// gameState decodes the logged state.
+func (e StateLogEntry) gameState() (GameState, error) {
+	var state GameState
+	err := decodeState(e.payload(), &state)
+	return state, err
+}
+
+var lastStateHash string
+
+func stateLogPath(id string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".gobblet", "audit", id+".jsonl")
+}
+
+func chainHash(prev string, state []byte) string {
+	sum := sha256.Sum256(append([]byte(prev), state...))
+	return hex.EncodeToString(sum[:])
+}
+
+// logDeltaState records the game state a move delta led to, as no full
+// state arrives for it.
+func logDeltaState() {
+	appendStateLog(encodeState(currentState()))
+}
+
+// appendStateLog records a received game state in the local audit log.
+func appendStateLog(payload []byte) {
+	path := stateLogPath(gameID)
+	if lastStateHash == "" {
+		if entries, err := readStateLog(gameID); err == nil && len(entries) > 0 {
+			lastStateHash = entries[len(entries)-1].Hash
+		}
+	}
+
+	entry := StateLogEntry{Time: time.Now().UTC(), Data: payload}
+	if jsonPayload(payload) {
+		entry = StateLogEntry{Time: entry.Time, State: payload}
+	}
+	entry.Hash = chainHash(lastStateHash, payload)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		fmt.Println("⚠ Could not write audit log:", err)
+		return
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		fmt.Println("⚠ Could not write audit log:", err)
+		return
+	}
+	defer f.Close()
+	data, _ := json.Marshal(entry)
+	f.Write(append(data, '\n'))
+	lastStateHash = entry.Hash
+}
+
+// readStateLog returns the local audit log of a game.
+func readStateLog(id string) ([]StateLogEntry, error) {
+	f, err := os.Open(stateLogPath(id))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []StateLogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		var e StateLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// verifyStateLogChain recomputes every hash in the log.
+func verifyStateLogChain(entries []StateLogEntry) error {
+	prev := ""
+	for i, e := range entries {
+		if chainHash(prev, e.payload()) != e.Hash {
+			return fmt.Errorf("audit log entry %d has been altered", i)
+		}
+		prev = e.Hash
+	}
+	return nil
+}
+
+// verifyMoveChain checks that every change between consecutive logged
+// states is a single legal placement or move by the player on turn.
+func verifyMoveChain(entries []StateLogEntry) error {
+	var prev *GameState
+	for i, e := range entries {
+		state, err := e.gameState()
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		if prev != nil {
+			if err := checkTransition(prev.Board, state.Board, prev.PlayerTurn); err != nil {
+				return fmt.Errorf("entry %d: %w", i, err)
+			}
+		}
+		prev = &state
+	}
+	return nil
+}
+
+// checkProgress verifies that a received state follows from the game we
+// hold: the same board at the same move count, one legal move on, or, when
+// moves were missed, the moves in its history replayed from our board.
+// States that take moves back are left to the takeback exchange.
+func checkProgress(state GameState) error {
+	next := boardOf(state)
+	switch {
+	case state.Moves < moveCount:
+		return nil
+	case state.Moves == moveCount:
+		if !engine.Equal(board, next) {
+			return errors.New("the board changed without a move")
+		}
+		return nil
+	case state.Moves == moveCount+1 && playerTurn != 0:
+		return checkTransition(board, next, playerTurn)
+	}
+
+	moves := activeMoves(state.History)
+	if len(moves) != state.Moves {
+		return fmt.Errorf("the state jumps from move %d to %d without the moves in between", moveCount, state.Moves)
+	}
+	if ours := activeMoves(history); len(ours) == moveCount {
+		for i, e := range ours {
+			if e.Player != moves[i].Player || e.Move != moves[i].Move {
+				return fmt.Errorf("move %d differs from the one we hold", i+1)
+			}
+		}
+	}
+	b, turn := board, playerTurn
+	for i, e := range moves[moveCount:] {
+		if turn != 0 && e.Player != turn {
+			return fmt.Errorf("move %d was played by Player %d on Player %d's turn", moveCount+i+1, e.Player, turn)
+		}
+		after, err := position(b, e.Player).Apply(e.Move)
+		if err != nil {
+			return fmt.Errorf("move %d: %w", moveCount+i+1, err)
+		}
+		b, turn = after.Board, after.Turn
+	}
+	if !engine.Equal(b, next) {
+		return errors.New("the moves in its history don't lead to its board")
+	}
+	return nil
+}
+
+// checkTransition verifies that next follows from prev by one legal action
+// of player. An unchanged board is allowed (turn hand-over messages).
+func checkTransition(prev, next Board, player int) error {
+	if engine.Equal(prev, next) {
+		return nil
+	}
+	state := position(prev, player)
+	for _, m := range engine.LegalMoves(state, player) {
+		if after, err := state.Apply(m); err == nil && engine.Equal(after.Board, next) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no legal move by player %d leads to this position", player)
+}
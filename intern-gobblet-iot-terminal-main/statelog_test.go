@@ -0,0 +1,67 @@
+package main
+
+import (
+	"goblets/engine"
+	"testing"
+)
+
+// playedState plays moves from an empty board and returns the state a
+// terminal would publish after them.
+func playedState(t *testing.T, moves ...engine.Move) GameState {
+	t.Helper()
+	g := engine.Game{State: engine.State{Board: engine.NewBoard(3), Turn: 1}}
+	var h []HistoryEntry
+	for _, m := range moves {
+		h = append(h, HistoryEntry{Player: g.Turn, Move: m})
+		var err error
+		if g, err = g.Play(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return GameState{Board: g.Board, PlayerTurn: g.Turn, Moves: g.Moves, History: h}
+}
+
+func TestCheckProgress(t *testing.T) {
+	oldBoard, oldTurn, oldCount, oldHistory := board, playerTurn, moveCount, history
+	t.Cleanup(func() { board, playerTurn, moveCount, history = oldBoard, oldTurn, oldCount, oldHistory })
+
+	opening := []engine.Move{engine.Place(1, 1, 2), engine.Place(0, 0, 1)}
+	held := playedState(t, opening...)
+	board, playerTurn, moveCount, history = held.Board, held.PlayerTurn, held.Moves, held.History
+
+	legal := playedState(t, append(opening, engine.Place(2, 2, 3))...)
+	jump := playedState(t, append(opening, engine.Place(2, 2, 3), engine.Shift(0, 0, 0, 1), engine.Place(0, 0, 3))...)
+
+	tampered := playedState(t, opening...)
+	tampered.Board[2][2] = engine.Stack{{Size: 3, Owner: 1}}
+
+	forgedJump := jump
+	forgedJump.Board = jump.Board.Clone()
+	forgedJump.Board[2][0] = engine.Stack{{Size: 1, Owner: 2}}
+
+	noHistory := jump
+	noHistory.History = nil
+
+	wrongTurn := playedState(t, append(opening, engine.Place(2, 2, 3))...)
+	wrongTurn.Moves, wrongTurn.History = 4, append(wrongTurn.History, HistoryEntry{Player: 1, Move: engine.Place(2, 0, 1)})
+	wrongTurn.Board = wrongTurn.Board.Clone()
+	wrongTurn.Board[2][0] = engine.Stack{{Size: 1, Owner: 1}}
+
+	for _, tc := range []struct {
+		name  string
+		state GameState
+		ok    bool
+	}{
+		{"echo of our state", held, true},
+		{"one legal move", legal, true},
+		{"missed moves replayed from the history", jump, true},
+		{"board changed at the same move count", tampered, false},
+		{"jump to a board the history doesn't reach", forgedJump, false},
+		{"jump without a history", noHistory, false},
+		{"jump with a player moving twice", wrongTurn, false},
+	} {
+		if err := checkProgress(tc.state); (err == nil) != tc.ok {
+			t.Errorf("%s: checkProgress() = %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goblets/engine"
+	"goblets/notation"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// HistoryEntry is one line of the append-only move history in GameState.
+// A takeback is recorded as an Undo entry instead of deleting the move.
+type HistoryEntry struct {
+	Player int
+	Move   engine.Move `json:",omitempty"`
+	Undo   bool        `json:",omitempty"`
+	Time   time.Time
+}
+
+// TakebackRequest asks the opponent to approve undoing the requester's last
+// move; the opponent answers with a TakebackReply.
+type TakebackRequest struct {
+	Player int
+	Moves  int // move count the request refers to
+}
+
+type TakebackReply struct {
+	Player   int // who asked
+	Accepted bool
+}
+
+var (
+	history         []HistoryEntry
+	takebackPending *TakebackRequest // opponent's request awaiting our answer
+	takebackAsked   bool             // our request awaiting the opponent
+)
+
+func takebackTopic() string {
+	return "gobblet/game/" + gameID + "/takeback"
+}
+
+// String formats the entry for move lists, e.g. "Player 1: P b2 L".
+func (e HistoryEntry) String() string {
+	if e.Undo {
+		return fmt.Sprintf("Player %d: takeback", e.Player)
+	}
+	return fmt.Sprintf("Player %d: %s", e.Player, notation.Format(e.Move))
+}
+
+// printHistory lists every move and takeback played so far.
+func printHistory() {
+	if memoryHidden() {
+		fmt.Println("\n❌ The move list shows hidden pieces, so it is off under the memory rule.")
+		return
+	}
+	if len(history) == 0 {
+		fmt.Println("\n📜 No moves yet.")
+		return
+	}
+	fmt.Println("\n📜 Moves:")
+	for i, e := range history {
+		fmt.Printf("  %2d. %s\n", i+1, e)
+	}
+}
+
+// recordHistory appends a played move to the history.
+func recordHistory(m engine.Move) {
+	history = append(history, HistoryEntry{Player: playerTurn, Move: m, Time: time.Now().UTC()})
+}
+
+// activeMoves replays the history, dropping moves that were taken back.
+func activeMoves(h []HistoryEntry) []HistoryEntry {
+	var moves []HistoryEntry
+	for _, e := range h {
+		if e.Undo {
+			if len(moves) > 0 {
+				moves = moves[:len(moves)-1]
+			}
+			continue
+		}
+		moves = append(moves, e)
+	}
+	return moves
+}
+
+// replayBoard rebuilds the board from a list of moves.
+func replayBoard(moves []HistoryEntry) (Board, error) {
+	b := engine.NewBoard(board.Size())
+	for _, e := range moves {
+		next, err := position(b, e.Player).Apply(e.Move)
+		if err != nil {
+			return b, err
+		}
+		b = next.Board
+	}
+	return b, nil
+}
+
+// takebacksAllowed reports whether this game permits takeback requests.
+func takebacksAllowed() bool {
+	return !gameMeta.Rated || gameMeta.Rules.Assists.Takebacks
+}
+
+// canUndo reports whether the local player's last move can be taken back.
+func canUndo() bool {
+	moves := activeMoves(history)
+	return takebacksAllowed() && gameResult == nil && !takebackAsked &&
+		len(moves) == moveCount && len(moves) > 0 && moves[len(moves)-1].Player == playerID
+}
+
+// undoneMove returns the move just taken back, if the local player can
+// replay it.
+func undoneMove() (engine.Move, bool) {
+	if len(history) == 0 || !history[len(history)-1].Undo || playerTurn != playerID {
+		return engine.Move{}, false
+	}
+	moves := activeMoves(history[:len(history)-1])
+	last := moves[len(moves)-1]
+	return last.Move, last.Player == playerID
+}
+
+// requestTakeback asks the opponent to approve undoing our last move.
+func requestTakeback() {
+	if !canUndo() {
+		fmt.Println("\n❌ Nothing to take back: you can only undo your own last move before your opponent replies.")
+		return
+	}
+	data, _ := json.Marshal(TakebackRequest{Player: playerID, Moves: moveCount})
+	mqttClient.Publish(takebackTopic(), 1, false, data)
+	takebackAsked = true
+	fmt.Println("\n↩ Takeback requested, waiting for your opponent to accept...")
+}
+
+// answerTakeback accepts or declines the opponent's pending request. On
+// accept the board is rolled back and published to both clients.
+func answerTakeback(accept bool) {
+	req := takebackPending
+	takebackPending = nil
+	if req == nil {
+		fmt.Println("❌ No takeback request to answer.")
+		return
+	}
+	if accept {
+		if err := rollBack(req.Player); err != nil {
+			fmt.Println("❌ Could not take back the move:", err)
+			accept = false
+		}
+	}
+	data, _ := json.Marshal(TakebackReply{Player: req.Player, Accepted: accept})
+	mqttClient.Publish(takebackTopic(), 1, false, data)
+	if !accept {
+		fmt.Println("🚫 Takeback declined.")
+	}
+}
+
+// rollBack undoes player's last move and publishes the rolled-back state.
+func rollBack(player int) error {
+	mu.Lock()
+	moves := activeMoves(history)
+	if len(moves) != moveCount || len(moves) == 0 || moves[len(moves)-1].Player != player {
+		mu.Unlock()
+		return errors.New("the move history does not match the board")
+	}
+	prev, err := replayBoard(moves[:len(moves)-1])
+	if err != nil {
+		mu.Unlock()
+		return err
+	}
+	if h := engine.RepetitionKey(board, playerTurn); positionCounts[h] > 0 {
+		positionCounts[h]--
+	}
+	history = append(history, HistoryEntry{Player: player, Undo: true, Time: time.Now().UTC()})
+	board = prev
+	moveCount--
+	playerTurn = player
+	mu.Unlock()
+
+	fmt.Printf("↩ Move taken back, Player %d to move again.\n", player)
+	saveGameState()
+	return nil
+}
+
+// onTakeback handles requests and replies from the opponent.
+func onTakeback(client mqtt.Client, msg mqtt.Message) {
+	var probe map[string]json.RawMessage
+	if json.Unmarshal(msg.Payload(), &probe) != nil {
+		return
+	}
+	if _, isReply := probe["Accepted"]; isReply {
+		var reply TakebackReply
+		json.Unmarshal(msg.Payload(), &reply)
+		if reply.Player != playerID {
+			return
+		}
+		takebackAsked = false
+		if reply.Accepted {
+			fmt.Println("\n✅ Takeback accepted.")
+		} else {
+			fmt.Println("\n🚫 Your opponent declined the takeback.")
+		}
+		return
+	}
+
+	var req TakebackRequest
+	json.Unmarshal(msg.Payload(), &req)
+	if req.Player == playerID || req.Player == 0 {
+		return
+	}
+	takebackPending = &req
+	fmt.Printf("\n↩ Player %d asks to take back their last move. Type 'accept' or 'decline'.\n", req.Player)
+}
+
+// watchTakebacks subscribes to takeback requests for the local seat.
+func watchTakebacks() {
+	if token := mqttClient.Subscribe(takebackTopic(), 1, onTakeback); token.Wait() && token.Error() != nil {
+		fmt.Println("⚠ Could not subscribe to takeback requests:", token.Error())
+	}
+}
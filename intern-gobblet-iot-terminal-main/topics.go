@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Channels of a game under gobblet/game/<id>, so the retained state never
+// shares a topic with ephemeral traffic.
+const (
+	channelState    = "state"    // the retained game state
+	channelMoves    = "moves"    // move deltas and resync requests
+	channelChat     = "chat"     // chat lines
+	channelPresence = "presence" // heartbeats and wills
+	channelControl  = "control"  // rejections and other game control
+	channelSeats    = "seats"    // the retained seating document
+)
+
+var gameChannels = []string{channelState, channelMoves, channelChat, channelPresence, channelControl}
+
+// gameTopic is the topic of channel in game id.
+func gameTopic(id, channel string) string {
+	return "gobblet/game/" + id + "/" + channel
+}
+
+func stateTopic() string {
+	return gameTopic(gameID, channelState)
+}
+
+// legacyGameTopic is the bare game topic where protocol 2 and older kept
+// the retained state, along with everything else.
+func legacyGameTopic(id string) string {
+	return "gobblet/game/" + id
+}
+
+// migrateLegacyState moves a game saved on the bare topic to its /state
+// channel and clears the old retained message. It reports whether there
+// was one.
+func migrateLegacyState() bool {
+	found := make(chan []byte, 1)
+	topic := legacyGameTopic(gameID)
+	token := mqttClient.Subscribe(topic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		if !msg.Retained() {
+			return
+		}
+		select {
+		case found <- unwrapPayload(msg.Payload()):
+		default:
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		return false
+	}
+	defer mqttClient.Unsubscribe(topic)
+
+	select {
+	case data := <-found:
+		var state GameState
+		if err := decodeState(data, &state); err != nil {
+			fmt.Println("⚠ Ignored an unreadable game on", topic+":", err)
+			return false
+		}
+		if token := publishPayload(stateTopic(), 1, true, data); token.Wait() && token.Error() != nil {
+			fmt.Println("❌ Could not move the saved game:", token.Error())
+			return false
+		}
+		mqttClient.Publish(topic, 1, true, []byte{}).Wait()
+		fmt.Println("🔁 Moved the saved game from", topic, "to", stateTopic())
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+// topicGameID returns the game ID of a topic under gobblet/game/.
+func topicGameID(topic string) string {
+	parts := strings.Split(topic, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+var (
+	routesMu sync.Mutex
+	routes   = map[string][]mqtt.MessageHandler{} // channel -> handlers
+)
+
+// route has the dispatcher pass messages on channel of the current game to
+// h, alongside any handlers already there.
+func route(channel string, h mqtt.MessageHandler) {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	routes[channel] = append(routes[channel], h)
+}
+
+// topicChannel returns the channel of a game topic, ignoring a codec
+// suffix.
+func topicChannel(topic string) string {
+	return path.Base(strings.TrimSuffix(topic, "/"+wireCBOR))
+}
+
+// dispatch hands a message on one of the game's channels to its handlers.
+func dispatch(client mqtt.Client, msg mqtt.Message) {
+	routesMu.Lock()
+	handlers := routes[topicChannel(msg.Topic())]
+	routesMu.Unlock()
+	for _, h := range handlers {
+		h(client, msg)
+	}
+}
+
+// subscribeChannels subscribes the dispatcher to every channel of the
+// current game.
+func subscribeChannels() mqtt.Token {
+	filters := map[string]byte{}
+	for _, channel := range gameChannels {
+		filters[gameTopic(gameID, channel)] = 1
+	}
+	filters[movesTopic()+"/"+wireCBOR] = 1 // CBOR moves over MQTT 3.1.1
+	return mqttClient.SubscribeMultiple(filters, dispatch)
+}
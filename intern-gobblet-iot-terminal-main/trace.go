@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"goblets/config"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// TraceEntry is one received MQTT message in a trace file (JSON lines).
+type TraceEntry struct {
+	Time    time.Time
+	Topic   string
+	Payload string
+	Binary  []byte `json:",omitempty"` // a protobuf or CBOR payload instead, which isn't text
+}
+
+var (
+	traceMu   sync.Mutex
+	traceFile *os.File
+)
+
+// traced wraps a handler so every message it receives is kept for session
+// snapshots and appended to the configured trace file.
+func traced(handler mqtt.MessageHandler) mqtt.MessageHandler {
+	return func(client mqtt.Client, msg mqtt.Message) {
+		recordTrace(msg.Topic(), msg.Payload())
+		handler(client, msg)
+	}
+}
+
+func recordTrace(topic string, payload []byte) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+
+	entry := TraceEntry{Time: time.Now(), Topic: topic, Payload: string(payload)}
+	if !utf8.Valid(payload) {
+		entry = TraceEntry{Time: entry.Time, Topic: topic, Binary: payload}
+	}
+	recentMessages = append(recentMessages, entry)
+	if len(recentMessages) > snapshotMessages {
+		recentMessages = recentMessages[1:]
+	}
+	if config.Conf.TraceFile == "" {
+		return
+	}
+	if traceFile == nil {
+		f, err := os.OpenFile(config.Conf.TraceFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			fmt.Println("⚠ Could not open trace file:", err)
+			config.Conf.TraceFile = ""
+			return
+		}
+		traceFile = f
+	}
+	data, _ := json.Marshal(entry)
+	traceFile.Write(append(data, '\n'))
+}
+
+// traceMessage feeds a recorded payload to the normal handlers.
+type traceMessage struct {
+	entry TraceEntry
+}
+
+func (m traceMessage) Duplicate() bool   { return false }
+func (m traceMessage) Qos() byte         { return 1 }
+func (m traceMessage) Retained() bool    { return false }
+func (m traceMessage) Topic() string     { return m.entry.Topic }
+func (m traceMessage) MessageID() uint16 { return 0 }
+func (m traceMessage) Ack()              {}
+
+func (m traceMessage) Payload() []byte {
+	if m.entry.Binary != nil {
+		return m.entry.Binary
+	}
+	return []byte(m.entry.Payload)
+}
+
+// runPlayback replays a captured trace into the client's receive path, so
+// renderer changes can be tried without a broker or an opponent.
+func runPlayback(args []string) {
+	fs := flag.NewFlagSet("playback", flag.ExitOnError)
+	speed := fs.Float64("speed", 1, "playback speed multiplier (0 = no delays)")
+	fs.IntVar(&playerID, "player", spectatorID, "player number whose view to render")
+	snapshot := fs.Bool("snapshot", false, "replay the messages kept in a session snapshot")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: playback [--speed N] [--player N] [--snapshot] <trace.jsonl or snapshot.json>")
+		os.Exit(1)
+	}
+	if *snapshot {
+		replaySnapshot(fs.Arg(0), *speed)
+		return
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatal("❌ Could not open trace:", err)
+	}
+	defer f.Close()
+
+	var last time.Time
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry TraceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			fmt.Println("⚠ Skipping malformed trace line:", err)
+			continue
+		}
+		replayEntry(entry, last, *speed)
+		last = entry.Time
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal("❌ Error reading trace:", err)
+	}
+}
+
+// replayEntry waits out the recorded gap since last, scaled by speed, and
+// feeds entry to the handler for its topic.
+func replayEntry(entry TraceEntry, last time.Time, speed float64) {
+	if !last.IsZero() && speed > 0 {
+		time.Sleep(time.Duration(float64(entry.Time.Sub(last)) / speed))
+	}
+	parts := strings.Split(entry.Topic, "/")
+	if len(parts) >= 3 {
+		gameID = parts[2]
+	}
+	msg := traceMessage{entry: entry}
+	switch {
+	case strings.HasSuffix(entry.Topic, "/audit"):
+		onAuditReceived(nil, msg)
+	case strings.HasSuffix(entry.Topic, "/moves"):
+		onMoveReceived(nil, msg)
+	case strings.HasPrefix(entry.Topic, "gobblet/game/"):
+		onMessageReceived(nil, msg)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"goblets/config"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	clientVersion   = "0.3.0"
+	protocolVersion = 3 // 2: moves travel as deltas on the moves topic; 3: game traffic split into channels
+	seqProtocol     = 3 // the first protocol whose game states carry a Seq
+	manifestTopic   = "gobblet/manifest"
+)
+
+// releaseManifest is published by the broker operators (as a retained
+// message or a static JSON file) to announce releases and protocol sunsets.
+type releaseManifest struct {
+	LatestVersion       string `json:"latest_version"`
+	ReleaseURL          string `json:"release_url"`
+	DeprecatedProtocols []int  `json:"deprecated_protocols"`
+	SunsetDate          string `json:"sunset_date"`
+}
+
+func fetchManifestHTTP(url string) (*releaseManifest, error) {
+	client := http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("manifest request failed: %s", resp.Status)
+	}
+	var m releaseManifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func fetchManifestMQTT() (*releaseManifest, error) {
+	manifestChan := make(chan releaseManifest, 1)
+	token := mqttClient.Subscribe(manifestTopic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		var m releaseManifest
+		if err := json.Unmarshal(msg.Payload(), &m); err != nil {
+			return
+		}
+		select {
+		case manifestChan <- m:
+		default:
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	defer mqttClient.Unsubscribe(manifestTopic)
+
+	select {
+	case m := <-manifestChan:
+		return &m, nil
+	case <-time.After(2 * time.Second):
+		return nil, nil // no manifest published, nothing to report
+	}
+}
+
+// checkForUpdates warns when a newer release exists or when this client's
+// protocol version is being retired on the shared broker.
+func checkForUpdates() {
+	if !config.Conf.UpdateCheck.Enabled || budgetMode() {
+		return
+	}
+
+	var m *releaseManifest
+	var err error
+	if config.Conf.UpdateCheck.ManifestURL != "" {
+		m, err = fetchManifestHTTP(config.Conf.UpdateCheck.ManifestURL)
+	} else {
+		m, err = fetchManifestMQTT()
+	}
+	if err != nil {
+		fmt.Println("⚠ Update check failed:", err)
+		return
+	}
+	if m == nil {
+		return
+	}
+
+	if slices.Contains(m.DeprecatedProtocols, protocolVersion) {
+		fmt.Printf("⚠ Protocol version %d used by this client is deprecated on this broker", protocolVersion)
+		if m.SunsetDate != "" {
+			fmt.Printf(" and stops working on %s", m.SunsetDate)
+		}
+		fmt.Println(".")
+		fmt.Println("⬆ Please upgrade:", m.ReleaseURL)
+	} else if newerVersion(m.LatestVersion, clientVersion) {
+		fmt.Printf("⬆ Version %s is available (you have %s): %s\n", m.LatestVersion, clientVersion, m.ReleaseURL)
+	}
+}
+
+// newerVersion reports whether semantic version a is newer than b. A
+// leading "v" is ignored, and a pre-release such as 1.0.0-rc.1 comes before
+// its release. Versions that don't parse are never newer.
+func newerVersion(a, b string) bool {
+	x, okA := parseVersion(a)
+	y, okB := parseVersion(b)
+	if !okA || !okB {
+		return false
+	}
+	for i := range 3 {
+		if x.core[i] != y.core[i] {
+			return x.core[i] > y.core[i]
+		}
+	}
+	return comparePrerelease(x.pre, y.pre) > 0
+}
+
+type version struct {
+	core [3]int
+	pre  string
+}
+
+func parseVersion(v string) (version, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+") // build metadata doesn't order
+	core, pre, _ := strings.Cut(v, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return version{}, false
+	}
+	var out version
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		out.core[i] = n
+	}
+	out.pre = pre
+	return out, true
+}
+
+// comparePrerelease orders pre-release tags as semver does: none is
+// highest, then identifiers compare in turn, numbers below words.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	x, y := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(x) && i < len(y); i++ {
+		m, errM := strconv.Atoi(x[i])
+		n, errN := strconv.Atoi(y[i])
+		switch {
+		case errM == nil && errN == nil && m != n:
+			return m - n
+		case errM == nil && errN != nil:
+			return -1
+		case errM != nil && errN == nil:
+			return 1
+		case errM != nil && errN != nil && x[i] != y[i]:
+			return strings.Compare(x[i], y[i])
+		}
+	}
+	return len(x) - len(y)
+}
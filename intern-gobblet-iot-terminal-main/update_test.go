@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestNewerVersion(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"0.4.0", "0.3.0", true},
+		{"0.3.0", "0.3.0", false},
+		{"0.2.9", "0.3.0", false},
+		{"0.10.0", "0.9.0", true},
+		{"v1.0.0", "0.3.0", true},
+		{"1.0.0", "1.0.0-rc.1", true},
+		{"1.0.0-rc.1", "1.0.0", false},
+		{"1.0.0-rc.2", "1.0.0-rc.1", true},
+		{"1.0.0-rc.10", "1.0.0-rc.9", true},
+		{"1.0.0-beta", "1.0.0-alpha", true},
+		{"1.0.0-alpha.1", "1.0.0-alpha", true},
+		{"1.0.0+build.5", "1.0.0", false},
+		{"latest", "0.3.0", false},
+		{"", "0.3.0", false},
+	} {
+		if got := newerVersion(tc.a, tc.b); got != tc.want {
+			t.Errorf("newerVersion(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// CBOR (RFC 8949) carries the same documents as the JSON payloads, with
+// the same keys, so a board without a JSON library can read and write
+// them. Values go through their JSON form: time stamps stay RFC 3339
+// strings, and byte strings received from a board fill []byte fields.
+
+const maxCBORDepth = 64
+
+var errCBORBreak = errors.New("cbor: unexpected break")
+
+// MarshalCBOR encodes v as CBOR, with map keys in sorted order.
+func MarshalCBOR(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return appendCBOR(nil, doc)
+}
+
+// UnmarshalCBOR decodes CBOR data into v, as encoding/json would decode
+// the same document.
+func UnmarshalCBOR(data []byte, v any) error {
+	d := cborDecoder{data: data}
+	doc, err := d.value(0)
+	if err != nil {
+		return err
+	}
+	if d.pos != len(data) {
+		return errors.New("cbor: trailing data")
+	}
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, v)
+}
+
+// IsCBORMap reports whether data starts with a CBOR map, which every
+// CBOR payload does. JSON starts with '{' and protobuf messages with a
+// field tag below 0xa0, as long as fields stay numbered below 20.
+func IsCBORMap(data []byte) bool {
+	return len(data) > 0 && data[0]>>5 == 5
+}
+
+func appendHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), n)
+}
+
+func appendCBOR(b []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xf6), nil
+	case bool:
+		if v {
+			return append(b, 0xf5), nil
+		}
+		return append(b, 0xf4), nil
+	case json.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			if n < 0 {
+				return appendHead(b, 1, uint64(-1-n)), nil
+			}
+			return appendHead(b, 0, uint64(n)), nil
+		}
+		if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return appendHead(b, 0, n), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return binary.BigEndian.AppendUint64(append(b, 0xfb), math.Float64bits(f)), nil
+	case string:
+		return append(appendHead(b, 3, uint64(len(v))), v...), nil
+	case []any:
+		b = appendHead(b, 4, uint64(len(v)))
+		for _, item := range v {
+			var err error
+			if b, err = appendCBOR(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		b = appendHead(b, 5, uint64(len(v)))
+		for _, k := range keys {
+			b = append(appendHead(b, 3, uint64(len(k))), k...)
+			var err error
+			if b, err = appendCBOR(b, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("cbor: can't encode %T", v)
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errors.New("cbor: unexpected end of data")
+	}
+	d.pos++
+	return d.data[d.pos-1], nil
+}
+
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errors.New("cbor: unexpected end of data")
+	}
+	d.pos += int(n)
+	return d.data[d.pos-int(n) : d.pos], nil
+}
+
+// head reads an item's major type, additional information and argument.
+// Information 31 marks an indefinite length, or the break ending one.
+func (d *cborDecoder) head() (major, info byte, n uint64, err error) {
+	c, err := d.byte()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = c>>5, c&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		b, err := d.bytes(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		for _, x := range b {
+			n = n<<8 | uint64(x)
+		}
+		return major, info, n, nil
+	case info == 31:
+		return major, info, 0, nil
+	}
+	return 0, 0, 0, fmt.Errorf("cbor: reserved additional information %d", info)
+}
+
+func (d *cborDecoder) value(depth int) (any, error) {
+	if depth > maxCBORDepth {
+		return nil, errors.New("cbor: nested too deeply")
+	}
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	indefinite := info == 31
+	switch major {
+	case 0:
+		return json.Number(strconv.FormatUint(n, 10)), nil
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer out of range")
+		}
+		return json.Number(strconv.FormatInt(-1-int64(n), 10)), nil
+	case 2, 3:
+		s, err := d.str(major, n, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if major == 2 {
+			return s, nil // []byte, base64 in its JSON form
+		}
+		return string(s), nil
+	case 4:
+		list := []any{}
+		for i := uint64(0); indefinite || i < n; i++ {
+			item, err := d.value(depth + 1)
+			if err == errCBORBreak && indefinite {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	case 5:
+		m := map[string]any{}
+		for i := uint64(0); indefinite || i < n; i++ {
+			k, err := d.value(depth + 1)
+			if err == errCBORBreak && indefinite {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, errors.New("cbor: map key is not a text string")
+			}
+			if m[key], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case 6:
+		return d.value(depth + 1) // tags add nothing the JSON form can keep
+	}
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return cborFloat(halfFloat(uint16(n)))
+	case 26:
+		return cborFloat(float64(math.Float32frombits(uint32(n))))
+	case 27:
+		return cborFloat(math.Float64frombits(n))
+	case 31:
+		return nil, errCBORBreak
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", n)
+}
+
+// str reads a byte or text string, joining the chunks of an indefinite
+// one.
+func (d *cborDecoder) str(major byte, n uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		return d.bytes(n)
+	}
+	var s []byte
+	for {
+		m, info, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m == 7 && info == 31 {
+			return s, nil
+		}
+		if m != major || info == 31 {
+			return nil, errors.New("cbor: bad chunk in indefinite-length string")
+		}
+		chunk, err := d.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, chunk...)
+	}
+}
+
+func cborFloat(f float64) (any, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, errors.New("cbor: NaN and infinity have no JSON form")
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+}
+
+// halfFloat converts an IEEE 754 half-precision float.
+func halfFloat(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1f, float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		f = math.Inf(1)
+		if mant != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
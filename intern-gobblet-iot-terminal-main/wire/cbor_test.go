@@ -0,0 +1,102 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type cborDoc struct {
+	Type   string
+	Seq    int
+	Neg    int64
+	Big    uint64
+	Ratio  float64
+	Ok     bool
+	Skip   *int `json:",omitempty"`
+	Data   []byte
+	Time   time.Time
+	Counts [][]int
+	Names  map[string]int
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	want := cborDoc{
+		Type: "move", Seq: 300, Neg: -70000, Big: 1 << 63, Ratio: 0.25, Ok: true,
+		Data: []byte{0, 1, 0xff}, Time: time.Date(2026, 3, 4, 5, 6, 7, 8, time.UTC),
+		Counts: [][]int{{1, 2}, {}}, Names: map[string]int{"a": 1, "b": -1},
+	}
+	data, err := MarshalCBOR(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsCBORMap(data) {
+		t.Errorf("encoding starts with %#x, want a map", data[0])
+	}
+	var got cborDoc
+	if err := UnmarshalCBOR(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMarshalCBORDeterministic(t *testing.T) {
+	// {"A":1,"B":[true,null]}: keys sorted, shortest heads
+	data, err := MarshalCBOR(map[string]any{"B": []any{true, nil}, "A": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := hex.DecodeString("a2614101614282f5f6"); !bytes.Equal(data, want) {
+		t.Errorf("got %x, want %x", data, want)
+	}
+}
+
+func TestUnmarshalCBORFromBoards(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+		want cborDoc
+	}{
+		// {_ "Type": (_ "a" "ck"), "Seq": 7, "Ratio": 2.0 as a half float}
+		{"indefinite lengths", "bf6454797065" + "7f616162636bff" + "63536571" + "07" + "65526174696f" + "f94000" + "ff",
+			cborDoc{Type: "ack", Seq: 7, Ratio: 2}},
+		{"single float and tag", "a2" + "65526174696f" + "fa3fc00000" + "63536571" + "c11864",
+			cborDoc{Ratio: 1.5, Seq: 100}},
+		{"byte string", "a1" + "6444617461" + "43010203", cborDoc{Data: []byte{1, 2, 3}}},
+	}
+	for _, tt := range tests {
+		raw, _ := hex.DecodeString(tt.hex)
+		var got cborDoc
+		if err := UnmarshalCBOR(raw, &got); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalCBORInvalid(t *testing.T) {
+	for name, h := range map[string]string{
+		"empty":            "",
+		"truncated string": "a16154",
+		"truncated head":   "a1615419",
+		"trailing data":    "a000",
+		"integer key":      "a10101",
+		"stray break":      "a161546474797065ff",
+		"NaN":              "a1615af97e00",
+		"reserved info":    "1c",
+		"too deep":         string(bytes.Repeat([]byte("81"), 100)) + "00",
+	} {
+		raw, _ := hex.DecodeString(h)
+		var v any
+		if err := UnmarshalCBOR(raw, &v); err == nil {
+			t.Errorf("%s: decoded %x without an error", name, raw)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+// Package wire holds the payload encodings besides JSON: the protobuf
+// schema of game states and move deltas with the Go types generated from
+// it, and CBOR. It also has the helpers services use to edit JSON states
+// without the terminal's types.
+package wire
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative ../wire/gobblet.proto
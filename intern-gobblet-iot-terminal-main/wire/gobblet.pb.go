@@ -0,0 +1,1721 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.1
+// 	protoc        (unknown)
+// source: wire/gobblet.proto
+
+package wire
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Piece is one gobblet; owner is the seat, 1 or 2.
+type Piece struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          int32                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Owner         int32                  `protobuf:"varint,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Piece) Reset() {
+	*x = Piece{}
+	mi := &file_wire_gobblet_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Piece) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Piece) ProtoMessage() {}
+
+func (x *Piece) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Piece.ProtoReflect.Descriptor instead.
+func (*Piece) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Piece) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Piece) GetOwner() int32 {
+	if x != nil {
+		return x.Owner
+	}
+	return 0
+}
+
+// Stack is the pile on one cell, bottom to top.
+type Stack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pieces        []*Piece               `protobuf:"bytes,1,rep,name=pieces,proto3" json:"pieces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stack) Reset() {
+	*x = Stack{}
+	mi := &file_wire_gobblet_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stack) ProtoMessage() {}
+
+func (x *Stack) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stack.ProtoReflect.Descriptor instead.
+func (*Stack) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Stack) GetPieces() []*Piece {
+	if x != nil {
+		return x.Pieces
+	}
+	return nil
+}
+
+// Row is one board row, left to right.
+type Row struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cells         []*Stack               `protobuf:"bytes,1,rep,name=cells,proto3" json:"cells,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	mi := &file_wire_gobblet_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Row) GetCells() []*Stack {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+// Move is a placement (from_row and from_col -1, size set) or a move of a
+// piece on the board.
+type Move struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromRow       int32                  `protobuf:"varint,1,opt,name=from_row,json=fromRow,proto3" json:"from_row,omitempty"`
+	FromCol       int32                  `protobuf:"varint,2,opt,name=from_col,json=fromCol,proto3" json:"from_col,omitempty"`
+	Row           int32                  `protobuf:"varint,3,opt,name=row,proto3" json:"row,omitempty"`
+	Col           int32                  `protobuf:"varint,4,opt,name=col,proto3" json:"col,omitempty"`
+	Size          int32                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Move) Reset() {
+	*x = Move{}
+	mi := &file_wire_gobblet_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Move) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Move) ProtoMessage() {}
+
+func (x *Move) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Move.ProtoReflect.Descriptor instead.
+func (*Move) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Move) GetFromRow() int32 {
+	if x != nil {
+		return x.FromRow
+	}
+	return 0
+}
+
+func (x *Move) GetFromCol() int32 {
+	if x != nil {
+		return x.FromCol
+	}
+	return 0
+}
+
+func (x *Move) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *Move) GetCol() int32 {
+	if x != nil {
+		return x.Col
+	}
+	return 0
+}
+
+func (x *Move) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type HistoryEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Player        int32                  `protobuf:"varint,1,opt,name=player,proto3" json:"player,omitempty"`
+	Move          *Move                  `protobuf:"bytes,2,opt,name=move,proto3" json:"move,omitempty"`
+	Undo          bool                   `protobuf:"varint,3,opt,name=undo,proto3" json:"undo,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryEntry) Reset() {
+	*x = HistoryEntry{}
+	mi := &file_wire_gobblet_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryEntry) ProtoMessage() {}
+
+func (x *HistoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryEntry.ProtoReflect.Descriptor instead.
+func (*HistoryEntry) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HistoryEntry) GetPlayer() int32 {
+	if x != nil {
+		return x.Player
+	}
+	return 0
+}
+
+func (x *HistoryEntry) GetMove() *Move {
+	if x != nil {
+		return x.Move
+	}
+	return nil
+}
+
+func (x *HistoryEntry) GetUndo() bool {
+	if x != nil {
+		return x.Undo
+	}
+	return false
+}
+
+func (x *HistoryEntry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type Result struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Outcome       string                 `protobuf:"bytes,1,opt,name=outcome,proto3" json:"outcome,omitempty"` // "1-0", "0-1" or "½-½"
+	Termination   string                 `protobuf:"bytes,2,opt,name=termination,proto3" json:"termination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_wire_gobblet_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Result) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *Result) GetTermination() string {
+	if x != nil {
+		return x.Termination
+	}
+	return ""
+}
+
+type Clocks struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Remaining     []*durationpb.Duration `protobuf:"bytes,1,rep,name=remaining,proto3" json:"remaining,omitempty"` // player 1, player 2
+	TurnStart     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=turn_start,json=turnStart,proto3" json:"turn_start,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Clocks) Reset() {
+	*x = Clocks{}
+	mi := &file_wire_gobblet_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Clocks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Clocks) ProtoMessage() {}
+
+func (x *Clocks) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Clocks.ProtoReflect.Descriptor instead.
+func (*Clocks) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Clocks) GetRemaining() []*durationpb.Duration {
+	if x != nil {
+		return x.Remaining
+	}
+	return nil
+}
+
+func (x *Clocks) GetTurnStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TurnStart
+	}
+	return nil
+}
+
+type Control struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	Player        int32                  `protobuf:"varint,2,opt,name=player,proto3" json:"player,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Control) Reset() {
+	*x = Control{}
+	mi := &file_wire_gobblet_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Control) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Control) ProtoMessage() {}
+
+func (x *Control) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Control.ProtoReflect.Descriptor instead.
+func (*Control) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Control) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Control) GetPlayer() int32 {
+	if x != nil {
+		return x.Player
+	}
+	return 0
+}
+
+// Counts is one row of a statistics grid.
+type Counts struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []int32                `protobuf:"varint,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Counts) Reset() {
+	*x = Counts{}
+	mi := &file_wire_gobblet_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Counts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Counts) ProtoMessage() {}
+
+func (x *Counts) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Counts.ProtoReflect.Descriptor instead.
+func (*Counts) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Counts) GetValues() []int32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type Stats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Landings      []*Counts              `protobuf:"bytes,1,rep,name=landings,proto3" json:"landings,omitempty"`
+	Gobbles       []*Counts              `protobuf:"bytes,2,rep,name=gobbles,proto3" json:"gobbles,omitempty"`
+	SizeUsage     []*Counts              `protobuf:"bytes,3,rep,name=size_usage,json=sizeUsage,proto3" json:"size_usage,omitempty"` // [player-1][size-1]
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_wire_gobblet_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Stats) GetLandings() []*Counts {
+	if x != nil {
+		return x.Landings
+	}
+	return nil
+}
+
+func (x *Stats) GetGobbles() []*Counts {
+	if x != nil {
+		return x.Gobbles
+	}
+	return nil
+}
+
+func (x *Stats) GetSizeUsage() []*Counts {
+	if x != nil {
+		return x.SizeUsage
+	}
+	return nil
+}
+
+// Assists are a preset's optional helpers for learning the game.
+type Assists struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Takebacks      bool                   `protobuf:"varint,1,opt,name=takebacks,proto3" json:"takebacks,omitempty"`
+	ThreatWarnings bool                   `protobuf:"varint,2,opt,name=threat_warnings,json=threatWarnings,proto3" json:"threat_warnings,omitempty"`
+	SimpleMessages bool                   `protobuf:"varint,3,opt,name=simple_messages,json=simpleMessages,proto3" json:"simple_messages,omitempty"`
+	LargeGlyphs    bool                   `protobuf:"varint,4,opt,name=large_glyphs,json=largeGlyphs,proto3" json:"large_glyphs,omitempty"`
+	Hints          bool                   `protobuf:"varint,5,opt,name=hints,proto3" json:"hints,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Assists) Reset() {
+	*x = Assists{}
+	mi := &file_wire_gobblet_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Assists) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Assists) ProtoMessage() {}
+
+func (x *Assists) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Assists.ProtoReflect.Descriptor instead.
+func (*Assists) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Assists) GetTakebacks() bool {
+	if x != nil {
+		return x.Takebacks
+	}
+	return false
+}
+
+func (x *Assists) GetThreatWarnings() bool {
+	if x != nil {
+		return x.ThreatWarnings
+	}
+	return false
+}
+
+func (x *Assists) GetSimpleMessages() bool {
+	if x != nil {
+		return x.SimpleMessages
+	}
+	return false
+}
+
+func (x *Assists) GetLargeGlyphs() bool {
+	if x != nil {
+		return x.LargeGlyphs
+	}
+	return false
+}
+
+func (x *Assists) GetHints() bool {
+	if x != nil {
+		return x.Hints
+	}
+	return false
+}
+
+// PresetRules is the bundle of game options a preset selects.
+type PresetRules struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BoardSize     int32                  `protobuf:"varint,1,opt,name=board_size,json=boardSize,proto3" json:"board_size,omitempty"`
+	TimeControl   int32                  `protobuf:"varint,2,opt,name=time_control,json=timeControl,proto3" json:"time_control,omitempty"` // seconds per player, 0 for no clock
+	Assists       *Assists               `protobuf:"bytes,3,opt,name=assists,proto3" json:"assists,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresetRules) Reset() {
+	*x = PresetRules{}
+	mi := &file_wire_gobblet_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresetRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresetRules) ProtoMessage() {}
+
+func (x *PresetRules) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresetRules.ProtoReflect.Descriptor instead.
+func (*PresetRules) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PresetRules) GetBoardSize() int32 {
+	if x != nil {
+		return x.BoardSize
+	}
+	return 0
+}
+
+func (x *PresetRules) GetTimeControl() int32 {
+	if x != nil {
+		return x.TimeControl
+	}
+	return 0
+}
+
+func (x *PresetRules) GetAssists() *Assists {
+	if x != nil {
+		return x.Assists
+	}
+	return nil
+}
+
+// PlayerIdentity is a player named by an external platform.
+type PlayerIdentity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seat          int32                  `protobuf:"varint,1,opt,name=seat,proto3" json:"seat,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerIdentity) Reset() {
+	*x = PlayerIdentity{}
+	mi := &file_wire_gobblet_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerIdentity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerIdentity) ProtoMessage() {}
+
+func (x *PlayerIdentity) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerIdentity.ProtoReflect.Descriptor instead.
+func (*PlayerIdentity) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PlayerIdentity) GetSeat() int32 {
+	if x != nil {
+		return x.Seat
+	}
+	return 0
+}
+
+func (x *PlayerIdentity) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PlayerIdentity) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// GameMeta is fixed when the game is created.
+type GameMeta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Preset        string                 `protobuf:"bytes,1,opt,name=preset,proto3" json:"preset,omitempty"`
+	Rules         *PresetRules           `protobuf:"bytes,2,opt,name=rules,proto3" json:"rules,omitempty"`
+	Rated         bool                   `protobuf:"varint,3,opt,name=rated,proto3" json:"rated,omitempty"`
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Players       []*PlayerIdentity      `protobuf:"bytes,6,rep,name=players,proto3" json:"players,omitempty"`
+	FirstPlayer   int32                  `protobuf:"varint,7,opt,name=first_player,json=firstPlayer,proto3" json:"first_player,omitempty"`
+	FirstChoice   string                 `protobuf:"bytes,8,opt,name=first_choice,json=firstChoice,proto3" json:"first_choice,omitempty"`
+	Themes        map[int32]string       `protobuf:"bytes,9,rep,name=themes,proto3" json:"themes,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // piece theme by seat
+	Refereed      bool                   `protobuf:"varint,10,opt,name=refereed,proto3" json:"refereed,omitempty"`
+	Tournament    string                 `protobuf:"bytes,11,opt,name=tournament,proto3" json:"tournament,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameMeta) Reset() {
+	*x = GameMeta{}
+	mi := &file_wire_gobblet_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameMeta) ProtoMessage() {}
+
+func (x *GameMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameMeta.ProtoReflect.Descriptor instead.
+func (*GameMeta) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GameMeta) GetPreset() string {
+	if x != nil {
+		return x.Preset
+	}
+	return ""
+}
+
+func (x *GameMeta) GetRules() *PresetRules {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *GameMeta) GetRated() bool {
+	if x != nil {
+		return x.Rated
+	}
+	return false
+}
+
+func (x *GameMeta) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *GameMeta) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *GameMeta) GetPlayers() []*PlayerIdentity {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *GameMeta) GetFirstPlayer() int32 {
+	if x != nil {
+		return x.FirstPlayer
+	}
+	return 0
+}
+
+func (x *GameMeta) GetFirstChoice() string {
+	if x != nil {
+		return x.FirstChoice
+	}
+	return ""
+}
+
+func (x *GameMeta) GetThemes() map[int32]string {
+	if x != nil {
+		return x.Themes
+	}
+	return nil
+}
+
+func (x *GameMeta) GetRefereed() bool {
+	if x != nil {
+		return x.Refereed
+	}
+	return false
+}
+
+func (x *GameMeta) GetTournament() string {
+	if x != nil {
+		return x.Tournament
+	}
+	return ""
+}
+
+// Rules are the rules of play, see engine.Rules.
+type Rules struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Size            int32                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Sizes           int32                  `protobuf:"varint,2,opt,name=sizes,proto3" json:"sizes,omitempty"`
+	Reserves        int32                  `protobuf:"varint,3,opt,name=reserves,proto3" json:"reserves,omitempty"`
+	EntryOnEmpty    bool                   `protobuf:"varint,4,opt,name=entry_on_empty,json=entryOnEmpty,proto3" json:"entry_on_empty,omitempty"`
+	CoverReveal     bool                   `protobuf:"varint,5,opt,name=cover_reveal,json=coverReveal,proto3" json:"cover_reveal,omitempty"`
+	Memory          bool                   `protobuf:"varint,6,opt,name=memory,proto3" json:"memory,omitempty"`
+	NoReserveGobble bool                   `protobuf:"varint,7,opt,name=no_reserve_gobble,json=noReserveGobble,proto3" json:"no_reserve_gobble,omitempty"`
+	GobbleOnThreat  bool                   `protobuf:"varint,8,opt,name=gobble_on_threat,json=gobbleOnThreat,proto3" json:"gobble_on_threat,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Rules) Reset() {
+	*x = Rules{}
+	mi := &file_wire_gobblet_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rules) ProtoMessage() {}
+
+func (x *Rules) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rules.ProtoReflect.Descriptor instead.
+func (*Rules) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Rules) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Rules) GetSizes() int32 {
+	if x != nil {
+		return x.Sizes
+	}
+	return 0
+}
+
+func (x *Rules) GetReserves() int32 {
+	if x != nil {
+		return x.Reserves
+	}
+	return 0
+}
+
+func (x *Rules) GetEntryOnEmpty() bool {
+	if x != nil {
+		return x.EntryOnEmpty
+	}
+	return false
+}
+
+func (x *Rules) GetCoverReveal() bool {
+	if x != nil {
+		return x.CoverReveal
+	}
+	return false
+}
+
+func (x *Rules) GetMemory() bool {
+	if x != nil {
+		return x.Memory
+	}
+	return false
+}
+
+func (x *Rules) GetNoReserveGobble() bool {
+	if x != nil {
+		return x.NoReserveGobble
+	}
+	return false
+}
+
+func (x *Rules) GetGobbleOnThreat() bool {
+	if x != nil {
+		return x.GobbleOnThreat
+	}
+	return false
+}
+
+// AuditEntry is a signed record of a referee action.
+type AuditEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	Action        string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Text          string                 `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	GameId        string                 `protobuf:"bytes,5,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Moves         int32                  `protobuf:"varint,6,opt,name=moves,proto3" json:"moves,omitempty"` // move count the action was taken at
+	PublicKey     []byte                 `protobuf:"bytes,7,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEntry) Reset() {
+	*x = AuditEntry{}
+	mi := &file_wire_gobblet_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEntry) ProtoMessage() {}
+
+func (x *AuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEntry.ProtoReflect.Descriptor instead.
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AuditEntry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *AuditEntry) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetMoves() int32 {
+	if x != nil {
+		return x.Moves
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *AuditEntry) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type GameState struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Board       []*Row                 `protobuf:"bytes,1,rep,name=board,proto3" json:"board,omitempty"`
+	PlayerTurn  int32                  `protobuf:"varint,2,opt,name=player_turn,json=playerTurn,proto3" json:"player_turn,omitempty"`
+	Winner      int32                  `protobuf:"varint,3,opt,name=winner,proto3" json:"winner,omitempty"`
+	Result      *Result                `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+	Moves       int32                  `protobuf:"varint,5,opt,name=moves,proto3" json:"moves,omitempty"`
+	Paused      bool                   `protobuf:"varint,6,opt,name=paused,proto3" json:"paused,omitempty"`
+	Stats       *Stats                 `protobuf:"bytes,7,opt,name=stats,proto3" json:"stats,omitempty"`
+	Clocks      *Clocks                `protobuf:"bytes,8,opt,name=clocks,proto3" json:"clocks,omitempty"`
+	Draw        bool                   `protobuf:"varint,9,opt,name=draw,proto3" json:"draw,omitempty"`
+	Repetitions map[string]int32       `protobuf:"bytes,10,rep,name=repetitions,proto3" json:"repetitions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	History     []*HistoryEntry        `protobuf:"bytes,11,rep,name=history,proto3" json:"history,omitempty"`
+	Variant     string                 `protobuf:"bytes,12,opt,name=variant,proto3" json:"variant,omitempty"`
+	Control     *Control               `protobuf:"bytes,13,opt,name=control,proto3" json:"control,omitempty"`
+	RulesHash   string                 `protobuf:"bytes,14,opt,name=rules_hash,json=rulesHash,proto3" json:"rules_hash,omitempty"`
+	Seq         uint64                 `protobuf:"varint,15,opt,name=seq,proto3" json:"seq,omitempty"`
+	Sender      string                 `protobuf:"bytes,16,opt,name=sender,proto3" json:"sender,omitempty"`
+	Meta        *GameMeta              `protobuf:"bytes,20,opt,name=meta,proto3" json:"meta,omitempty"`
+	Rules       *Rules                 `protobuf:"bytes,21,opt,name=rules,proto3" json:"rules,omitempty"` // house rules replacing the variant's; unset for none
+	// The referee's signed audit entry behind a pause or adjudication.
+	Ruling        *AuditEntry `protobuf:"bytes,22,opt,name=ruling,proto3" json:"ruling,omitempty"`
+	ChatOff       bool        `protobuf:"varint,23,opt,name=chat_off,json=chatOff,proto3" json:"chat_off,omitempty"`
+	Seat          int32       `protobuf:"varint,24,opt,name=seat,proto3" json:"seat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameState) Reset() {
+	*x = GameState{}
+	mi := &file_wire_gobblet_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameState) ProtoMessage() {}
+
+func (x *GameState) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameState.ProtoReflect.Descriptor instead.
+func (*GameState) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GameState) GetBoard() []*Row {
+	if x != nil {
+		return x.Board
+	}
+	return nil
+}
+
+func (x *GameState) GetPlayerTurn() int32 {
+	if x != nil {
+		return x.PlayerTurn
+	}
+	return 0
+}
+
+func (x *GameState) GetWinner() int32 {
+	if x != nil {
+		return x.Winner
+	}
+	return 0
+}
+
+func (x *GameState) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *GameState) GetMoves() int32 {
+	if x != nil {
+		return x.Moves
+	}
+	return 0
+}
+
+func (x *GameState) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *GameState) GetStats() *Stats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *GameState) GetClocks() *Clocks {
+	if x != nil {
+		return x.Clocks
+	}
+	return nil
+}
+
+func (x *GameState) GetDraw() bool {
+	if x != nil {
+		return x.Draw
+	}
+	return false
+}
+
+func (x *GameState) GetRepetitions() map[string]int32 {
+	if x != nil {
+		return x.Repetitions
+	}
+	return nil
+}
+
+func (x *GameState) GetHistory() []*HistoryEntry {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *GameState) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
+
+func (x *GameState) GetControl() *Control {
+	if x != nil {
+		return x.Control
+	}
+	return nil
+}
+
+func (x *GameState) GetRulesHash() string {
+	if x != nil {
+		return x.RulesHash
+	}
+	return ""
+}
+
+func (x *GameState) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *GameState) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *GameState) GetMeta() *GameMeta {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *GameState) GetRules() *Rules {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *GameState) GetRuling() *AuditEntry {
+	if x != nil {
+		return x.Ruling
+	}
+	return nil
+}
+
+func (x *GameState) GetChatOff() bool {
+	if x != nil {
+		return x.ChatOff
+	}
+	return false
+}
+
+func (x *GameState) GetSeat() int32 {
+	if x != nil {
+		return x.Seat
+	}
+	return 0
+}
+
+// MoveMessage is a move delta, or an ack or resync request on the moves
+// channel.
+type MoveMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"` // square the piece leaves, e.g. "a1"; empty for a placement
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Size          int32                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Player        int32                  `protobuf:"varint,5,opt,name=player,proto3" json:"player,omitempty"`
+	Seq           int32                  `protobuf:"varint,6,opt,name=seq,proto3" json:"seq,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=time,proto3" json:"time,omitempty"`
+	Clocks        *Clocks                `protobuf:"bytes,8,opt,name=clocks,proto3" json:"clocks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveMessage) Reset() {
+	*x = MoveMessage{}
+	mi := &file_wire_gobblet_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveMessage) ProtoMessage() {}
+
+func (x *MoveMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_gobblet_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveMessage.ProtoReflect.Descriptor instead.
+func (*MoveMessage) Descriptor() ([]byte, []int) {
+	return file_wire_gobblet_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MoveMessage) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *MoveMessage) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *MoveMessage) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *MoveMessage) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *MoveMessage) GetPlayer() int32 {
+	if x != nil {
+		return x.Player
+	}
+	return 0
+}
+
+func (x *MoveMessage) GetSeq() int32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *MoveMessage) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *MoveMessage) GetClocks() *Clocks {
+	if x != nil {
+		return x.Clocks
+	}
+	return nil
+}
+
+var File_wire_gobblet_proto protoreflect.FileDescriptor
+
+var file_wire_gobblet_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x77, 0x69, 0x72, 0x65, 0x2f, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31,
+	0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x22, 0x31, 0x0a, 0x05, 0x50, 0x69, 0x65, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6f,
+	0x77, 0x6e, 0x65, 0x72, 0x22, 0x32, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x12, 0x29, 0x0a,
+	0x06, 0x70, 0x69, 0x65, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x69, 0x65, 0x63, 0x65,
+	0x52, 0x06, 0x70, 0x69, 0x65, 0x63, 0x65, 0x73, 0x22, 0x2e, 0x0a, 0x03, 0x52, 0x6f, 0x77, 0x12,
+	0x27, 0x0a, 0x05, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x63,
+	0x6b, 0x52, 0x05, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x22, 0x74, 0x0a, 0x04, 0x4d, 0x6f, 0x76, 0x65,
+	0x12, 0x19, 0x0a, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x72, 0x6f, 0x77, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x66, 0x72, 0x6f, 0x6d, 0x52, 0x6f, 0x77, 0x12, 0x19, 0x0a, 0x08, 0x66,
+	0x72, 0x6f, 0x6d, 0x5f, 0x63, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x66,
+	0x72, 0x6f, 0x6d, 0x43, 0x6f, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x6f, 0x77, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x03, 0x72, 0x6f, 0x77, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x6f, 0x6c, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x63, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x90,
+	0x01, 0x0a, 0x0c, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x16, 0x0a, 0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x24, 0x0a, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e,
+	0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x75, 0x6e, 0x64, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x75, 0x6e, 0x64,
+	0x6f, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x22, 0x44, 0x0a, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6f,
+	0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6f, 0x75,
+	0x74, 0x63, 0x6f, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x7c, 0x0a, 0x06, 0x43, 0x6c, 0x6f, 0x63, 0x6b,
+	0x73, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x39, 0x0a, 0x0a, 0x74, 0x75,
+	0x72, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x75, 0x72, 0x6e,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x22, 0x39, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c,
+	0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x22, 0x20, 0x0a, 0x06, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x05, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x73, 0x22, 0x98, 0x01, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x2e, 0x0a, 0x08,
+	0x6c, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x73, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x2c, 0x0a, 0x07,
+	0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x73, 0x52, 0x07, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x73, 0x12, 0x31, 0x0a, 0x0a, 0x73, 0x69,
+	0x7a, 0x65, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x73, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x22, 0xb2, 0x01,
+	0x0a, 0x07, 0x41, 0x73, 0x73, 0x69, 0x73, 0x74, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x61, 0x6b,
+	0x65, 0x62, 0x61, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x61,
+	0x6b, 0x65, 0x62, 0x61, 0x63, 0x6b, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x68, 0x72, 0x65, 0x61,
+	0x74, 0x5f, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73,
+	0x12, 0x27, 0x0a, 0x0f, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x73, 0x69, 0x6d, 0x70, 0x6c,
+	0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x61, 0x72,
+	0x67, 0x65, 0x5f, 0x67, 0x6c, 0x79, 0x70, 0x68, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0b, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x47, 0x6c, 0x79, 0x70, 0x68, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x68, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x68, 0x69, 0x6e,
+	0x74, 0x73, 0x22, 0x7e, 0x0a, 0x0b, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x52, 0x75, 0x6c, 0x65,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x21, 0x0a, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x74,
+	0x72, 0x6f, 0x6c, 0x12, 0x2d, 0x0a, 0x07, 0x61, 0x73, 0x73, 0x69, 0x73, 0x74, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x41, 0x73, 0x73, 0x69, 0x73, 0x74, 0x73, 0x52, 0x07, 0x61, 0x73, 0x73, 0x69, 0x73,
+	0x74, 0x73, 0x22, 0x48, 0x0a, 0x0e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x65, 0x6e,
+	0x74, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x73, 0x65, 0x61, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xbe, 0x03, 0x0a,
+	0x08, 0x47, 0x61, 0x6d, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65,
+	0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x73, 0x65,
+	0x74, 0x12, 0x2d, 0x0a, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72,
+	0x65, 0x73, 0x65, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x72, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x05, 0x72, 0x61, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x61, 0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73,
+	0x12, 0x34, 0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x07, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f,
+	0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x66, 0x69,
+	0x72, 0x73, 0x74, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x69, 0x72,
+	0x73, 0x74, 0x5f, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x66, 0x69, 0x72, 0x73, 0x74, 0x43, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x12, 0x38, 0x0a, 0x06,
+	0x74, 0x68, 0x65, 0x6d, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x67,
+	0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x4d, 0x65,
+	0x74, 0x61, 0x2e, 0x54, 0x68, 0x65, 0x6d, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06,
+	0x74, 0x68, 0x65, 0x6d, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65,
+	0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65,
+	0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6d, 0x65,
+	0x6e, 0x74, 0x1a, 0x39, 0x0a, 0x0b, 0x54, 0x68, 0x65, 0x6d, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x84, 0x02,
+	0x0a, 0x05, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x69, 0x7a, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x69, 0x7a, 0x65,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x73, 0x12, 0x24, 0x0a,
+	0x0e, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x6f, 0x6e, 0x5f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x4f, 0x6e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x5f, 0x72, 0x65, 0x76,
+	0x65, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x63, 0x6f, 0x76, 0x65, 0x72,
+	0x52, 0x65, 0x76, 0x65, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x2a,
+	0x0a, 0x11, 0x6e, 0x6f, 0x5f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x5f, 0x67, 0x6f, 0x62,
+	0x62, 0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x6e, 0x6f, 0x52, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x47, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x67, 0x6f,
+	0x62, 0x62, 0x6c, 0x65, 0x5f, 0x6f, 0x6e, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x4f, 0x6e, 0x54, 0x68,
+	0x72, 0x65, 0x61, 0x74, 0x22, 0xe8, 0x01, 0x0a, 0x0a, 0x41, 0x75, 0x64, 0x69, 0x74, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74,
+	0x69, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x65, 0x78, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x6d, 0x6f, 0x76, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6d, 0x6f, 0x76,
+	0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65,
+	0x79, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22,
+	0xe6, 0x06, 0x0a, 0x09, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x25, 0x0a,
+	0x05, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67,
+	0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x77, 0x52, 0x05, 0x62,
+	0x6f, 0x61, 0x72, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x74,
+	0x75, 0x72, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x70, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x54, 0x75, 0x72, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x12, 0x2a, 0x0a,
+	0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x76,
+	0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6d, 0x6f, 0x76, 0x65, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73,
+	0x12, 0x2a, 0x0a, 0x06, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c,
+	0x6f, 0x63, 0x6b, 0x73, 0x52, 0x06, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x72, 0x61, 0x77, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x64, 0x72, 0x61, 0x77,
+	0x12, 0x48, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x65, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x52, 0x65, 0x70,
+	0x65, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x72,
+	0x65, 0x70, 0x65, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x32, 0x0a, 0x07, 0x68, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x67, 0x6f,
+	0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x18,
+	0x0a, 0x07, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74,
+	0x72, 0x6f, 0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x6f, 0x62, 0x62,
+	0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x07,
+	0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x75, 0x6c, 0x65, 0x73,
+	0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x75, 0x6c,
+	0x65, 0x73, 0x48, 0x61, 0x73, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x03, 0x73, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64,
+	0x65, 0x72, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72,
+	0x12, 0x28, 0x0a, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14,
+	0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61, 0x6d, 0x65,
+	0x4d, 0x65, 0x74, 0x61, 0x52, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x12, 0x27, 0x0a, 0x05, 0x72, 0x75,
+	0x6c, 0x65, 0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x67, 0x6f, 0x62, 0x62,
+	0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x05, 0x72, 0x75,
+	0x6c, 0x65, 0x73, 0x12, 0x2e, 0x0a, 0x06, 0x72, 0x75, 0x6c, 0x69, 0x6e, 0x67, 0x18, 0x16, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x75, 0x64, 0x69, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x72, 0x75, 0x6c,
+	0x69, 0x6e, 0x67, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x74, 0x5f, 0x6f, 0x66, 0x66, 0x18,
+	0x17, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x63, 0x68, 0x61, 0x74, 0x4f, 0x66, 0x66, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x65, 0x61, 0x74, 0x18, 0x18, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x65,
+	0x61, 0x74, 0x1a, 0x3e, 0x0a, 0x10, 0x52, 0x65, 0x70, 0x65, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x4a, 0x04, 0x08, 0x11, 0x10, 0x12, 0x4a, 0x04, 0x08, 0x12, 0x10, 0x13, 0x4a, 0x04,
+	0x08, 0x13, 0x10, 0x14, 0x52, 0x09, 0x6d, 0x65, 0x74, 0x61, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x52,
+	0x0a, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x52, 0x0b, 0x72, 0x75, 0x6c,
+	0x69, 0x6e, 0x67, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x22, 0xdf, 0x01, 0x0a, 0x0b, 0x4d, 0x6f, 0x76,
+	0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d,
+	0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x73, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03,
+	0x73, 0x65, 0x71, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x73, 0x65, 0x71, 0x12, 0x2e,
+	0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x2a,
+	0x0a, 0x06, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x67, 0x6f, 0x62, 0x62, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x6f, 0x63,
+	0x6b, 0x73, 0x52, 0x06, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x42, 0x0e, 0x5a, 0x0c, 0x67, 0x6f,
+	0x62, 0x6c, 0x65, 0x74, 0x73, 0x2f, 0x77, 0x69, 0x72, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_wire_gobblet_proto_rawDescOnce sync.Once
+	file_wire_gobblet_proto_rawDescData = file_wire_gobblet_proto_rawDesc
+)
+
+func file_wire_gobblet_proto_rawDescGZIP() []byte {
+	file_wire_gobblet_proto_rawDescOnce.Do(func() {
+		file_wire_gobblet_proto_rawDescData = protoimpl.X.CompressGZIP(file_wire_gobblet_proto_rawDescData)
+	})
+	return file_wire_gobblet_proto_rawDescData
+}
+
+var file_wire_gobblet_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_wire_gobblet_proto_goTypes = []any{
+	(*Piece)(nil),                 // 0: gobblet.v1.Piece
+	(*Stack)(nil),                 // 1: gobblet.v1.Stack
+	(*Row)(nil),                   // 2: gobblet.v1.Row
+	(*Move)(nil),                  // 3: gobblet.v1.Move
+	(*HistoryEntry)(nil),          // 4: gobblet.v1.HistoryEntry
+	(*Result)(nil),                // 5: gobblet.v1.Result
+	(*Clocks)(nil),                // 6: gobblet.v1.Clocks
+	(*Control)(nil),               // 7: gobblet.v1.Control
+	(*Counts)(nil),                // 8: gobblet.v1.Counts
+	(*Stats)(nil),                 // 9: gobblet.v1.Stats
+	(*Assists)(nil),               // 10: gobblet.v1.Assists
+	(*PresetRules)(nil),           // 11: gobblet.v1.PresetRules
+	(*PlayerIdentity)(nil),        // 12: gobblet.v1.PlayerIdentity
+	(*GameMeta)(nil),              // 13: gobblet.v1.GameMeta
+	(*Rules)(nil),                 // 14: gobblet.v1.Rules
+	(*AuditEntry)(nil),            // 15: gobblet.v1.AuditEntry
+	(*GameState)(nil),             // 16: gobblet.v1.GameState
+	(*MoveMessage)(nil),           // 17: gobblet.v1.MoveMessage
+	nil,                           // 18: gobblet.v1.GameMeta.ThemesEntry
+	nil,                           // 19: gobblet.v1.GameState.RepetitionsEntry
+	(*timestamppb.Timestamp)(nil), // 20: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 21: google.protobuf.Duration
+}
+var file_wire_gobblet_proto_depIdxs = []int32{
+	0,  // 0: gobblet.v1.Stack.pieces:type_name -> gobblet.v1.Piece
+	1,  // 1: gobblet.v1.Row.cells:type_name -> gobblet.v1.Stack
+	3,  // 2: gobblet.v1.HistoryEntry.move:type_name -> gobblet.v1.Move
+	20, // 3: gobblet.v1.HistoryEntry.time:type_name -> google.protobuf.Timestamp
+	21, // 4: gobblet.v1.Clocks.remaining:type_name -> google.protobuf.Duration
+	20, // 5: gobblet.v1.Clocks.turn_start:type_name -> google.protobuf.Timestamp
+	8,  // 6: gobblet.v1.Stats.landings:type_name -> gobblet.v1.Counts
+	8,  // 7: gobblet.v1.Stats.gobbles:type_name -> gobblet.v1.Counts
+	8,  // 8: gobblet.v1.Stats.size_usage:type_name -> gobblet.v1.Counts
+	10, // 9: gobblet.v1.PresetRules.assists:type_name -> gobblet.v1.Assists
+	11, // 10: gobblet.v1.GameMeta.rules:type_name -> gobblet.v1.PresetRules
+	12, // 11: gobblet.v1.GameMeta.players:type_name -> gobblet.v1.PlayerIdentity
+	18, // 12: gobblet.v1.GameMeta.themes:type_name -> gobblet.v1.GameMeta.ThemesEntry
+	20, // 13: gobblet.v1.AuditEntry.time:type_name -> google.protobuf.Timestamp
+	2,  // 14: gobblet.v1.GameState.board:type_name -> gobblet.v1.Row
+	5,  // 15: gobblet.v1.GameState.result:type_name -> gobblet.v1.Result
+	9,  // 16: gobblet.v1.GameState.stats:type_name -> gobblet.v1.Stats
+	6,  // 17: gobblet.v1.GameState.clocks:type_name -> gobblet.v1.Clocks
+	19, // 18: gobblet.v1.GameState.repetitions:type_name -> gobblet.v1.GameState.RepetitionsEntry
+	4,  // 19: gobblet.v1.GameState.history:type_name -> gobblet.v1.HistoryEntry
+	7,  // 20: gobblet.v1.GameState.control:type_name -> gobblet.v1.Control
+	13, // 21: gobblet.v1.GameState.meta:type_name -> gobblet.v1.GameMeta
+	14, // 22: gobblet.v1.GameState.rules:type_name -> gobblet.v1.Rules
+	15, // 23: gobblet.v1.GameState.ruling:type_name -> gobblet.v1.AuditEntry
+	20, // 24: gobblet.v1.MoveMessage.time:type_name -> google.protobuf.Timestamp
+	6,  // 25: gobblet.v1.MoveMessage.clocks:type_name -> gobblet.v1.Clocks
+	26, // [26:26] is the sub-list for method output_type
+	26, // [26:26] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
+}
+
+func init() { file_wire_gobblet_proto_init() }
+func file_wire_gobblet_proto_init() {
+	if File_wire_gobblet_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_wire_gobblet_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   20,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_wire_gobblet_proto_goTypes,
+		DependencyIndexes: file_wire_gobblet_proto_depIdxs,
+		MessageInfos:      file_wire_gobblet_proto_msgTypes,
+	}.Build()
+	File_wire_gobblet_proto = out.File
+	file_wire_gobblet_proto_rawDesc = nil
+	file_wire_gobblet_proto_goTypes = nil
+	file_wire_gobblet_proto_depIdxs = nil
+}
@@ -0,0 +1,39 @@
+package wire
+
+import "encoding/json"
+
+// Services that take part in a game without the terminal's types, such as
+// the referee and gobbot, read JSON states as they arrive and edit only the
+// fields they own, so fields they don't know pass through unchanged.
+
+// Unwrap returns the JSON state inside an ordered-delivery envelope, or
+// the payload as it is.
+func Unwrap(payload []byte) []byte {
+	var env struct {
+		Sender string
+		Body   json.RawMessage
+	}
+	if json.Unmarshal(payload, &env) == nil && env.Sender != "" && len(env.Body) > 0 {
+		return env.Body
+	}
+	return payload
+}
+
+// UpdateState writes fields into a JSON game state, keeping every other
+// field as its sender wrote it. Control is dropped: a resignation or offer
+// is only sent once.
+func UpdateState(payload []byte, fields map[string]any) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return nil, err
+	}
+	for name, v := range fields {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		doc[name] = data
+	}
+	delete(doc, "Control")
+	return json.Marshal(doc)
+}
@@ -0,0 +1,42 @@
+package wire
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUnwrap(t *testing.T) {
+	state := `{"Moves":3}`
+	for payload, want := range map[string]string{
+		`{"Sender":"a","Seq":4,"Body":{"Moves":3}}`: state,
+		state:                              state,
+		`{"Sender":"","Body":{"Moves":3}}`: `{"Sender":"","Body":{"Moves":3}}`,
+		`not json`:                         `not json`,
+	} {
+		if got := string(Unwrap([]byte(payload))); got != want {
+			t.Errorf("Unwrap(%s) = %s, want %s", payload, got, want)
+		}
+	}
+}
+
+func TestUpdateState(t *testing.T) {
+	in := []byte(`{"Moves":3,"Stats":{"Gobbles":[[1]]},"Control":{"Action":"resign"}}`)
+	out, err := UpdateState(in, map[string]any{"Moves": 4, "Winner": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]json.RawMessage
+	json.Unmarshal(out, &got)
+	if string(got["Moves"]) != "4" || string(got["Winner"]) != "2" {
+		t.Errorf("fields not written: %s", out)
+	}
+	if string(got["Stats"]) != `{"Gobbles":[[1]]}` {
+		t.Errorf("an unknown field changed: %s", got["Stats"])
+	}
+	if _, ok := got["Control"]; ok {
+		t.Errorf("Control was kept: %s", out)
+	}
+	if _, err := UpdateState([]byte("[]"), nil); err == nil {
+		t.Error("a payload that isn't an object was accepted")
+	}
+}
@@ -0,0 +1,65 @@
+package wire
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	at := timestamppb.New(time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC))
+	want := &GameState{
+		Board: []*Row{
+			{Cells: []*Stack{{Pieces: []*Piece{{Size: 1, Owner: 2}, {Size: 3, Owner: 1}}}, {}, {}}},
+			{Cells: []*Stack{{}, {}, {}}},
+			{Cells: []*Stack{{}, {}, {}}},
+		},
+		PlayerTurn:  2,
+		Moves:       2,
+		Result:      &Result{Outcome: "½-½", Termination: "adjudication"},
+		Clocks:      &Clocks{Remaining: []*durationpb.Duration{durationpb.New(time.Minute), durationpb.New(90 * time.Second)}, TurnStart: at},
+		Repetitions: map[string]int32{"00ff": 2},
+		History:     []*HistoryEntry{{Player: 1, Move: &Move{FromRow: -1, FromCol: -1, Size: 3}, Time: at}},
+		Seq:         7,
+		Sender:      "GobbletClient-1",
+		Meta: &GameMeta{
+			Preset:   "kids-mode",
+			Rules:    &PresetRules{BoardSize: 3, TimeControl: 180, Assists: &Assists{Takebacks: true, LargeGlyphs: true}},
+			Rated:    true,
+			Tags:     []string{"classroom", "final"},
+			Players:  []*PlayerIdentity{{Seat: 1, Id: "s1", Name: "Ana"}},
+			Themes:   map[int32]string{1: "animals", 2: "space"},
+			Refereed: true,
+		},
+		Rules:   &Rules{Size: 4, Sizes: 3, Reserves: 2, CoverReveal: true, NoReserveGobble: true},
+		Ruling:  &AuditEntry{Time: at, Role: "referee", Action: "adjudication", GameId: "g1", Moves: 2, PublicKey: []byte{1, 2}, Signature: []byte{3}},
+		ChatOff: true,
+		Seat:    1,
+	}
+	data, err := proto.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := new(GameState)
+	if err := proto.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestProtoSkipsRetiredJSONFields(t *testing.T) {
+	// A state from a client that sent its metadata as JSON in field 17
+	data := []byte{0x10, 0x02, 0x8a, 0x01, 0x02, '{', '}'}
+	got := new(GameState)
+	if err := proto.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.PlayerTurn != 2 || got.Meta != nil {
+		t.Errorf("got %v, want Player 2 to move and no metadata", got)
+	}
+}